@@ -15,7 +15,7 @@ type MetricMaker interface {
 
 type accumulator struct {
 	maker     MetricMaker
-	metrics   chan<- telegraf.Metric
+	metrics   []chan<- telegraf.Metric
 	precision time.Duration
 }
 
@@ -23,12 +23,20 @@ func NewAccumulator(
 	maker MetricMaker,
 	metrics chan<- telegraf.Metric,
 ) telegraf.Accumulator {
-	acc := accumulator{
+	return newFanOutAccumulator(maker, metrics)
+}
+
+// newFanOutAccumulator returns an accumulator writing a copy of each metric
+// to every given channel.
+func newFanOutAccumulator(
+	maker MetricMaker,
+	metrics ...chan<- telegraf.Metric,
+) *accumulator {
+	return &accumulator{
 		maker:     maker,
 		metrics:   metrics,
 		precision: time.Nanosecond,
 	}
-	return &acc
 }
 
 func (ac *accumulator) AddFields(
@@ -79,7 +87,7 @@ func (ac *accumulator) AddHistogram(
 func (ac *accumulator) AddMetric(m telegraf.Metric) {
 	m.SetTime(m.Time().Round(ac.precision))
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
 	}
 }
 
@@ -92,7 +100,18 @@ func (ac *accumulator) addMeasurement(
 ) {
 	m := metric.New(measurement, tags, fields, ac.getTime(t), tp)
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
+	}
+}
+
+// send writes the metric to all channels, copying it for all but the last.
+func (ac *accumulator) send(m telegraf.Metric) {
+	for i, metrics := range ac.metrics {
+		if i == len(ac.metrics)-1 {
+			metrics <- m
+		} else {
+			metrics <- m.Copy()
+		}
 	}
 }
 
@@ -22,6 +22,10 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Running units used to apply configuration changes while running
+	reloadLock sync.Mutex
+	running    *runningUnits
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -32,7 +36,8 @@ func NewAgent(cfg *config.Config) *Agent {
 	return a
 }
 
-// inputUnit is a group of input plugins and the shared channel they write to.
+// inputUnit is a group of input plugins and the shared channels of the
+// pipelines they write to.
 //
 // ┌───────┐
 // │ Input │───┐
@@ -44,8 +49,13 @@ func NewAgent(cfg *config.Config) *Agent {
 // │ Input │───┘
 // └───────┘
 type inputUnit struct {
-	dst    chan<- telegraf.Metric
+	dsts   map[string]chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// Gather loops of the running inputs and the inputs paused on request
+	sync.Mutex
+	loops  map[*models.RunningInput]*pluginLoop
+	paused map[*models.RunningInput]bool
 }
 
 //  ______     ┌───────────┐     ______
@@ -79,6 +89,12 @@ type aggregatorUnit struct {
 	aggC        chan<- telegraf.Metric
 	outputC     chan<- telegraf.Metric
 	aggregators []*models.RunningAggregator
+
+	// Aggregators taken over from a previous unit keep their aggregation
+	// window, aggregators retained by a subsequent unit are not pushed when
+	// this unit stops.
+	resumed  map[*models.RunningAggregator]bool
+	retained map[*models.RunningAggregator]bool
 }
 
 // outputUnit is a group of Outputs and their source channel.  Metrics on the
@@ -97,6 +113,40 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// Flush loops of the running outputs
+	sync.RWMutex
+	loops map[*models.RunningOutput]*pluginLoop
+}
+
+// pluginLoop is the gather or flush loop of a single plugin.
+type pluginLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// trigger requests an immediate run of the loop if supported
+	trigger chan struct{}
+}
+
+// newPluginLoop runs the given function in the background until the
+// loop is stopped.
+func newPluginLoop(run func(ctx context.Context)) *pluginLoop {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &pluginLoop{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(l.done)
+		run(ctx)
+	}()
+	return l
+}
+
+// stop terminates the loop and waits for it to finish.
+func (l *pluginLoop) stop() {
+	l.cancel()
+	<-l.done
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -127,73 +177,42 @@ func (a *Agent) Run(ctx context.Context) error {
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	pu, err := a.startPipelines(ctx, startTime)
 	if err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		aggC := next
-		if len(a.Config.AggProcessors) != 0 && !a.Config.Agent.SkipProcessorsAfterAggregators {
-			aggC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
-			return err
-		}
-	}
-
-	iu, err := a.startInputs(next, a.Config.Inputs)
+	iu, err := a.startInputs(pipelineSources(pu), a.Config.Inputs)
 	if err != nil {
+		a.stopPipelines(pu)
 		return err
 	}
 
+	a.reloadLock.Lock()
+	a.running = &runningUnits{inputs: iu, pipelines: pu}
+	a.reloadLock.Unlock()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		a.runOutputs(ou)
+		a.runPipelines(pu)
 	}()
 
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.runInputs(ctx, startTime, iu)
+	}()
 
-	if pu != nil {
+	if a.Config.Persister != nil && a.Config.Agent.StatefileInterval > 0 {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.checkpointStates(ctx, time.Duration(a.Config.Agent.StatefileInterval))
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runInputs(ctx, startTime, iu)
-	}()
-
 	wg.Wait()
 
 	if a.Config.Persister != nil {
@@ -209,7 +228,24 @@ func (a *Agent) Run(ctx context.Context) error {
 
 // initPlugins runs the Init function on plugins.
 func (a *Agent) initPlugins() error {
-	for _, input := range a.Config.Inputs {
+	return a.initializePlugins(
+		a.Config.Inputs,
+		a.Config.Processors,
+		a.Config.Aggregators,
+		a.Config.AggProcessors,
+		a.Config.Outputs,
+	)
+}
+
+// initializePlugins runs the Init function on the given plugins.
+func (a *Agent) initializePlugins(
+	inputs []*models.RunningInput,
+	runningProcessors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+	outputs []*models.RunningOutput,
+) error {
+	for _, input := range inputs {
 		// Share the snmp translator setting with plugins that need it.
 		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
 			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
@@ -219,25 +255,25 @@ func (a *Agent) initPlugins() error {
 			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
 		}
 	}
-	for _, processor := range a.Config.Processors {
+	for _, processor := range runningProcessors {
 		err := processor.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
 		}
 	}
-	for _, aggregator := range a.Config.Aggregators {
+	for _, aggregator := range aggregators {
 		err := aggregator.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err)
 		}
 	}
-	for _, processor := range a.Config.AggProcessors {
+	for _, processor := range aggProcessors {
 		err := processor.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
 		}
 	}
-	for _, output := range a.Config.Outputs {
+	for _, output := range outputs {
 		err := output.Init()
 		if err != nil {
 			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
@@ -252,7 +288,24 @@ func (a *Agent) initPersister() error {
 		return err
 	}
 
-	for _, input := range a.Config.Inputs {
+	return a.registerStates(
+		a.Config.Inputs,
+		a.Config.Processors,
+		a.Config.Aggregators,
+		a.Config.AggProcessors,
+		a.Config.Outputs,
+	)
+}
+
+// registerStates registers the stateful plugins with the persister.
+func (a *Agent) registerStates(
+	inputs []*models.RunningInput,
+	runningProcessors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+	outputs []*models.RunningOutput,
+) error {
+	for _, input := range inputs {
 		plugin, ok := input.Input.(telegraf.StatefulPlugin)
 		if !ok {
 			continue
@@ -265,7 +318,7 @@ func (a *Agent) initPersister() error {
 		}
 	}
 
-	for _, processor := range a.Config.Processors {
+	for _, processor := range runningProcessors {
 		var plugin telegraf.StatefulPlugin
 		if p, ok := processor.Processor.(processors.HasUnwrap); ok {
 			plugin, ok = p.Unwrap().(telegraf.StatefulPlugin)
@@ -286,7 +339,7 @@ func (a *Agent) initPersister() error {
 		}
 	}
 
-	for _, aggregator := range a.Config.Aggregators {
+	for _, aggregator := range aggregators {
 		plugin, ok := aggregator.Aggregator.(telegraf.StatefulPlugin)
 		if !ok {
 			continue
@@ -299,7 +352,7 @@ func (a *Agent) initPersister() error {
 		}
 	}
 
-	for _, processor := range a.Config.AggProcessors {
+	for _, processor := range aggProcessors {
 		plugin, ok := processor.Processor.(telegraf.StatefulPlugin)
 		if !ok {
 			continue
@@ -312,7 +365,7 @@ func (a *Agent) initPersister() error {
 		}
 	}
 
-	for _, output := range a.Config.Outputs {
+	for _, output := range outputs {
 		plugin, ok := output.Output.(telegraf.StatefulPlugin)
 		if !ok {
 			continue
@@ -328,38 +381,55 @@ func (a *Agent) initPersister() error {
 	return nil
 }
 
+// checkpointStates periodically stores the states of the plugins until the
+// context is done.
+func (a *Agent) checkpointStates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Hold the reload lock to not interfere with plugins being
+		// registered or stopped
+		a.reloadLock.Lock()
+		if a.running != nil {
+			log.Printf("D! [agent] Checkpointing plugin states")
+			if err := a.Config.Persister.Store(); err != nil {
+				log.Printf("E! [agent] Checkpointing plugin states failed: %v", err)
+			}
+		}
+		a.reloadLock.Unlock()
+	}
+}
+
 func (a *Agent) startInputs(
-	dst chan<- telegraf.Metric,
+	dsts map[string]chan<- telegraf.Metric,
 	inputs []*models.RunningInput,
 ) (*inputUnit, error) {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dsts:  dsts,
+		loops: make(map[*models.RunningInput]*pluginLoop),
 	}
 
 	for _, input := range inputs {
-		if si, ok := input.Input.(telegraf.ServiceInput); ok {
-			// Service input plugins are not normally subject to timestamp
-			// rounding except for when precision is set on the input plugin.
-			//
-			// This only applies to the accumulator passed to Start(), the
-			// Gather() accumulator does apply rounding according to the
-			// precision and interval agent/plugin settings.
-			var interval time.Duration
-			var precision time.Duration
-			if input.Config.Precision != 0 {
-				precision = input.Config.Precision
+		if err := a.startInput(unit.destinations(input), input); err != nil {
+			// If the model tells us to remove the plugin we do so without
+			// error
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				log.Printf("I! [agent] Failed to start %s, error was %q; shutting down plugin...", input.LogName(), err)
+				continue
 			}
 
-			acc := NewAccumulator(input, dst)
-			acc.SetPrecision(getPrecision(precision, interval))
-
-			err := si.Start(acc)
-			if err != nil {
-				stopServiceInputs(unit.inputs)
-				return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
-			}
+			stopServiceInputs(unit.inputs)
+			return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
 		}
 		unit.inputs = append(unit.inputs, input)
 	}
@@ -367,6 +437,43 @@ func (a *Agent) startInputs(
 	return unit, nil
 }
 
+// destinations returns the channels of the pipelines fed by the given input.
+func (unit *inputUnit) destinations(input *models.RunningInput) []chan<- telegraf.Metric {
+	pipelines := input.Pipelines()
+	dsts := make([]chan<- telegraf.Metric, 0, len(pipelines))
+	for _, name := range pipelines {
+		dsts = append(dsts, unit.dsts[name])
+	}
+	return dsts
+}
+
+// closeDestinations closes the channels of all pipelines.
+func (unit *inputUnit) closeDestinations() {
+	for _, dst := range unit.dsts {
+		close(dst)
+	}
+}
+
+// startInput calls Start on the given input.
+func (a *Agent) startInput(dsts []chan<- telegraf.Metric, input *models.RunningInput) error {
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := newFanOutAccumulator(input, dsts...)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	return input.Start(acc)
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -376,86 +483,105 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) {
-	var wg sync.WaitGroup
-	tickers := make([]Ticker, 0, len(unit.inputs))
+	unit.Lock()
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := time.Duration(a.Config.Agent.Interval)
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
-
-		// Overwrite agent precision if this plugin has its own.
-		precision := time.Duration(a.Config.Agent.Precision)
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
-
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := time.Duration(a.Config.Agent.CollectionJitter)
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
-
-		// Overwrite agent collection_offset if this plugin has its own.
-		offset := time.Duration(a.Config.Agent.CollectionOffset)
-		if input.Config.CollectionOffset != 0 {
-			offset = input.Config.CollectionOffset
-		}
+		a.runInput(startTime, unit, input)
+	}
+	unit.Unlock()
 
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter, offset)
-		}
-		tickers = append(tickers, ticker)
+	<-ctx.Done()
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+	// Wait for a running reload to finish and prevent further ones
+	a.reloadLock.Lock()
+	a.running = nil
+	a.reloadLock.Unlock()
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+	unit.Lock()
+	for _, loop := range unit.loops {
+		loop.cancel()
 	}
-	defer stopTickers(tickers)
-	wg.Wait()
+	for _, loop := range unit.loops {
+		<-loop.done
+	}
+	unit.Unlock()
 
 	log.Printf("D! [agent] Stopping service inputs")
 	stopServiceInputs(unit.inputs)
 
-	close(unit.dst)
+	unit.closeDestinations()
 	log.Printf("D! [agent] Input channel closed")
 }
 
+// runInput starts the gather loop of the given input unless it is already
+// running or paused. The unit must be locked by the caller.
+func (a *Agent) runInput(startTime time.Time, unit *inputUnit, input *models.RunningInput) {
+	if _, found := unit.loops[input]; found || unit.paused[input] {
+		return
+	}
+
+	// Overwrite agent interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.Interval)
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
+
+	// Overwrite agent precision if this plugin has its own.
+	precision := time.Duration(a.Config.Agent.Precision)
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.CollectionJitter)
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
+	}
+
+	// Overwrite agent collection_offset if this plugin has its own.
+	offset := time.Duration(a.Config.Agent.CollectionOffset)
+	if input.Config.CollectionOffset != 0 {
+		offset = input.Config.CollectionOffset
+	}
+
+	var ticker Ticker
+	if a.Config.Agent.RoundInterval {
+		ticker = NewAlignedTicker(startTime, interval, jitter, offset)
+	} else {
+		ticker = NewUnalignedTicker(interval, jitter, offset)
+	}
+
+	acc := newFanOutAccumulator(input, unit.destinations(input)...)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	unit.loops[input] = newPluginLoop(func(ctx context.Context) {
+		defer ticker.Stop()
+		a.gatherLoop(ctx, acc, input, ticker, interval)
+	})
+}
+
 // testStartInputs is a variation of startInputs for use in --test and --once
 // mode.  It differs by logging Start errors and returning only plugins
 // successfully started.
 func (a *Agent) testStartInputs(
-	dst chan<- telegraf.Metric,
+	dsts map[string]chan<- telegraf.Metric,
 	inputs []*models.RunningInput,
 ) *inputUnit {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dsts: dsts,
 	}
 
 	for _, input := range inputs {
-		if si, ok := input.Input.(telegraf.ServiceInput); ok {
-			// Service input plugins are not subject to timestamp rounding.
-			// This only applies to the accumulator passed to Start(), the
-			// Gather() accumulator does apply rounding according to the
-			// precision agent setting.
-			acc := NewAccumulator(input, dst)
-			acc.SetPrecision(time.Nanosecond)
-
-			err := si.Start(acc)
-			if err != nil {
-				log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
-			}
+		// Service input plugins are not subject to timestamp rounding.
+		// This only applies to the accumulator passed to Start(), the
+		// Gather() accumulator does apply rounding according to the
+		// precision agent setting.
+		acc := newFanOutAccumulator(input, unit.destinations(input)...)
+		acc.SetPrecision(time.Nanosecond)
+
+		if err := input.Start(acc); err != nil {
+			log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
 		}
 
 		unit.inputs = append(unit.inputs, input)
@@ -510,7 +636,7 @@ func (a *Agent) testRunInputs(
 				time.Sleep(500 * time.Millisecond)
 			}
 
-			acc := NewAccumulator(input, unit.dst)
+			acc := newFanOutAccumulator(input, unit.destinations(input)...)
 			acc.SetPrecision(getPrecision(precision, interval))
 
 			if err := input.Input.Gather(acc); err != nil {
@@ -527,16 +653,14 @@ func (a *Agent) testRunInputs(
 	log.Printf("D! [agent] Stopping service inputs")
 	stopServiceInputs(unit.inputs)
 
-	close(unit.dst)
+	unit.closeDestinations()
 	log.Printf("D! [agent] Input channel closed")
 }
 
 // stopServiceInputs stops all service inputs.
 func stopServiceInputs(inputs []*models.RunningInput) {
 	for _, input := range inputs {
-		if si, ok := input.Input.(telegraf.ServiceInput); ok {
-			si.Stop()
-		}
+		input.Stop()
 	}
 }
 
@@ -696,7 +820,10 @@ func (a *Agent) runAggregators(
 
 	// Before calling Add, initialize the aggregation window.  This ensures
 	// that any metric created after start time will be aggregated.
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
+		if unit.resumed[agg] {
+			continue
+		}
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
 	}
@@ -707,7 +834,7 @@ func (a *Agent) runAggregators(
 		defer wg.Done()
 		for metric := range unit.src {
 			var dropOriginal bool
-			for _, agg := range a.Config.Aggregators {
+			for _, agg := range unit.aggregators {
 				if ok := agg.Add(metric); ok {
 					dropOriginal = true
 				}
@@ -722,7 +849,7 @@ func (a *Agent) runAggregators(
 		cancel()
 	}()
 
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		wg.Add(1)
 		go func(agg *models.RunningAggregator) {
 			defer wg.Done()
@@ -733,6 +860,12 @@ func (a *Agent) runAggregators(
 			acc := NewAccumulator(agg, unit.aggC)
 			acc.SetPrecision(getPrecision(precision, interval))
 			a.push(ctx, agg, acc)
+
+			// Push the remaining metrics unless the aggregator continues
+			// in another unit.
+			if !unit.retained[agg] {
+				agg.Push(acc)
+			}
 		}(agg)
 	}
 
@@ -761,7 +894,8 @@ func updateWindow(start time.Time, roundInterval bool, period time.Duration) (ti
 	return since, until
 }
 
-// push runs the push for a single aggregator every period.
+// push runs the push for a single aggregator every period until the context
+// is done.
 func (a *Agent) push(
 	ctx context.Context,
 	aggregator *models.RunningAggregator,
@@ -778,7 +912,6 @@ func (a *Agent) push(
 		case <-time.After(until):
 			aggregator.Push(acc)
 		case <-ctx.Done():
-			aggregator.Push(acc)
 			return
 		}
 	}
@@ -791,10 +924,22 @@ func (a *Agent) startOutputs(
 	outputs []*models.RunningOutput,
 ) (chan<- telegraf.Metric, *outputUnit, error) {
 	src := make(chan telegraf.Metric, 100)
-	unit := &outputUnit{src: src}
+	unit := &outputUnit{
+		src:   src,
+		loops: make(map[*models.RunningOutput]*pluginLoop),
+	}
 	for _, output := range outputs {
+		output.SetDeadLetterRouter(unit.routeDeadLetter)
 		err := a.connectOutput(ctx, output)
 		if err != nil {
+			// If the model tells us to remove the plugin we do so without
+			// error
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				log.Printf("I! [agent] Failed to connect to [%s], error was %q; shutting down plugin...", output.LogName(), err)
+				continue
+			}
+
 			for _, output := range unit.outputs {
 				output.Close()
 			}
@@ -810,8 +955,13 @@ func (a *Agent) startOutputs(
 // connectOutputs connects to all outputs.
 func (a *Agent) connectOutput(ctx context.Context, output *models.RunningOutput) error {
 	log.Printf("D! [agent] Attempting connection to [%s]", output.LogName())
-	err := output.Output.Connect()
+	err := output.Connect()
 	if err != nil {
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			return err
+		}
+
 		log.Printf("E! [agent] Failed to connect to [%s], retrying in 15s, "+
 			"error was %q", output.LogName(), err)
 
@@ -820,7 +970,7 @@ func (a *Agent) connectOutput(ctx context.Context, output *models.RunningOutput)
 			return err
 		}
 
-		err = output.Output.Connect()
+		err = output.Connect()
 		if err != nil {
 			return fmt.Errorf("error connecting to output %q: %w", output.LogName(), err)
 		}
@@ -829,68 +979,100 @@ func (a *Agent) connectOutput(ctx context.Context, output *models.RunningOutput)
 	return nil
 }
 
+// routeDeadLetter adds the metric to the output with the given alias or ID.
+func (unit *outputUnit) routeDeadLetter(target string, metric telegraf.Metric) error {
+	unit.RLock()
+	defer unit.RUnlock()
+
+	for _, output := range unit.outputs {
+		if output.Config.Alias == target || output.ID() == target {
+			output.AddMetric(metric)
+			return nil
+		}
+	}
+	metric.Drop()
+	return fmt.Errorf("dead-letter output %q not running", target)
+}
+
 // runOutputs begins processing metrics and returns until the source channel is
 // closed and all metrics have been written.  On shutdown metrics will be
 // written one last time and dropped if unsuccessful.
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
 	// Start flush loop
-	interval := time.Duration(a.Config.Agent.FlushInterval)
-	jitter := time.Duration(a.Config.Agent.FlushJitter)
-
-	ctx, cancel := context.WithCancel(context.Background())
-
+	unit.Lock()
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
-
-		wg.Add(1)
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
-
-			a.flushLoop(ctx, output, ticker)
-		}(output)
+		a.runOutput(unit, output)
 	}
+	unit.Unlock()
 
 	for metric := range unit.src {
+		unit.RLock()
+		if len(unit.outputs) == 0 {
+			metric.Drop()
+		}
 		for i, output := range unit.outputs {
-			if i == len(a.Config.Outputs)-1 {
+			if i == len(unit.outputs)-1 {
 				output.AddMetric(metric)
 			} else {
 				output.AddMetric(metric.Copy())
 			}
 		}
+		unit.RUnlock()
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
-	cancel()
-	wg.Wait()
+	unit.Lock()
+	for _, loop := range unit.loops {
+		loop.cancel()
+	}
+	for _, loop := range unit.loops {
+		<-loop.done
+	}
+	unit.Unlock()
 
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
+// runOutput starts the flush loop of the given output unless it is already
+// running. The unit must be locked by the caller.
+func (a *Agent) runOutput(unit *outputUnit, output *models.RunningOutput) {
+	if _, found := unit.loops[output]; found {
+		return
+	}
+
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.FlushInterval)
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
+
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.FlushJitter)
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	flush := make(chan struct{}, 1)
+	loop := newPluginLoop(func(ctx context.Context) {
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, output, ticker, flush)
+	})
+	loop.trigger = flush
+	unit.loops[output] = loop
+}
+
 // flushLoop runs an output's flush function periodically until the context is
 // done.
 func (a *Agent) flushLoop(
 	ctx context.Context,
 	output *models.RunningOutput,
 	ticker Ticker,
+	flush <-chan struct{},
 ) {
 	logError := func(err error) {
 		if err != nil {
@@ -907,19 +1089,21 @@ func (a *Agent) flushLoop(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteFinal))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteFinal))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
 			logError(a.flushOnce(output, ticker, output.Write))
+		case <-flush:
+			logError(a.flushOnce(output, ticker, output.Write))
 		case <-output.BatchReady:
 			logError(a.flushBatch(output, output.WriteBatch))
 		}
@@ -1006,53 +1190,39 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 
 	startTime := time.Now()
 
-	next := outputC
+	// Merge the metrics of all pipelines into the output channel
+	var merge sync.WaitGroup
+	chains := make([]*chainUnit, 0, len(a.Config.Pipelines)+1)
+	sources := make(map[string]chan<- telegraf.Metric, len(a.Config.Pipelines)+1)
+	for _, name := range a.pipelineNames() {
+		p := selectPipeline(name, a.Config.Processors, a.Config.Aggregators, a.Config.AggProcessors, nil)
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !a.Config.Agent.SkipProcessorsAfterAggregators {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
+		dst := make(chan telegraf.Metric, 100)
+		cu := newChainUnit(dst)
+		if err := a.startChain(cu, startTime, p.processors, p.aggregators, p.aggProcessors, nil); err != nil {
 			return err
 		}
-	}
-
-	iu := a.testStartInputs(next, a.Config.Inputs)
+		chains = append(chains, cu)
+		sources[name] = cu.src
 
-	var wg sync.WaitGroup
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
-		wg.Add(1)
+		merge.Add(1)
 		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
+			defer merge.Done()
+			for m := range dst {
+				outputC <- m
+			}
 		}()
 	}
 
-	if pu != nil {
+	iu := a.testStartInputs(sources, a.Config.Inputs)
+
+	var wg sync.WaitGroup
+	for _, cu := range chains {
 		wg.Add(1)
-		go func() {
+		go func(cu *chainUnit) {
 			defer wg.Done()
-			a.runProcessors(pu)
-		}()
+			a.runChain(cu)
+		}(cu)
 	}
 
 	wg.Add(1)
@@ -1062,6 +1232,8 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 	}()
 
 	wg.Wait()
+	merge.Wait()
+	close(outputC)
 
 	log.Printf("D! [agent] Stopped Successfully")
 
@@ -1102,64 +1274,20 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
+	pu, err := a.startPipelines(ctx, startTime)
 	if err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 && !a.Config.Agent.SkipProcessorsAfterAggregators {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
-			return err
-		}
-	}
-
-	iu := a.testStartInputs(next, a.Config.Inputs)
+	iu := a.testStartInputs(pipelineSources(pu), a.Config.Inputs)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		a.runOutputs(ou)
+		a.runPipelines(pu)
 	}()
 
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
-
-	if pu != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(pu)
-		}()
-	}
-
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -1204,9 +1332,3 @@ func panicRecover(input *models.RunningInput) {
 			"https://github.com/influxdata/telegraf/issues/new/choose")
 	}
 }
-
-func stopTickers(tickers []Ticker) {
-	for _, ticker := range tickers {
-		ticker.Stop()
-	}
-}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,6 +20,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/processors"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -258,3 +260,433 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+func TestAgent_Reload(t *testing.T) {
+	output := &mockOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(50 * time.Millisecond)
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"))
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(output))
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return output.count("first", "") > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	first := a.Config.Inputs[0]
+
+	// Add an input and a processor while keeping the existing input
+	newCfg := config.NewConfig()
+	newCfg.Agent = cfg.Agent
+	newCfg.Inputs = append(newCfg.Inputs, newMockInput("first"), newMockInput("second"))
+	newCfg.Processors = append(newCfg.Processors, newMockProcessor("reloaded"))
+	newCfg.Outputs = append(newCfg.Outputs, newMockOutput(&mockOutput{}))
+
+	diff := config.Compare(a.Config, newCfg)
+	require.Empty(t, diff.RestartReason)
+	require.Len(t, diff.Inputs.Added, 1)
+	require.Empty(t, diff.Outputs.Added)
+	require.NoError(t, a.Reload(ctx, diff))
+
+	require.Len(t, a.Config.Inputs, 2)
+	require.Same(t, first, a.Config.Inputs[0])
+	require.Eventually(t, func() bool {
+		return output.count("first", "reloaded") > 0 && output.count("second", "reloaded") > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Remove the first input
+	newCfg = config.NewConfig()
+	newCfg.Agent = cfg.Agent
+	newCfg.Inputs = append(newCfg.Inputs, newMockInput("second"))
+	newCfg.Processors = append(newCfg.Processors, newMockProcessor("reloaded"))
+	newCfg.Outputs = append(newCfg.Outputs, newMockOutput(&mockOutput{}))
+
+	diff = config.Compare(a.Config, newCfg)
+	require.Len(t, diff.Inputs.Removed, 1)
+	require.False(t, diff.Processors.Changed())
+	require.NoError(t, a.Reload(ctx, diff))
+	require.Len(t, a.Config.Inputs, 1)
+
+	// Wait for metrics of the removed input still in flight to be written
+	current := output.count("second", "")
+	require.Eventually(t, func() bool {
+		return output.count("second", "") > current+2
+	}, 5*time.Second, 50*time.Millisecond)
+	before := output.count("first", "")
+	current = output.count("second", "")
+	require.Eventually(t, func() bool {
+		return output.count("second", "") > current+2
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, before, output.count("first", ""))
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestAgent_ReloadRollback(t *testing.T) {
+	output := &mockOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(50 * time.Millisecond)
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"))
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(output))
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return output.count("first", "") > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	inputs := a.Config.Inputs
+	outputs := a.Config.Outputs
+
+	// Replace the input and add a processor and an output with the new input
+	// failing to start
+	added := &mockOutput{}
+	addedOutput := newMockOutput(added)
+	addedOutput.Config.ID = "added"
+	newCfg := config.NewConfig()
+	newCfg.Agent = cfg.Agent
+	newCfg.Inputs = append(newCfg.Inputs, models.NewRunningInput(&mockServiceInput{}, &models.InputConfig{
+		Name: "mock_service",
+		ID:   "failing",
+	}))
+	newCfg.Processors = append(newCfg.Processors, newMockProcessor("reloaded"))
+	newCfg.Outputs = append(newCfg.Outputs, newMockOutput(&mockOutput{}), addedOutput)
+
+	diff := config.Compare(a.Config, newCfg)
+	require.Len(t, diff.Inputs.Removed, 1)
+	require.Len(t, diff.Outputs.Added, 1)
+	require.ErrorContains(t, a.Reload(ctx, diff), "starting input")
+
+	// The running plugins must match the unchanged configuration
+	require.Equal(t, inputs, a.Config.Inputs)
+	require.Equal(t, outputs, a.Config.Outputs)
+	require.Empty(t, a.Config.Processors)
+	require.True(t, added.isClosed())
+
+	current := output.count("first", "")
+	require.Eventually(t, func() bool {
+		return output.count("first", "") > current+2
+	}, 5*time.Second, 50*time.Millisecond)
+	reloaded := output.count("first", "reloaded")
+	current = output.count("first", "")
+	require.Eventually(t, func() bool {
+		return output.count("first", "") > current+2
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, reloaded, output.count("first", "reloaded"))
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestAgent_Control(t *testing.T) {
+	output := &mockOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(time.Hour)
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"))
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(output))
+
+	a := NewAgent(cfg)
+	_, err := a.Plugins()
+	require.ErrorContains(t, err, "agent is not running")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Run(ctx)
+	}()
+
+	// Metrics must only be written when requesting a flush
+	require.Eventually(t, func() bool {
+		return a.FlushOutput("output") == nil && output.count("first", "") > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	plugins, err := a.Plugins()
+	require.NoError(t, err)
+	require.Len(t, plugins, 2)
+	require.Equal(t, "first", plugins[0].ID)
+	require.Equal(t, "input", plugins[0].Type)
+	require.False(t, plugins[0].Paused)
+	require.Positive(t, plugins[0].Stats["metrics_gathered"])
+	require.Equal(t, "output", plugins[1].ID)
+	require.Equal(t, 10000, plugins[1].Buffer.Limit)
+
+	// Paused inputs must not be gathered anymore
+	require.NoError(t, a.PauseInput("first"))
+	require.Eventually(t, func() bool {
+		require.NoError(t, a.FlushOutput("output"))
+		plugins, err := a.Plugins()
+		require.NoError(t, err)
+		return plugins[1].Buffer.Size == 0
+	}, 5*time.Second, 50*time.Millisecond)
+	plugins, err = a.Plugins()
+	require.NoError(t, err)
+	require.True(t, plugins[0].Paused)
+	time.Sleep(200 * time.Millisecond)
+	plugins, err = a.Plugins()
+	require.NoError(t, err)
+	require.Zero(t, plugins[1].Buffer.Size)
+
+	// Resumed inputs are gathered again
+	require.NoError(t, a.ResumeInput("first"))
+	require.Eventually(t, func() bool {
+		plugins, err := a.Plugins()
+		require.NoError(t, err)
+		return !plugins[0].Paused && plugins[1].Buffer.Size > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.ErrorIs(t, a.PauseInput("unknown"), ErrPluginNotFound)
+	require.ErrorIs(t, a.ResumeInput("unknown"), ErrPluginNotFound)
+	require.ErrorIs(t, a.FlushOutput("unknown"), ErrPluginNotFound)
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestAgent_ControlAmbiguous(t *testing.T) {
+	// Plugins with identical configurations share the same ID
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(time.Hour)
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"), newMockInput("first"))
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(&mockOutput{}), newMockOutput(&mockOutput{}))
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := a.Plugins()
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.ErrorIs(t, a.PauseInput("first"), ErrPluginAmbiguous)
+	require.ErrorIs(t, a.ResumeInput("first"), ErrPluginAmbiguous)
+	require.ErrorIs(t, a.FlushOutput("output"), ErrPluginAmbiguous)
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestAgent_Pipelines(t *testing.T) {
+	output := &mockOutput{}
+	audit := &mockOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(50 * time.Millisecond)
+	cfg.Pipelines = []string{"audit"}
+
+	second := newMockInput("second")
+	second.Config.Pipelines = []string{"audit", models.DefaultPipeline}
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"), second)
+
+	processor := newMockProcessor("audited")
+	processor.Config.Pipeline = "audit"
+	cfg.Processors = append(cfg.Processors, processor)
+
+	auditOutput := newMockOutput(audit)
+	auditOutput.Config.Pipeline = "audit"
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(output), auditOutput)
+
+	a := NewAgent(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		return output.count("first", "") > 0 && output.count("second", "") > 0 && audit.count("second", "audited") > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-errC)
+
+	// Processors of a pipeline must not modify the metrics of other pipelines
+	require.Zero(t, output.count("second", "audited"))
+	require.Zero(t, audit.count("first", ""))
+}
+
+func TestAgent_PipelinesStartFailure(t *testing.T) {
+	output := &mockOutput{}
+
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(50 * time.Millisecond)
+	cfg.Pipelines = []string{"audit"}
+	cfg.Inputs = append(cfg.Inputs, newMockInput("first"))
+	cfg.Outputs = append(cfg.Outputs, newMockOutput(output))
+
+	processor := models.NewRunningProcessor(&mockFailingProcessor{}, &models.ProcessorConfig{
+		Name:     "mock_failing",
+		Pipeline: "audit",
+	})
+	cfg.Processors = append(cfg.Processors, processor)
+
+	// The outputs of the pipelines started before must be closed
+	a := NewAgent(cfg)
+	require.ErrorContains(t, a.Run(context.Background()), "start failed")
+	require.True(t, output.isClosed())
+}
+
+type mockInput struct {
+	name string
+}
+
+func newMockInput(name string) *models.RunningInput {
+	return models.NewRunningInput(&mockInput{name: name}, &models.InputConfig{
+		Name: "mock",
+		ID:   name,
+	})
+}
+
+func (*mockInput) SampleConfig() string {
+	return ""
+}
+
+func (m *mockInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields(m.name, map[string]interface{}{"value": 42}, nil)
+	return nil
+}
+
+type mockProcessor struct {
+	tag string
+}
+
+func newMockProcessor(tag string) *models.RunningProcessor {
+	processor := processors.NewStreamingProcessorFromProcessor(&mockProcessor{tag: tag})
+	return models.NewRunningProcessor(processor, &models.ProcessorConfig{
+		Name: "mock",
+		ID:   tag,
+	})
+}
+
+func (*mockProcessor) SampleConfig() string {
+	return ""
+}
+
+func (m *mockProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, metric := range in {
+		metric.AddTag(m.tag, "true")
+	}
+	return in
+}
+
+type mockServiceInput struct{}
+
+func (*mockServiceInput) SampleConfig() string {
+	return ""
+}
+
+func (*mockServiceInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*mockServiceInput) Start(telegraf.Accumulator) error {
+	return errors.New("start failed")
+}
+
+func (*mockServiceInput) Stop() {}
+
+type mockFailingProcessor struct{}
+
+func (*mockFailingProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*mockFailingProcessor) Start(telegraf.Accumulator) error {
+	return errors.New("start failed")
+}
+
+func (*mockFailingProcessor) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	acc.AddMetric(metric)
+	return nil
+}
+
+func (*mockFailingProcessor) Stop() {}
+
+type mockOutput struct {
+	metrics []telegraf.Metric
+	closed  bool
+	sync.Mutex
+}
+
+func newMockOutput(output *mockOutput) *models.RunningOutput {
+	return models.NewRunningOutput(output, &models.OutputConfig{
+		Name: "mock",
+		ID:   "output",
+	}, 1000, 10000)
+}
+
+func (*mockOutput) SampleConfig() string {
+	return ""
+}
+
+func (*mockOutput) Connect() error {
+	return nil
+}
+
+func (m *mockOutput) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockOutput) isClosed() bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.closed
+}
+
+func (m *mockOutput) Write(metrics []telegraf.Metric) error {
+	m.Lock()
+	defer m.Unlock()
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+// count returns the number of metrics with the given name and optionally
+// the given tag written to the output.
+func (m *mockOutput) count(name, tag string) int {
+	m.Lock()
+	defer m.Unlock()
+
+	var n int
+	for _, metric := range m.metrics {
+		if metric.Name() != name {
+			continue
+		}
+		if tag != "" && !metric.HasTag(tag) {
+			continue
+		}
+		n++
+	}
+	return n
+}
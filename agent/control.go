@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// ErrPluginNotFound is returned when controlling a plugin not running in the
+// agent.
+var ErrPluginNotFound = errors.New("plugin not found")
+
+// ErrPluginAmbiguous is returned when controlling a plugin by an ID shared by
+// multiple plugins. This happens for plugins with identical configurations.
+var ErrPluginAmbiguous = errors.New("ID matches multiple plugins, set an alias to distinguish them")
+
+// PluginInfo describes a plugin of the running agent.
+type PluginInfo struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Alias  string `json:"alias,omitempty"`
+	Paused bool   `json:"paused,omitempty"`
+
+	// Pipelines fed by an input or the pipeline of other plugins, empty
+	// for the default pipeline
+	Pipelines []string `json:"pipelines,omitempty"`
+	Pipeline  string   `json:"pipeline,omitempty"`
+
+	Buffer *BufferInfo      `json:"buffer,omitempty"`
+	Stats  map[string]int64 `json:"stats"`
+}
+
+// BufferInfo describes the fill level of an output buffer.
+type BufferInfo struct {
+	Size  int `json:"size"`
+	Limit int `json:"limit"`
+}
+
+// Plugins returns the plugins of the running agent including their
+// statistics.
+func (a *Agent) Plugins() ([]PluginInfo, error) {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	units := a.running
+	if units == nil {
+		return nil, errors.New("agent is not running")
+	}
+
+	units.inputs.Lock()
+	paused := make(map[*models.RunningInput]bool, len(units.inputs.paused))
+	for input, p := range units.inputs.paused {
+		paused[input] = p
+	}
+	units.inputs.Unlock()
+
+	plugins := make([]PluginInfo, 0, len(a.Config.Inputs)+len(a.Config.Processors)+
+		len(a.Config.Aggregators)+len(a.Config.AggProcessors)+len(a.Config.Outputs))
+	for _, input := range a.Config.Inputs {
+		plugins = append(plugins, PluginInfo{
+			ID:        input.ID(),
+			Type:      "input",
+			Name:      input.Config.Name,
+			Alias:     input.Config.Alias,
+			Paused:    paused[input],
+			Pipelines: input.Config.Pipelines,
+			Stats:     pluginStats("gather", "input", input.Config.Name, input.Config.Alias),
+		})
+	}
+	for _, processor := range a.Config.Processors {
+		plugins = append(plugins, PluginInfo{
+			ID:       processor.ID(),
+			Type:     "processor",
+			Name:     processor.Config.Name,
+			Alias:    processor.Config.Alias,
+			Pipeline: processor.Config.Pipeline,
+			Stats:    pluginStats("process", "processor", processor.Config.Name, processor.Config.Alias),
+		})
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		plugins = append(plugins, PluginInfo{
+			ID:       aggregator.ID(),
+			Type:     "aggregator",
+			Name:     aggregator.Config.Name,
+			Alias:    aggregator.Config.Alias,
+			Pipeline: aggregator.Config.Pipeline,
+			Stats:    pluginStats("aggregate", "aggregator", aggregator.Config.Name, aggregator.Config.Alias),
+		})
+	}
+	for _, processor := range a.Config.AggProcessors {
+		plugins = append(plugins, PluginInfo{
+			ID:       processor.ID(),
+			Type:     "processor",
+			Name:     processor.Config.Name,
+			Alias:    processor.Config.Alias,
+			Pipeline: processor.Config.Pipeline,
+			Stats:    pluginStats("process", "processor", processor.Config.Name, processor.Config.Alias),
+		})
+	}
+	for _, output := range a.Config.Outputs {
+		plugins = append(plugins, PluginInfo{
+			ID:       output.ID(),
+			Type:     "output",
+			Name:     output.Config.Name,
+			Alias:    output.Config.Alias,
+			Pipeline: output.Config.Pipeline,
+			Buffer: &BufferInfo{
+				Size:  output.BufferLength(),
+				Limit: output.MetricBufferLimit,
+			},
+			Stats: pluginStats("write", "output", output.Config.Name, output.Config.Alias),
+		})
+	}
+
+	return plugins, nil
+}
+
+// pluginStats returns the selfstat counters of the plugin with the given
+// name and alias.
+func pluginStats(measurement, kind, name, alias string) map[string]int64 {
+	tags := map[string]string{kind: name}
+	if alias != "" {
+		tags["alias"] = alias
+	}
+	return selfstat.Values(measurement, tags)
+}
+
+// PauseInput stops gathering the input with the given ID until it is resumed.
+// Service inputs keep running but are not gathered anymore.
+func (a *Agent) PauseInput(id string) error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	unit, input, err := a.findInput(id)
+	if err != nil {
+		return err
+	}
+
+	unit.Lock()
+	if unit.paused[input] {
+		unit.Unlock()
+		return nil
+	}
+	if unit.paused == nil {
+		unit.paused = make(map[*models.RunningInput]bool)
+	}
+	unit.paused[input] = true
+	loop := unit.loops[input]
+	delete(unit.loops, input)
+	unit.Unlock()
+
+	if loop != nil {
+		loop.stop()
+	}
+	log.Printf("I! [agent] Paused %s", input.LogName())
+
+	return nil
+}
+
+// ResumeInput restarts gathering the paused input with the given ID.
+func (a *Agent) ResumeInput(id string) error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	unit, input, err := a.findInput(id)
+	if err != nil {
+		return err
+	}
+
+	unit.Lock()
+	defer unit.Unlock()
+	if !unit.paused[input] {
+		return nil
+	}
+	delete(unit.paused, input)
+	a.runInput(time.Now(), unit, input)
+	log.Printf("I! [agent] Resumed %s", input.LogName())
+
+	return nil
+}
+
+// findInput returns the input unit and the running input with the given ID.
+// The reload lock must be held by the caller.
+func (a *Agent) findInput(id string) (*inputUnit, *models.RunningInput, error) {
+	if a.running == nil {
+		return nil, nil, errors.New("agent is not running")
+	}
+
+	unit := a.running.inputs
+	unit.Lock()
+	defer unit.Unlock()
+	var found *models.RunningInput
+	for _, input := range unit.inputs {
+		if input.ID() != id {
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("input %q: %w", id, ErrPluginAmbiguous)
+		}
+		found = input
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("input %q: %w", id, ErrPluginNotFound)
+	}
+	return unit, found, nil
+}
+
+// FlushOutput requests an immediate write of the buffered metrics of the
+// output with the given ID.
+func (a *Agent) FlushOutput(id string) error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	if a.running == nil {
+		return errors.New("agent is not running")
+	}
+
+	var unit *outputUnit
+	var output *models.RunningOutput
+	for _, pipeline := range a.running.pipelines {
+		pipeline.outputs.RLock()
+		for _, o := range pipeline.outputs.outputs {
+			if o.ID() != id {
+				continue
+			}
+			if output != nil {
+				pipeline.outputs.RUnlock()
+				return fmt.Errorf("output %q: %w", id, ErrPluginAmbiguous)
+			}
+			unit, output = pipeline.outputs, o
+		}
+		pipeline.outputs.RUnlock()
+	}
+	if output == nil {
+		return fmt.Errorf("output %q: %w", id, ErrPluginNotFound)
+	}
+
+	return unit.flush(output)
+}
+
+// flush triggers the flush loop of the given output.
+func (unit *outputUnit) flush(output *models.RunningOutput) error {
+	unit.RLock()
+	defer unit.RUnlock()
+
+	loop, found := unit.loops[output]
+	if !found || loop.trigger == nil {
+		return fmt.Errorf("output %q is not running", output.ID())
+	}
+	select {
+	case loop.trigger <- struct{}{}:
+	default:
+		// A flush is already pending
+	}
+	return nil
+}
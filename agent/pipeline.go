@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pipelineUnit is a pipeline consisting of a chain of processors and
+// aggregators and the outputs receiving the metrics of the chain. Inputs
+// write to the source channel of the chain of each pipeline they feed.
+//
+//              ┌──────────┐     ┌─────────┐
+//         ┌──▶ │  Chain   │──▶ │ Outputs │
+//  ______ │    └──────────┘     └─────────┘
+// ()_____)┤
+//         │    ┌──────────┐     ┌─────────┐
+//         └──▶ │  Chain   │──▶ │ Outputs │
+//              └──────────┘     └─────────┘
+
+type pipelineUnit struct {
+	chain   *chainUnit
+	outputs *outputUnit
+}
+
+// pipelinePlugins are the processors, aggregators and outputs of a single
+// pipeline.
+type pipelinePlugins struct {
+	processors    models.RunningProcessors
+	aggregators   []*models.RunningAggregator
+	aggProcessors models.RunningProcessors
+	outputs       []*models.RunningOutput
+}
+
+// pipelineNames returns the names of all pipelines including the default
+// pipeline denoted by an empty name.
+func (a *Agent) pipelineNames() []string {
+	return append([]string{""}, a.Config.Pipelines...)
+}
+
+// selectPipeline returns the plugins belonging to the pipeline with the given
+// name keeping their order.
+func selectPipeline(
+	name string,
+	processors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+	outputs []*models.RunningOutput,
+) *pipelinePlugins {
+	p := &pipelinePlugins{}
+	for _, processor := range processors {
+		if processor.Config.Pipeline == name {
+			p.processors = append(p.processors, processor)
+		}
+	}
+	for _, aggregator := range aggregators {
+		if aggregator.Config.Pipeline == name {
+			p.aggregators = append(p.aggregators, aggregator)
+		}
+	}
+	for _, processor := range aggProcessors {
+		if processor.Config.Pipeline == name {
+			p.aggProcessors = append(p.aggProcessors, processor)
+		}
+	}
+	for _, output := range outputs {
+		if output.Config.Pipeline == name {
+			p.outputs = append(p.outputs, output)
+		}
+	}
+	return p
+}
+
+// startPipelines connects the outputs and starts the processors and
+// aggregators of all pipelines. If a pipeline fails to start, the pipelines
+// already started are stopped again.
+func (a *Agent) startPipelines(ctx context.Context, startTime time.Time) (map[string]*pipelineUnit, error) {
+	units := make(map[string]*pipelineUnit, len(a.Config.Pipelines)+1)
+	for _, name := range a.pipelineNames() {
+		p := selectPipeline(name, a.Config.Processors, a.Config.Aggregators, a.Config.AggProcessors, a.Config.Outputs)
+
+		next, ou, err := a.startOutputs(ctx, p.outputs)
+		if err != nil {
+			a.stopPipelines(units)
+			return nil, err
+		}
+
+		cu := newChainUnit(next)
+		if err := a.startChain(cu, startTime, p.processors, p.aggregators, p.aggProcessors, nil); err != nil {
+			stopRunningOutputs(ou.outputs)
+			a.stopPipelines(units)
+			return nil, err
+		}
+
+		units[name] = &pipelineUnit{chain: cu, outputs: ou}
+	}
+	return units, nil
+}
+
+// stopPipelines stops the chains and closes the outputs of started pipelines
+// that are not running yet. Metrics emitted by the chains on stop are dropped
+// as the outputs never ran.
+func (a *Agent) stopPipelines(units map[string]*pipelineUnit) {
+	for name, unit := range units {
+		log.Printf("D! [agent] Stopping pipeline%s", pipelineLogSuffix(name))
+
+		drained := make(chan struct{})
+		go func(src <-chan telegraf.Metric) {
+			defer close(drained)
+			for m := range src {
+				m.Drop()
+			}
+		}(unit.outputs.src)
+
+		close(unit.chain.head)
+		<-unit.chain.done
+		close(unit.chain.dst)
+		<-drained
+
+		stopRunningOutputs(unit.outputs.outputs)
+	}
+}
+
+// runPipelines runs the chains and outputs of all pipelines until the source
+// channels of the chains are closed and all metrics have been written.
+func (a *Agent) runPipelines(units map[string]*pipelineUnit) {
+	var wg sync.WaitGroup
+	for _, unit := range units {
+		wg.Add(1)
+		go func(unit *pipelineUnit) {
+			defer wg.Done()
+			a.runOutputs(unit.outputs)
+		}(unit)
+
+		wg.Add(1)
+		go func(unit *pipelineUnit) {
+			defer wg.Done()
+			a.runChain(unit.chain)
+		}(unit)
+	}
+	wg.Wait()
+}
+
+// pipelineSources returns the source channels of the pipelines by name.
+func pipelineSources(units map[string]*pipelineUnit) map[string]chan<- telegraf.Metric {
+	sources := make(map[string]chan<- telegraf.Metric, len(units))
+	for name, unit := range units {
+		sources[name] = unit.chain.src
+	}
+	return sources
+}
+
+// pipelineLogSuffix returns the suffix for log messages concerning the
+// pipeline with the given name, empty for the default pipeline.
+func pipelineLogSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " of pipeline " + name
+}
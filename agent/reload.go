@@ -0,0 +1,489 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+)
+
+// runningUnits are the units of a running agent.
+type runningUnits struct {
+	inputs    *inputUnit
+	pipelines map[string]*pipelineUnit
+}
+
+// chainUnit connects the inputs to the outputs via the processors and
+// aggregators. The chain of processors and aggregators can be replaced while
+// the agent is running.
+//
+//  ______     ┌────────┐     ┌────────────┐     ______
+// ()_____)──▶ │ Router │──▶ │ Processors │──▶ ()_____)
+//             └────────┘     │ Aggregators│
+//                            └────────────┘
+
+type chainUnit struct {
+	src chan telegraf.Metric
+	dst chan<- telegraf.Metric
+
+	sync.Mutex
+	head        chan<- telegraf.Metric
+	done        chan struct{}
+	aggregators *aggregatorUnit
+}
+
+func newChainUnit(dst chan<- telegraf.Metric) *chainUnit {
+	return &chainUnit{
+		src: make(chan telegraf.Metric, 100),
+		dst: dst,
+	}
+}
+
+// startChain sets up and starts the processors and aggregators of the chain.
+// Aggregators contained in resumed keep their current aggregation window.
+func (a *Agent) startChain(
+	unit *chainUnit,
+	startTime time.Time,
+	processors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+	resumed map[*models.RunningAggregator]bool,
+) error {
+	tail := make(chan telegraf.Metric, 100)
+	next := chan<- telegraf.Metric(tail)
+
+	var err error
+	var apu []*processorUnit
+	var au *aggregatorUnit
+	if len(aggregators) != 0 {
+		aggC := next
+		if len(aggProcessors) != 0 && !a.Config.Agent.SkipProcessorsAfterAggregators {
+			aggC, apu, err = a.startProcessors(next, aggProcessors)
+			if err != nil {
+				return err
+			}
+		}
+
+		next, au = a.startAggregators(aggC, next, aggregators)
+		au.resumed = resumed
+	}
+
+	var pu []*processorUnit
+	if len(processors) != 0 {
+		next, pu, err = a.startProcessors(next, processors)
+		if err != nil {
+			for _, u := range apu {
+				u.processor.Stop()
+			}
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	if au != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(apu)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runAggregators(startTime, au)
+		}()
+	}
+
+	if pu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(pu)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for m := range tail {
+			unit.dst <- m
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	unit.head = next
+	unit.done = done
+	unit.aggregators = au
+
+	return nil
+}
+
+// runChain routes the metrics from the inputs to the current chain and runs
+// until the source channel is closed and all metrics have been processed.
+func (a *Agent) runChain(unit *chainUnit) {
+	for m := range unit.src {
+		unit.Lock()
+		unit.head <- m
+		unit.Unlock()
+	}
+
+	unit.Lock()
+	close(unit.head)
+	<-unit.done
+	unit.Unlock()
+
+	close(unit.dst)
+	log.Printf("D! [agent] Chain channel closed")
+}
+
+// replaceChain stops the current chain after processing all pending metrics
+// and starts a new chain with the given plugins. Aggregators contained in
+// both chains continue their aggregation window.
+func (a *Agent) replaceChain(
+	unit *chainUnit,
+	processors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+) error {
+	unit.Lock()
+	defer unit.Unlock()
+
+	retained := make(map[*models.RunningAggregator]bool)
+	if unit.aggregators != nil {
+		for _, agg := range unit.aggregators.aggregators {
+			if slices.Contains(aggregators, agg) {
+				retained[agg] = true
+			}
+		}
+		unit.aggregators.retained = retained
+	}
+
+	close(unit.head)
+	<-unit.done
+
+	startTime := time.Now()
+	if err := a.startChain(unit, startTime, processors, aggregators, aggProcessors, retained); err != nil {
+		// Keep the metrics flowing to the outputs until the agent is restarted
+		if err := a.startChain(unit, startTime, nil, nil, nil, nil); err != nil {
+			log.Printf("E! [agent] Starting empty chain failed: %v", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Reload applies the given configuration changes to the running agent. Only
+// the plugins added or removed are started or stopped, all other plugins
+// continue to run. The processors and aggregators are only restarted if any
+// of them changed. If applying the changes fails, the changes applied so far
+// are rolled back so the running plugins match the current configuration.
+func (a *Agent) Reload(ctx context.Context, diff *config.Diff) (err error) {
+	if diff.RestartReason != "" {
+		return fmt.Errorf("changes require a restart: %s", diff.RestartReason)
+	}
+
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	units := a.running
+	if units == nil {
+		return errors.New("agent is not running")
+	}
+
+	var rollback []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		log.Printf("W! [agent] Reloading failed, rolling back changes")
+		for i := len(rollback) - 1; i >= 0; i-- {
+			rollback[i]()
+		}
+	}()
+
+	// Outputs taking over the disk buffer of a removed output must not open
+	// the buffer before the removed output closed it
+	for _, output := range diff.Outputs.Removed {
+		path := output.BufferPath()
+		if path == "" {
+			continue
+		}
+		if slices.ContainsFunc(diff.Outputs.Added, func(o *models.RunningOutput) bool { return o.BufferPath() == path }) {
+			log.Printf("D! [agent] Stopping output %s", output.LogName())
+			a.removeOutput(units.pipelines[output.Config.Pipeline].outputs, output)
+			rollback = append(rollback, func() { a.restoreOutput(ctx, units, output) })
+		}
+	}
+
+	// Added outputs might be initialized or connected even if the step fails
+	rollback = append(rollback, func() {
+		for _, output := range diff.Outputs.Added {
+			a.discardOutput(units, output)
+		}
+	})
+
+	log.Printf("D! [agent] Initializing new plugins")
+	err = a.initializePlugins(
+		diff.Inputs.Added,
+		diff.Processors.Added,
+		diff.Aggregators.Added,
+		diff.AggProcessors.Added,
+		diff.Outputs.Added,
+	)
+	if err != nil {
+		return err
+	}
+
+	if a.Config.Persister != nil {
+		a.unregisterStates(
+			diff.Inputs.Removed,
+			diff.Processors.Removed,
+			diff.Aggregators.Removed,
+			diff.AggProcessors.Removed,
+			diff.Outputs.Removed,
+		)
+		rollback = append(rollback, func() {
+			a.unregisterStates(
+				diff.Inputs.Added,
+				diff.Processors.Added,
+				diff.Aggregators.Added,
+				diff.AggProcessors.Added,
+				diff.Outputs.Added,
+			)
+			err := a.registerStates(
+				diff.Inputs.Removed,
+				diff.Processors.Removed,
+				diff.Aggregators.Removed,
+				diff.AggProcessors.Removed,
+				diff.Outputs.Removed,
+			)
+			if err != nil {
+				log.Printf("E! [agent] Restoring states failed: %v", err)
+			}
+		})
+		err := a.registerStates(
+			diff.Inputs.Added,
+			diff.Processors.Added,
+			diff.Aggregators.Added,
+			diff.AggProcessors.Added,
+			diff.Outputs.Added,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, input := range diff.Inputs.Removed {
+		log.Printf("D! [agent] Stopping input %s", input.LogName())
+		a.removeInput(units.inputs, input)
+		rollback = append(rollback, func() { a.restoreInput(units, input) })
+	}
+
+	// Connect new outputs before changing the chain to not lose any metrics
+	// routed to the new outputs
+	for _, output := range diff.Outputs.Added {
+		if err := a.addOutput(ctx, units.pipelines[output.Config.Pipeline].outputs, output); err != nil {
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				log.Printf("I! [agent] Failed to connect to [%s], error was %q; shutting down plugin...", output.LogName(), err)
+				continue
+			}
+			return fmt.Errorf("connecting output %s: %w", output.LogName(), err)
+		}
+	}
+
+	if diff.Processors.Changed() || diff.Aggregators.Changed() || diff.AggProcessors.Changed() {
+		// Only restart the chains of the pipelines affected by the changes
+		for _, name := range a.pipelineNames() {
+			current := selectPipeline(name, a.Config.Processors, a.Config.Aggregators, a.Config.AggProcessors, nil)
+			p := selectPipeline(name, diff.Processors.Plugins, diff.Aggregators.Plugins, diff.AggProcessors.Plugins, nil)
+			if slices.Equal(current.processors, p.processors) &&
+				slices.Equal(current.aggregators, p.aggregators) &&
+				slices.Equal(current.aggProcessors, p.aggProcessors) {
+				continue
+			}
+
+			log.Printf("D! [agent] Restarting processors and aggregators%s", pipelineLogSuffix(name))
+			chain := units.pipelines[name].chain
+			rollback = append(rollback, func() {
+				log.Printf("D! [agent] Restoring processors and aggregators%s", pipelineLogSuffix(name))
+				if err := a.replaceChain(chain, current.processors, current.aggregators, current.aggProcessors); err != nil {
+					log.Printf("E! [agent] Restoring processors and aggregators%s failed: %v", pipelineLogSuffix(name), err)
+				}
+			})
+			if err := a.replaceChain(chain, p.processors, p.aggregators, p.aggProcessors); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, output := range diff.Outputs.Removed {
+		log.Printf("D! [agent] Stopping output %s", output.LogName())
+		if a.removeOutput(units.pipelines[output.Config.Pipeline].outputs, output) {
+			rollback = append(rollback, func() { a.restoreOutput(ctx, units, output) })
+		}
+	}
+
+	for _, input := range diff.Inputs.Added {
+		if err := a.addInput(units.inputs, input); err != nil {
+			var fatalErr *internal.FatalError
+			if errors.As(err, &fatalErr) {
+				log.Printf("I! [agent] Failed to start %s, error was %q; shutting down plugin...", input.LogName(), err)
+				continue
+			}
+			return fmt.Errorf("starting input %s: %w", input.LogName(), err)
+		}
+		rollback = append(rollback, func() { a.removeInput(units.inputs, input) })
+	}
+
+	a.Config.Inputs = diff.Inputs.Plugins
+	a.Config.Processors = diff.Processors.Plugins
+	a.Config.Aggregators = diff.Aggregators.Plugins
+	a.Config.AggProcessors = diff.AggProcessors.Plugins
+	a.Config.Outputs = diff.Outputs.Plugins
+
+	return nil
+}
+
+// restoreInput restarts an input stopped by a failed reload. The input is
+// removed from the configuration if it cannot be started again.
+func (a *Agent) restoreInput(units *runningUnits, input *models.RunningInput) {
+	log.Printf("D! [agent] Restoring input %s", input.LogName())
+	if err := a.addInput(units.inputs, input); err != nil {
+		log.Printf("E! [agent] Restoring input %s failed: %v", input.LogName(), err)
+		a.Config.Inputs = slices.DeleteFunc(a.Config.Inputs, func(i *models.RunningInput) bool { return i == input })
+	}
+}
+
+// restoreOutput reconnects an output closed by a failed reload. The output is
+// removed from the configuration if it cannot be connected again.
+func (a *Agent) restoreOutput(ctx context.Context, units *runningUnits, output *models.RunningOutput) {
+	log.Printf("D! [agent] Restoring output %s", output.LogName())
+
+	// Closing the output also closed its buffer
+	err := output.Init()
+	if err == nil {
+		err = a.addOutput(ctx, units.pipelines[output.Config.Pipeline].outputs, output)
+	}
+	if err != nil {
+		log.Printf("E! [agent] Restoring output %s failed: %v", output.LogName(), err)
+		a.Config.Outputs = slices.DeleteFunc(a.Config.Outputs, func(o *models.RunningOutput) bool { return o == output })
+	}
+}
+
+// discardOutput stops and closes an output added by a failed reload.
+func (a *Agent) discardOutput(units *runningUnits, output *models.RunningOutput) {
+	if !a.removeOutput(units.pipelines[output.Config.Pipeline].outputs, output) {
+		output.Close()
+	}
+}
+
+// addInput starts the given input and its gather loop.
+func (a *Agent) addInput(unit *inputUnit, input *models.RunningInput) error {
+	if err := a.startInput(unit.destinations(input), input); err != nil {
+		return err
+	}
+
+	unit.Lock()
+	defer unit.Unlock()
+	unit.inputs = append(unit.inputs, input)
+	a.runInput(time.Now(), unit, input)
+
+	return nil
+}
+
+// removeInput stops the gather loop of the given input and stops the input.
+func (a *Agent) removeInput(unit *inputUnit, input *models.RunningInput) {
+	unit.Lock()
+	idx := slices.Index(unit.inputs, input)
+	if idx < 0 {
+		unit.Unlock()
+		return
+	}
+	unit.inputs = slices.Delete(unit.inputs, idx, idx+1)
+	loop := unit.loops[input]
+	delete(unit.loops, input)
+	delete(unit.paused, input)
+	unit.Unlock()
+
+	if loop != nil {
+		loop.stop()
+	}
+	input.Stop()
+}
+
+// addOutput connects the given output and starts its flush loop.
+func (a *Agent) addOutput(ctx context.Context, unit *outputUnit, output *models.RunningOutput) error {
+	output.SetDeadLetterRouter(unit.routeDeadLetter)
+	if err := a.connectOutput(ctx, output); err != nil {
+		return err
+	}
+
+	unit.Lock()
+	defer unit.Unlock()
+	unit.outputs = append(unit.outputs, output)
+	a.runOutput(unit, output)
+
+	return nil
+}
+
+// removeOutput stops routing metrics to the given output, flushes the
+// remaining metrics and closes the output. It returns false if the output is
+// not part of the unit.
+func (a *Agent) removeOutput(unit *outputUnit, output *models.RunningOutput) bool {
+	unit.Lock()
+	idx := slices.Index(unit.outputs, output)
+	if idx < 0 {
+		unit.Unlock()
+		return false
+	}
+	unit.outputs = slices.Delete(unit.outputs, idx, idx+1)
+	loop := unit.loops[output]
+	delete(unit.loops, output)
+	unit.Unlock()
+
+	if loop != nil {
+		loop.stop()
+	}
+	output.Close()
+	return true
+}
+
+// unregisterStates removes the given plugins from the persister.
+func (a *Agent) unregisterStates(
+	inputs []*models.RunningInput,
+	runningProcessors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+	aggProcessors models.RunningProcessors,
+	outputs []*models.RunningOutput,
+) {
+	for _, input := range inputs {
+		a.Config.Persister.Unregister(input.ID())
+	}
+	for _, processor := range runningProcessors {
+		a.Config.Persister.Unregister(processor.ID())
+	}
+	for _, aggregator := range aggregators {
+		a.Config.Persister.Unregister(aggregator.ID())
+	}
+	for _, processor := range aggProcessors {
+		a.Config.Persister.Unregister(processor.ID())
+	}
+	for _, output := range outputs {
+		a.Config.Persister.Unregister(output.ID())
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+// startAPI starts the control API of the running agent on the given address.
+// Addresses starting with "unix://" denote a Unix socket, all others are
+// TCP addresses. TCP addresses without host listen on the loopback interface
+// and other non-loopback addresses require an API token.
+func (t *Telegraf) startAPI(address string) (*http.Server, error) {
+	address, err := apiAddress(address, t.apiToken != "")
+	if err != nil {
+		return nil, err
+	}
+
+	handler := t.apiHandler()
+	if t.apiToken != "" {
+		handler = apiAuth(t.apiToken, handler)
+	}
+	return startServer("API", address, handler)
+}
+
+// apiAddress restricts the API to the loopback interface unless requests are
+// authenticated.
+func apiAddress(address string, authenticated bool) (string, error) {
+	if strings.HasPrefix(address, "unix://") {
+		return address, nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid API address %q: %w", address, err)
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if host == "localhost" {
+		return address, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return address, nil
+	}
+	if !authenticated {
+		return "", fmt.Errorf("API address %q is not a loopback address, an API token is required", address)
+	}
+	return address, nil
+}
+
+// apiAuth requires requests to carry the given token as bearer token.
+func apiAuth(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			apiError(w, http.StatusUnauthorized, errors.New("invalid or missing API token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startServer serves the handler on the given TCP address or unix://<path>
+// socket.
+func startServer(name, address string, handler http.Handler) (*http.Server, error) {
+	network := "tcp"
+	if strings.HasPrefix(address, "unix://") {
+		network = "unix"
+		address = strings.TrimPrefix(address, "unix://")
+
+		// Remove stale sockets left behind by a previous instance
+		if err := os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("starting %s server failed: %w", name, err)
+	}
+	if network == "unix" {
+		if err := os.Chmod(address, 0o600); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("setting socket permissions failed: %w", err)
+		}
+	}
+
+	server := &http.Server{
+		Handler:      handler,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! %s server failed: %v", name, err)
+		}
+	}()
+	log.Printf("I! Starting %s server at: %s://%s", name, network, address)
+
+	return server, nil
+}
+
+func (t *Telegraf) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", t.apiPlugins)
+	mux.HandleFunc("POST /api/v1/reload", t.apiReload)
+	mux.HandleFunc("POST /api/v1/inputs/{id}/pause", t.apiPauseInput)
+	mux.HandleFunc("POST /api/v1/inputs/{id}/resume", t.apiResumeInput)
+	mux.HandleFunc("POST /api/v1/outputs/{id}/flush", t.apiFlushOutput)
+	return mux
+}
+
+func (t *Telegraf) apiPlugins(w http.ResponseWriter, _ *http.Request) {
+	ag := t.running.Load()
+	if ag == nil {
+		apiError(w, http.StatusServiceUnavailable, errors.New("agent is not running"))
+		return
+	}
+
+	plugins, err := ag.Plugins()
+	if err != nil {
+		apiError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plugins); err != nil {
+		log.Printf("E! Writing API response failed: %v", err)
+	}
+}
+
+func (t *Telegraf) apiReload(w http.ResponseWriter, _ *http.Request) {
+	signals := t.signals.Load()
+	if signals == nil {
+		apiError(w, http.StatusServiceUnavailable, errors.New("agent is not running"))
+		return
+	}
+
+	log.Println("I! Reload requested via API")
+	select {
+	case *signals <- syscall.SIGHUP:
+	default:
+		// A signal is already pending
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (t *Telegraf) apiPauseInput(w http.ResponseWriter, r *http.Request) {
+	t.apiControl(w, r, (*agent.Agent).PauseInput)
+}
+
+func (t *Telegraf) apiResumeInput(w http.ResponseWriter, r *http.Request) {
+	t.apiControl(w, r, (*agent.Agent).ResumeInput)
+}
+
+func (t *Telegraf) apiFlushOutput(w http.ResponseWriter, r *http.Request) {
+	t.apiControl(w, r, (*agent.Agent).FlushOutput)
+}
+
+// apiControl applies the given control function to the plugin with the ID
+// given in the request path.
+func (t *Telegraf) apiControl(w http.ResponseWriter, r *http.Request, control func(*agent.Agent, string) error) {
+	ag := t.running.Load()
+	if ag == nil {
+		apiError(w, http.StatusServiceUnavailable, errors.New("agent is not running"))
+		return
+	}
+
+	if err := control(ag, r.PathValue("id")); err != nil {
+		status := http.StatusServiceUnavailable
+		switch {
+		case errors.Is(err, agent.ErrPluginNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, agent.ErrPluginAmbiguous):
+			status = http.StatusConflict
+		}
+		apiError(w, status, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	response := map[string]string{"error": err.Error()}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("E! Writing API response failed: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestAPI(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+
+	tg := &Telegraf{}
+	server, err := tg.startAPI("unix://" + socket)
+	require.NoError(t, err)
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	request := func(method, path string) *http.Response {
+		req, err := http.NewRequest(method, "http://telegraf"+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	// Requests must fail without a running agent
+	resp := request(http.MethodGet, "/api/v1/plugins")
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp = request(http.MethodPost, "/api/v1/reload")
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// Start an agent
+	cfg := config.NewConfig()
+	cfg.Agent.Interval = config.Duration(50 * time.Millisecond)
+	cfg.Agent.FlushInterval = config.Duration(time.Hour)
+	cfg.Inputs = append(cfg.Inputs, models.NewRunningInput(&apiInput{}, &models.InputConfig{Name: "mock", ID: "in"}))
+	cfg.Outputs = append(cfg.Outputs, models.NewRunningOutput(&apiOutput{}, &models.OutputConfig{Name: "mock", ID: "out"}, 0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ag := agent.NewAgent(cfg)
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ag.Run(ctx)
+	}()
+	tg.running.Store(ag)
+
+	var plugins []agent.PluginInfo
+	require.Eventually(t, func() bool {
+		return request(http.MethodGet, "/api/v1/plugins").StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+	resp = request(http.MethodGet, "/api/v1/plugins")
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plugins))
+	require.Len(t, plugins, 2)
+	require.Equal(t, "in", plugins[0].ID)
+	require.Equal(t, "out", plugins[1].ID)
+	require.NotNil(t, plugins[1].Buffer)
+
+	// Control the plugins
+	resp = request(http.MethodPost, "/api/v1/inputs/in/pause")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = request(http.MethodGet, "/api/v1/plugins")
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plugins))
+	require.True(t, plugins[0].Paused)
+	resp = request(http.MethodPost, "/api/v1/inputs/in/resume")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = request(http.MethodPost, "/api/v1/outputs/out/flush")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = request(http.MethodPost, "/api/v1/inputs/unknown/pause")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = request(http.MethodGet, "/api/v1/outputs/out/flush")
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// Trigger a reload
+	signals := make(chan os.Signal, 1)
+	tg.signals.Store(&signals)
+	resp = request(http.MethodPost, "/api/v1/reload")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, syscall.SIGHUP, <-signals)
+
+	cancel()
+	require.NoError(t, <-errC)
+}
+
+func TestAPIAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		address       string
+		authenticated bool
+		expected      string
+		err           string
+	}{
+		{name: "socket", address: "unix:///run/telegraf/api.sock", expected: "unix:///run/telegraf/api.sock"},
+		{name: "no host", address: ":8088", expected: "127.0.0.1:8088"},
+		{name: "localhost", address: "localhost:8088", expected: "localhost:8088"},
+		{name: "loopback IPv4", address: "127.0.0.1:8088", expected: "127.0.0.1:8088"},
+		{name: "loopback IPv6", address: "[::1]:8088", expected: "[::1]:8088"},
+		{name: "remote", address: "0.0.0.0:8088", err: "an API token is required"},
+		{name: "remote authenticated", address: "0.0.0.0:8088", authenticated: true, expected: "0.0.0.0:8088"},
+		{name: "invalid", address: "localhost", err: "invalid API address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := apiAddress(tt.address, tt.authenticated)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, address)
+		})
+	}
+}
+
+func TestAPIToken(t *testing.T) {
+	tg := &Telegraf{}
+	tg.apiToken = "secret"
+	server := httptest.NewServer(apiAuth(tg.apiToken, tg.apiHandler()))
+	defer server.Close()
+
+	request := func(token string) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/plugins", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusUnauthorized, request(""))
+	require.Equal(t, http.StatusUnauthorized, request("wrong"))
+	// No agent is running but the request passed the authentication
+	require.Equal(t, http.StatusServiceUnavailable, request("secret"))
+}
+
+type apiInput struct{}
+
+func (*apiInput) SampleConfig() string {
+	return ""
+}
+
+func (*apiInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("test", map[string]interface{}{"value": 42}, nil)
+	return nil
+}
+
+type apiOutput struct{}
+
+func (*apiOutput) SampleConfig() string {
+	return ""
+}
+
+func (*apiOutput) Connect() error {
+	return nil
+}
+
+func (*apiOutput) Close() error {
+	return nil
+}
+
+func (*apiOutput) Write([]telegraf.Metric) error {
+	return nil
+}
@@ -21,7 +21,7 @@ func getConfigCommands(pluginFilterFlags []cli.Flag, outputBuffer io.Writer) []*
 	return []*cli.Command{
 		{
 			Name:  "config",
-			Usage: "commands for generating and migrating configurations",
+			Usage: "commands for generating, checking and migrating configurations",
 			Flags: pluginFilterFlags,
 			Action: func(cCtx *cli.Context) error {
 				// The sub_Filters are populated when the filter flags are set after the subcommand config
@@ -57,6 +57,53 @@ InfluxDB v2 output plugin use
 						return nil
 					},
 				},
+				{
+					Name:  "check",
+					Usage: "check the configuration(s) for errors without running any plugin",
+					Description: `
+The 'check' command reads the configuration files specified via '--config' or
+'--config-directory' and checks them for errors without gathering or writing
+any metrics. If no configuration file is explicitly specified the command reads
+the default locations and uses those configuration files. Besides parsing the
+files, the command initializes all plugins, compiles filters and expressions,
+resolves secret references without revealing the secrets and reports unused
+or deprecated options. The command exits with an error if any problem other
+than a deprecation warning is found, e.g. for use in CI pipelines.
+
+To check the file 'mysettings.conf' use
+
+> telegraf --config mysettings.conf config check
+`,
+					Action: func(cCtx *cli.Context) error {
+						// Setup logging
+						telegraf.Debug = cCtx.Bool("debug")
+						logConfig := logger.LogConfig{Debug: telegraf.Debug}
+						if err := logger.SetupLogging(logConfig); err != nil {
+							return err
+						}
+
+						configFiles, err := collectConfigFiles(cCtx)
+						if err != nil {
+							return err
+						}
+
+						c := config.NewConfig()
+						issues := c.Check(configFiles...)
+
+						var errCount int
+						for _, issue := range issues {
+							fmt.Fprintln(outputBuffer, issue.String())
+							if !issue.Warning {
+								errCount++
+							}
+						}
+						if errCount > 0 {
+							return fmt.Errorf("found %d error(s) in configuration", errCount)
+						}
+						fmt.Fprintf(outputBuffer, "Configuration OK (%d warning(s))\n", len(issues))
+						return nil
+					},
+				},
 				{
 					Name:  "migrate",
 					Usage: "migrate deprecated plugins and options of the configuration(s)",
@@ -98,25 +145,9 @@ To migrate the file 'mysettings.conf' use
 						}
 						log.Printf("%d plugin migration(s) available", len(migrations.PluginMigrations))
 
-						// Collect the given configuration files
-						configFiles := cCtx.StringSlice("config")
-						configDir := cCtx.StringSlice("config-directory")
-						for _, fConfigDirectory := range configDir {
-							files, err := config.WalkDirectory(fConfigDirectory)
-							if err != nil {
-								return err
-							}
-							configFiles = append(configFiles, files...)
-						}
-
-						// If no "config" or "config-directory" flag(s) was
-						// provided we should load default configuration files
-						if len(configFiles) == 0 {
-							paths, err := config.GetDefaultConfigPath()
-							if err != nil {
-								return err
-							}
-							configFiles = paths
+						configFiles, err := collectConfigFiles(cCtx)
+						if err != nil {
+							return err
 						}
 
 						for _, fn := range configFiles {
@@ -172,3 +203,29 @@ To migrate the file 'mysettings.conf' use
 		},
 	}
 }
+
+// collectConfigFiles returns the configuration files given via the "config"
+// and "config-directory" flags or the default configuration files if none
+// are given.
+func collectConfigFiles(cCtx *cli.Context) ([]string, error) {
+	configFiles := cCtx.StringSlice("config")
+	configDir := cCtx.StringSlice("config-directory")
+	for _, fConfigDirectory := range configDir {
+		files, err := config.WalkDirectory(fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+		configFiles = append(configFiles, files...)
+	}
+
+	// If no "config" or "config-directory" flag(s) was
+	// provided we should load default configuration files
+	if len(configFiles) == 0 {
+		paths, err := config.GetDefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		configFiles = paths
+	}
+	return configFiles, nil
+}
@@ -226,6 +226,9 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			testWait:       cCtx.Int("test-wait"),
 			watchConfig:    cCtx.String("watch-config"),
 			pidFile:        cCtx.String("pidfile"),
+			apiAddr:        cCtx.String("api-addr"),
+			apiToken:       cCtx.String("api-token"),
+			metricsAddr:    cCtx.String("metrics-addr"),
 			plugindDir:     cCtx.String("plugin-directory"),
 			password:       cCtx.String("password"),
 			oldEnvBehavior: cCtx.Bool("old-env-behavior"),
@@ -285,6 +288,19 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Name:  "pprof-addr",
 					Usage: "pprof host/IP and port to listen on (e.g. 'localhost:6060')",
 				},
+				&cli.StringFlag{
+					Name:  "api-addr",
+					Usage: "control API host/IP and port or unix://<path> socket to listen on (e.g. 'localhost:8088')",
+				},
+				&cli.StringFlag{
+					Name:    "api-token",
+					Usage:   "bearer token required for control API requests, mandatory for non-loopback addresses",
+					EnvVars: []string{"TELEGRAF_API_TOKEN"},
+				},
+				&cli.StringFlag{
+					Name:  "metrics-addr",
+					Usage: "internal metrics host/IP and port or unix://<path> socket to listen on (e.g. 'localhost:9273')",
+				},
 				&cli.StringFlag{
 					Name:  "watch-config",
 					Usage: "monitoring config changes [notify, poll] of --config and --config-directory options",
@@ -270,6 +270,28 @@ func TestPprofAddressFlag(t *testing.T) {
 	require.Equal(t, address, m.Address)
 }
 
+func TestAPIAddressFlag(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	address := "unix:///run/telegraf/api.sock"
+	args = append(args, "--api-addr", address)
+	m := NewMockTelegraf()
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), m)
+	require.NoError(t, err)
+	require.Equal(t, address, m.apiAddr)
+}
+
+func TestMetricsAddressFlag(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	address := "localhost:9273"
+	args = append(args, "--metrics-addr", address)
+	m := NewMockTelegraf()
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), m)
+	require.NoError(t, err)
+	require.Equal(t, address, m.metricsAddr)
+}
+
 // !!! DEPRECATED !!!
 // TestPluginDirectoryFlag tests `--plugin-directory`
 func TestPluginDirectoryFlag(t *testing.T) {
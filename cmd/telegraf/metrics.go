@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// startMetricsServer serves the internal statistics of Telegraf in the
+// Prometheus text format. The statistics are read directly from selfstat so
+// they are available even if the outputs or the agent are stuck.
+func startMetricsServer(address string) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", serveMetrics)
+	return startServer("metrics", address, mux)
+}
+
+func serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	serializer := &prometheus.Serializer{
+		FormatConfig: prometheus.FormatConfig{SortMetrics: true},
+	}
+	if err := serializer.Init(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := serializer.SerializeBatch(selfstat.Snapshot())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write(body); err != nil {
+		log.Printf("E! Writing metrics response failed: %v", err)
+	}
+}
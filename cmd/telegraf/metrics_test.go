@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+func TestMetricsServer(t *testing.T) {
+	stat := selfstat.Register("metrics_server_test", "requests", map[string]string{"test": "value"})
+	stat.Set(42)
+
+	socket := filepath.Join(t.TempDir(), "metrics.sock")
+	server, err := startMetricsServer("unix://" + socket)
+	require.NoError(t, err)
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://telegraf/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `internal_metrics_server_test_requests{test="value"} 42`)
+
+	resp, err = client.Post("http://telegraf/metrics", "text/plain", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -37,6 +38,9 @@ type GlobalFlags struct {
 	testWait       int
 	watchConfig    string
 	pidFile        string
+	apiAddr        string
+	apiToken       string
+	metricsAddr    string
 	plugindDir     string
 	password       string
 	oldEnvBehavior bool
@@ -73,6 +77,14 @@ type Telegraf struct {
 	configFiles        []string
 	secretstoreFilters []string
 
+	// Currently running agent used for reloading plugins and the channel
+	// used for requesting a reload
+	running atomic.Pointer[agent.Agent]
+	signals atomic.Pointer[chan os.Signal]
+
+	// Most recently loaded configuration used for watching remote configs
+	loaded atomic.Pointer[config.Config]
+
 	GlobalFlags
 	WindowFlags
 }
@@ -135,6 +147,22 @@ func (t *Telegraf) reloadLoop() error {
 		return err
 	}
 
+	if t.apiAddr != "" {
+		server, err := t.startAPI(t.apiAddr)
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+	}
+
+	if t.metricsAddr != "" {
+		server, err := startMetricsServer(t.metricsAddr)
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+	}
+
 	reload := make(chan bool, 1)
 	reload <- true
 	for <-reload {
@@ -144,29 +172,32 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
-		if t.watchConfig != "" {
-			for _, fConfig := range t.configFiles {
-				if _, err := os.Stat(fConfig); err == nil {
-					go t.watchLocalConfig(signals, fConfig)
-				} else {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				}
-			}
-		}
+		t.signals.Store(&signals)
+		watchCtx, watchCancel := context.WithCancel(ctx)
+		t.watchConfigs(watchCtx, signals)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					<-reload
-					reload <- true
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						watchCancel()
+						if t.reloadPlugins(ctx) {
+							watchCtx, watchCancel = context.WithCancel(ctx)
+							t.watchConfigs(watchCtx, signals)
+							continue
+						}
+						<-reload
+						reload <- true
+					}
+					cancel()
+				case err := <-t.pprofErr:
+					log.Printf("E! pprof server failed: %v", err)
+					cancel()
+				case <-stop:
+					cancel()
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
@@ -180,8 +211,89 @@ func (t *Telegraf) reloadLoop() error {
 	return nil
 }
 
-func (t *Telegraf) watchLocalConfig(signals chan os.Signal, fConfig string) {
+// reloadPlugins applies the changes of the configuration to the running agent
+// by only restarting the plugins that changed. It returns false if the agent
+// needs to be restarted completely.
+func (t *Telegraf) reloadPlugins(ctx context.Context) bool {
+	ag := t.running.Load()
+	if ag == nil {
+		return false
+	}
+
+	c, err := t.loadConfiguration()
+	if err != nil {
+		log.Printf("E! Loading config failed: %v", err)
+		return false
+	}
+	if len(c.Outputs) == 0 || (t.plugindDir == "" && len(c.Inputs) == 0) {
+		return false
+	}
+
+	diff := config.Compare(ag.Config, c)
+	if diff.RestartReason != "" {
+		log.Printf("I! Restarting agent as %s", diff.RestartReason)
+		return false
+	}
+	if !diff.Changed() {
+		log.Println("I! Config unchanged")
+		return true
+	}
+
+	if err := ag.Reload(ctx, diff); err != nil {
+		log.Printf("E! Reloading plugins failed: %v; restarting agent", err)
+		return false
+	}
+
+	log.Printf("I! Loaded inputs: %s", strings.Join(ag.Config.InputNames(), " "))
+	log.Printf("I! Loaded aggregators: %s", strings.Join(ag.Config.AggregatorNames(), " "))
+	log.Printf("I! Loaded processors: %s", strings.Join(ag.Config.ProcessorNames(), " "))
+	log.Printf("I! Loaded outputs: %s", strings.Join(ag.Config.OutputNames(), " "))
+	return true
+}
+
+func (t *Telegraf) watchConfigs(ctx context.Context, signals chan os.Signal) {
+	remote := make(map[string]bool)
+	if c := t.loaded.Load(); c != nil {
+		for _, source := range c.RemoteSources {
+			remote[source.URL] = true
+		}
+		if interval := c.RemoteWatchInterval(); interval > 0 && len(c.RemoteSources) > 0 {
+			go t.watchRemoteConfigs(ctx, signals, c.RemoteSources, interval)
+		}
+	}
+
+	if t.watchConfig == "" {
+		return
+	}
+	for _, fConfig := range t.configFiles {
+		if remote[fConfig] {
+			continue
+		}
+		if _, err := os.Stat(fConfig); err == nil {
+			go t.watchLocalConfig(ctx, signals, fConfig)
+		} else {
+			log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+		}
+	}
+	for _, fConfigDirectory := range t.configDir {
+		if _, err := os.Stat(fConfigDirectory); err == nil {
+			go t.watchConfigDirectory(ctx, signals, fConfigDirectory)
+		} else {
+			log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
+		}
+	}
+}
+
+func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal, fConfig string) {
 	var mytomb tomb.Tomb
+	go func() {
+		select {
+		case <-ctx.Done():
+			mytomb.Kill(nil)
+		case <-mytomb.Dead():
+		}
+	}()
+
 	var watcher watch.FileWatcher
 	if t.watchConfig == "poll" {
 		watcher = watch.NewPollingFileWatcher(fConfig)
@@ -220,6 +332,38 @@ func (t *Telegraf) watchLocalConfig(signals chan os.Signal, fConfig string) {
 	signals <- syscall.SIGHUP
 }
 
+// watchRemoteConfigs polls the configurations loaded from URLs and triggers
+// a reload if any of them changed.
+func (*Telegraf) watchRemoteConfigs(ctx context.Context, signals chan os.Signal, sources []*config.RemoteSource, interval time.Duration) {
+	log.Printf("I! Remote config watcher started with interval %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("I! Remote config watcher ended")
+			return
+		case <-ticker.C:
+		}
+
+		for _, source := range sources {
+			changed, err := source.Changed(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("W! Checking remote config %s for changes failed: %v", source.URL, err)
+				}
+				continue
+			}
+			if changed {
+				log.Printf("I! Remote config %s changed", source.URL)
+				signals <- syscall.SIGHUP
+				return
+			}
+		}
+	}
+}
+
 func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	// If no other options are specified, load the config file and run.
 	c := config.NewConfig()
@@ -252,6 +396,7 @@ func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	if err := c.LoadAll(configFiles...); err != nil {
 		return c, err
 	}
+	t.loaded.Store(c)
 	return c, nil
 }
 
@@ -284,7 +429,9 @@ func (t *Telegraf) runAgent(ctx context.Context, c *config.Config, reloadConfig
 		Debug:               telegraf.Debug,
 		Quiet:               c.Agent.Quiet || t.quiet,
 		LogTarget:           c.Agent.LogTarget,
+		LogFormat:           c.Agent.LogFormat,
 		Logfile:             c.Agent.Logfile,
+		SyslogAddress:       c.Agent.SyslogAddress,
 		RotationInterval:    c.Agent.LogfileRotationInterval,
 		RotationMaxSize:     c.Agent.LogfileRotationMaxSize,
 		RotationMaxArchives: c.Agent.LogfileRotationMaxArchives,
@@ -383,5 +530,8 @@ func (t *Telegraf) runAgent(ctx context.Context, c *config.Config, reloadConfig
 		}
 	}
 
+	t.running.Store(ag)
+	defer t.running.Store(nil)
+
 	return ag.Run(ctx)
 }
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	// Time without further changes to wait for before reloading, so that
+	// deploying multiple files at once only causes a single reload
+	configDirectoryDebounce = 2 * time.Second
+
+	// Interval for checking config directories in poll mode
+	configDirectoryPollInterval = time.Second
+)
+
+// watchConfigDirectory watches the given config directory for config files
+// being added, removed or renamed and triggers a reload. Modifications of
+// existing files are handled by the watchers of the individual files.
+func (t *Telegraf) watchConfigDirectory(ctx context.Context, signals chan os.Signal, dir string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var changes <-chan string
+	if t.watchConfig == "poll" {
+		changes = pollConfigDirectory(ctx, dir, configDirectoryPollInterval)
+	} else {
+		var err error
+		changes, err = notifyConfigDirectory(ctx, dir)
+		if err != nil {
+			log.Printf("E! Error watching config directory %s: %s", dir, err)
+			return
+		}
+	}
+	log.Printf("I! Config directory watcher started for %s", dir)
+
+	if !debounce(ctx, changes, configDirectoryDebounce) {
+		log.Println("I! Config directory watcher ended")
+		return
+	}
+	signals <- syscall.SIGHUP
+}
+
+// debounce waits for changes until no further change occurred for the given
+// delay. It returns false if the context is done before.
+func debounce(ctx context.Context, changes <-chan string, delay time.Duration) bool {
+	var timeout <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case fn := <-changes:
+			log.Printf("I! Config directory changed: %s", fn)
+			timeout = time.After(delay)
+		case <-timeout:
+			return true
+		}
+	}
+}
+
+// notifyConfigDirectory reports config files and directories being created,
+// removed or renamed within the given directory using filesystem
+// notifications.
+func notifyConfigDirectory(ctx context.Context, dir string) (<-chan string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch all subdirectories as those are considered when loading the
+	// config files
+	dirs := make(map[string]bool)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), "..") {
+			return filepath.SkipDir
+		}
+		dirs[path] = true
+		return watcher.Add(path)
+	})
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	changes := make(chan string)
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-watcher.Events:
+				if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+
+				relevant := isConfigFile(event.Name) || dirs[event.Name]
+				if event.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						relevant = true
+						if !strings.HasPrefix(filepath.Base(event.Name), "..") {
+							dirs[event.Name] = true
+							if err := watcher.Add(event.Name); err != nil {
+								log.Printf("W! Cannot watch config directory %s: %s", event.Name, err)
+							}
+						}
+					}
+				}
+				if !relevant {
+					continue
+				}
+
+				select {
+				case changes <- event.Name:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-watcher.Errors:
+				log.Printf("W! Error watching config directory %s: %s", dir, err)
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// pollConfigDirectory reports config files being added to or removed from the
+// given directory by periodically listing its content.
+func pollConfigDirectory(ctx context.Context, dir string, interval time.Duration) <-chan string {
+	changes := make(chan string)
+	go func() {
+		files := listConfigFiles(dir)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current := listConfigFiles(dir)
+			var changed []string
+			for fn := range current {
+				if !files[fn] {
+					changed = append(changed, fn)
+				}
+			}
+			for fn := range files {
+				if !current[fn] {
+					changed = append(changed, fn)
+				}
+			}
+			files = current
+
+			for _, fn := range changed {
+				select {
+				case changes <- fn:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// listConfigFiles returns the config files within the given directory in the
+// same way as config.WalkDirectory but ignoring any errors.
+func listConfigFiles(dir string) map[string]bool {
+	files := make(map[string]bool)
+	//nolint:errcheck // Unreadable entries are skipped and reported when loading the config
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), "..") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isConfigFile(path) {
+			files[path] = true
+		}
+		return nil
+	})
+	return files
+}
+
+func isConfigFile(path string) bool {
+	name := filepath.Base(path)
+	return len(name) > 5 && strings.HasSuffix(name, ".conf")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchConfigDirectory(t *testing.T) {
+	configDirectoryDebounce = 500 * time.Millisecond
+	configDirectoryPollInterval = 50 * time.Millisecond
+
+	for _, mode := range []string{"notify", "poll"} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.conf"), nil, 0o600))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tg := &Telegraf{GlobalFlags: GlobalFlags{watchConfig: mode}}
+			signals := make(chan os.Signal, 10)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				tg.watchConfigDirectory(ctx, signals, dir)
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Files not being config files must not trigger a reload
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o600))
+			time.Sleep(2 * configDirectoryDebounce)
+			require.Empty(t, signals)
+
+			// Deploying multiple files must only trigger a single reload
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.conf"), nil, 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "b.conf"), nil, 0o600))
+			require.NoError(t, os.Rename(filepath.Join(dir, "existing.conf"), filepath.Join(dir, "renamed.conf")))
+
+			select {
+			case sig := <-signals:
+				require.Equal(t, syscall.SIGHUP, sig)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "no reload triggered")
+			}
+			<-done
+			require.Empty(t, signals)
+		})
+	}
+}
+
+func TestWatchConfigDirectoryRemoved(t *testing.T) {
+	configDirectoryDebounce = 100 * time.Millisecond
+	configDirectoryPollInterval = 50 * time.Millisecond
+
+	for _, mode := range []string{"notify", "poll"} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.conf"), nil, 0o600))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tg := &Telegraf{GlobalFlags: GlobalFlags{watchConfig: mode}}
+			signals := make(chan os.Signal, 10)
+			go tg.watchConfigDirectory(ctx, signals, dir)
+			time.Sleep(100 * time.Millisecond)
+
+			require.NoError(t, os.Remove(filepath.Join(dir, "sub", "a.conf")))
+
+			select {
+			case sig := <-signals:
+				require.Equal(t, syscall.SIGHUP, sig)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "no reload triggered")
+			}
+		})
+	}
+}
+
+func TestWatchConfigDirectoryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tg := &Telegraf{GlobalFlags: GlobalFlags{watchConfig: "poll"}}
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tg.watchConfigDirectory(ctx, signals, t.TempDir())
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "watcher did not stop")
+	}
+	require.Empty(t, signals)
+}
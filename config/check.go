@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// CheckIssue is a problem found when checking a configuration.
+type CheckIssue struct {
+	// Source is the configuration file and Line the line of the problem if
+	// known
+	Source string
+	Line   int
+
+	Plugin  string
+	Message string
+
+	// Warning is set for problems not preventing Telegraf from running
+	Warning bool
+}
+
+func (i *CheckIssue) String() string {
+	var location string
+	switch {
+	case i.Source != "" && i.Line > 0:
+		location = fmt.Sprintf("%s:%d: ", i.Source, i.Line)
+	case i.Source != "":
+		location = i.Source + ": "
+	}
+	if i.Plugin != "" {
+		location += "[" + i.Plugin + "] "
+	}
+
+	level := "E!"
+	if i.Warning {
+		level = "W!"
+	}
+	return level + " " + location + i.Message
+}
+
+// Check loads the given configuration files and checks them for problems
+// without running any plugin. In contrast to LoadAll, checking continues
+// after a problem to report as many problems as possible. Secrets are
+// resolved to check their references but are never revealed.
+func (c *Config) Check(configFiles ...string) []CheckIssue {
+	var issues []CheckIssue
+	addError := func(plugin string, err error) {
+		issues = append(issues, CheckIssue{Plugin: plugin, Message: err.Error()})
+	}
+
+	// Only check the secrets of the given files
+	secretsOffset := len(unlinkedSecrets)
+
+	// Loading a file stops at the first problem of the file, e.g. unused
+	// fields, so continue with the next file
+	var loadFailed bool
+	for _, fn := range configFiles {
+		if err := c.LoadConfig(fn); err != nil {
+			addError("", err)
+			loadFailed = true
+		}
+	}
+
+	// Plugins are missing if a file failed to load so do not report missing
+	// references to them
+	if !loadFailed {
+		if err := c.resolvePlugins(); err != nil {
+			addError("", err)
+		}
+	}
+
+	for _, n := range c.DeprecationNotices {
+		issues = append(issues, CheckIssue{
+			Source:  n.Source,
+			Line:    n.Line,
+			Plugin:  n.Plugin,
+			Message: n.String(),
+			Warning: n.LogLevel != telegraf.Error,
+		})
+	}
+
+	// Link all secrets individually and resolve the dynamic ones to report
+	// all invalid references
+	secretsLinked := true
+	for _, s := range unlinkedSecrets[secretsOffset:] {
+		if err := c.linkSecret(s); err != nil {
+			addError("", fmt.Errorf("secret: %w", err))
+			secretsLinked = false
+			continue
+		}
+		if len(s.resolvers) == 0 {
+			continue
+		}
+		buf, err := s.Get()
+		if err != nil {
+			addError("", fmt.Errorf("secret: %w", err))
+			continue
+		}
+		buf.Destroy()
+	}
+
+	// Plugins might use their secrets during initialization
+	if !secretsLinked {
+		return issues
+	}
+
+	// Initialize the plugins directly as the running plugins create
+	// persistent resources like disk buffers when initialized
+	for _, input := range c.Inputs {
+		// Same as snmp.TranslatorPlugin which cannot be imported here
+		if tp, ok := input.Input.(interface{ SetTranslator(name string) }); ok {
+			tp.SetTranslator(c.Agent.SnmpTranslator)
+		}
+		if p, ok := input.Input.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				addError(input.LogName(), fmt.Errorf("initialization failed: %w", err))
+			}
+		}
+	}
+	for _, processor := range c.Processors {
+		if p, ok := processor.Processor.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				addError(processor.LogName(), fmt.Errorf("initialization failed: %w", err))
+			}
+		}
+	}
+	for _, aggregator := range c.Aggregators {
+		if p, ok := aggregator.Aggregator.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				addError(aggregator.LogName(), fmt.Errorf("initialization failed: %w", err))
+			}
+		}
+	}
+	for _, output := range c.Outputs {
+		if p, ok := output.Output.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				addError(output.LogName(), fmt.Errorf("initialization failed: %w", err))
+			}
+		}
+	}
+
+	return issues
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -26,6 +25,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/aggregators"
@@ -72,6 +72,10 @@ type Config struct {
 
 	SecretStores map[string]telegraf.SecretStore
 
+	// secretStoreIDs contains the configuration IDs of the secret-stores
+	// to detect changes when reloading
+	secretStoreIDs map[string]string
+
 	Agent       *AgentConfig
 	Inputs      []*models.RunningInput
 	Outputs     []*models.RunningOutput
@@ -82,12 +86,29 @@ type Config struct {
 	fileProcessors    OrderedPlugins
 	fileAggProcessors OrderedPlugins
 
+	// outputGroups are resolved after loading all configuration files
+	outputGroups []*outputGroup
+
+	// Pipelines are the names of the pipelines in the order of definition
+	// and pipeline is the one currently being parsed
+	Pipelines []string
+	pipeline  string
+
+	// RemoteSources are the configurations loaded from URLs
+	RemoteSources []*RemoteSource
+	remote        *RemoteConfig
+
 	// Parsers are created by their inputs during gather. Config doesn't keep track of them
 	// like the other plugins because they need to be garbage collected (See issue #11809)
 
 	Deprecations map[string][]int64
 	version      *semver.Version
 
+	// DeprecationNotices are the deprecated plugins and options used in the
+	// configuration and source is the file currently being loaded
+	DeprecationNotices []DeprecationNotice
+	source             string
+
 	Persister *persister.Persister
 
 	NumberSecrets uint64
@@ -127,6 +148,7 @@ func NewConfig() *Config {
 		Processors:         make([]*models.RunningProcessor, 0),
 		AggProcessors:      make([]*models.RunningProcessor, 0),
 		SecretStores:       make(map[string]telegraf.SecretStore),
+		secretStoreIDs:     make(map[string]string),
 		fileProcessors:     make([]*OrderedPlugin, 0),
 		fileAggProcessors:  make([]*OrderedPlugin, 0),
 		InputFilters:       make([]string, 0),
@@ -205,6 +227,15 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// BufferStrategy is the type of buffer used by the outputs, either
+	// "memory" (default) keeping the metrics in memory or "disk" persisting
+	// the metrics in a write-ahead log on disk to survive restarts.
+	BufferStrategy string `toml:"buffer_strategy"`
+
+	// BufferDirectory is the directory to store the buffers of outputs using
+	// the "disk" buffer strategy in.
+	BufferDirectory string `toml:"buffer_directory"`
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
@@ -222,14 +253,23 @@ type AgentConfig struct {
 	Quiet bool `toml:"quiet"`
 
 	// Log target controls the destination for logs and can be one of "file",
-	// "stderr" or, on Windows, "eventlog".  When set to "file", the output file
-	// is determined by the "logfile" setting.
+	// "stderr", "syslog" or, on Windows, "eventlog".  When set to "file", the
+	// output file is determined by the "logfile" setting.
 	LogTarget string `toml:"logtarget"`
 
+	// Log format controls the format of the log messages and can be one of
+	// "text" or "json".
+	LogFormat string `toml:"logformat"`
+
 	// Name of the file to be logged to when using the "file" logtarget.  If set to
 	// the empty string then logs are written to stderr.
 	Logfile string `toml:"logfile"`
 
+	// Address of the syslog server when using the "syslog" logtarget, e.g.
+	// "udp://localhost:514".  If set to the empty string then logs are written
+	// to the local syslog socket.
+	SyslogAddress string `toml:"syslog_address"`
+
 	// The file will be rotated after the time interval specified.  When set
 	// to 0 no time based rotation is performed.
 	LogfileRotationInterval Duration `toml:"logfile_rotation_interval"`
@@ -258,6 +298,11 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Interval for periodically storing the state of plugins to the
+	// statefile in addition to storing it on termination of Telegraf.
+	// Zero disables periodic checkpointing.
+	StatefileInterval Duration `toml:"statefile_interval"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -266,6 +311,13 @@ type AgentConfig struct {
 	// and ensure those tags always pass filtering.
 	AlwaysIncludeGlobalTags bool `toml:"always_include_global_tags"`
 
+	// StartupErrorBehavior controls the behavior of inputs and outputs failing
+	// to start or connect. Can be "error" (default) to abort the startup,
+	// "retry" to retry starting the plugin in the background, "ignore" to
+	// remove the plugin and continue or "probe" to additionally remove the
+	// plugin if probing its availability fails after startup.
+	StartupErrorBehavior string `toml:"startup_error_behavior"`
+
 	// Flag to skip running processors after aggregators
 	// By default, processors are run a second time after aggregators. Changing
 	// this setting to true will skip the second run of processors.
@@ -447,8 +499,16 @@ func (c *Config) LoadConfig(path string) error {
 	if !c.Agent.Quiet {
 		log.Printf("I! Loading config: %s", path)
 	}
+	c.source = path
+	defer func() { c.source = "" }()
 
-	data, _, err := LoadConfigFile(path)
+	var data []byte
+	var err error
+	if isRemoteURL(path) {
+		data, err = c.fetchRemoteConfig(path)
+	} else {
+		data, _, err = LoadConfigFile(path)
+	}
 	if err != nil {
 		return fmt.Errorf("error loading config file %s: %w", path, err)
 	}
@@ -467,6 +527,20 @@ func (c *Config) LoadAll(configFiles ...string) error {
 		}
 	}
 
+	if err := c.resolvePlugins(); err != nil {
+		return err
+	}
+
+	// Check if there is enough lockable memory for the secret
+	c.NumberSecrets = uint64(secretCount.Load())
+
+	// Let's link all secrets to their secret-stores
+	return c.LinkSecrets()
+}
+
+// resolvePlugins sorts the plugins and resolves the references between them
+// after loading all configuration files.
+func (c *Config) resolvePlugins() error {
 	// Sort the processors according to their `order` setting while
 	// using a stable sort to keep the file loading / file position order.
 	sort.Stable(c.Processors)
@@ -477,11 +551,15 @@ func (c *Config) LoadAll(configFiles ...string) error {
 		c.Agent.SnmpTranslator = "netsnmp"
 	}
 
-	// Check if there is enough lockable memory for the secret
-	c.NumberSecrets = uint64(secretCount.Load())
+	if err := c.buildOutputGroups(); err != nil {
+		return err
+	}
 
-	// Let's link all secrets to their secret-stores
-	return c.LinkSecrets()
+	if err := c.checkDeadLetters(); err != nil {
+		return err
+	}
+
+	return c.checkPipelines()
 }
 
 // LoadConfigData loads TOML-formatted config data
@@ -557,6 +635,19 @@ func (c *Config) LoadConfigData(data []byte) error {
 
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
+		if name == "pipelines" {
+			pipelines, ok := val.([]*ast.Table)
+			if !ok {
+				return errors.New("invalid configuration, pipelines must be defined as [[pipelines]]")
+			}
+			for _, t := range pipelines {
+				if err := c.addPipeline(t); err != nil {
+					return fmt.Errorf("error parsing pipeline, %w", err)
+				}
+			}
+			continue
+		}
+
 		subTable, ok := val.(*ast.Table)
 		if !ok {
 			return fmt.Errorf("invalid configuration, error parsing field %q as table", name)
@@ -565,28 +656,38 @@ func (c *Config) LoadConfigData(data []byte) error {
 		switch name {
 		case "agent", "global_tags", "tags":
 		case "outputs":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				// legacy [outputs.influxdb] support
-				case *ast.Table:
-					if err = c.addOutput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("error parsing %s, %w", pluginName, err)
-					}
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addOutput(pluginName, t); err != nil {
-							return fmt.Errorf("error parsing %s array, %w", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("unsupported config format: %s",
-						pluginName)
+			if err := c.addOutputs(name, subTable); err != nil {
+				return err
+			}
+		case "remote_config":
+			if c.remote != nil {
+				return errors.New("duplicate remote_config section")
+			}
+			remote := newRemoteConfig()
+			if err := c.toml.UnmarshalTable(subTable, remote); err != nil {
+				return fmt.Errorf("error parsing remote_config, %w", err)
+			}
+			if len(c.UnusedFields) > 0 {
+				return fmt.Errorf(
+					"remote_config: line %d: configuration specified the fields %q, but they were not used. "+
+						"This is either a typo or this config option does not exist in this version.",
+					subTable.Line, keys(c.UnusedFields))
+			}
+			c.remote = remote
+		case "output_groups":
+			for groupName, groupVal := range subTable.Fields {
+				groupTable, ok := groupVal.(*ast.Table)
+				if !ok {
+					return fmt.Errorf("unsupported config format: %s", groupName)
+				}
+				if err = c.addOutputGroup(groupName, groupTable); err != nil {
+					return fmt.Errorf("error parsing output group %s, %w", groupName, err)
 				}
 				if len(c.UnusedFields) > 0 {
 					return fmt.Errorf(
-						"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
+						"output group %s: line %d: configuration specified the fields %q, but they were not used. "+
 							"This is either a typo or this config option does not exist in this version.",
-						name, pluginName, subTable.Line, keys(c.UnusedFields))
+						groupName, groupTable.Line, keys(c.UnusedFields))
 				}
 			}
 		case "inputs", "plugins":
@@ -615,48 +716,12 @@ func (c *Config) LoadConfigData(data []byte) error {
 				}
 			}
 		case "processors":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addProcessor(pluginName, t); err != nil {
-							return fmt.Errorf("error parsing %s, %w", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("unsupported config format: %s",
-						pluginName)
-				}
-				if len(c.UnusedFields) > 0 {
-					return fmt.Errorf(
-						"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
-							"This is either a typo or this config option does not exist in this version.",
-						name,
-						pluginName,
-						subTable.Line,
-						keys(c.UnusedFields),
-					)
-				}
+			if err := c.addProcessors(name, subTable); err != nil {
+				return err
 			}
 		case "aggregators":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addAggregator(pluginName, t); err != nil {
-							return fmt.Errorf("error parsing %s, %w", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("unsupported config format: %s",
-						pluginName)
-				}
-				if len(c.UnusedFields) > 0 {
-					return fmt.Errorf(
-						"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
-							"This is either a typo or this config option does not exist in this version.",
-						name, pluginName, subTable.Line, keys(c.UnusedFields))
-				}
+			if err := c.addAggregators(name, subTable); err != nil {
+				return err
 			}
 		case "secretstores":
 			for pluginName, pluginVal := range subTable.Fields {
@@ -701,6 +766,104 @@ func (c *Config) LoadConfigData(data []byte) error {
 	return nil
 }
 
+// addOutputs adds the outputs of the given "outputs" table.
+func (c *Config) addOutputs(name string, tbl *ast.Table) error {
+	// Add the outputs in the order of their appearance in the configuration
+	// as the order of the fields is random. Output groups replace their
+	// outputs in place, so the order of the outputs must be stable.
+	type outputTable struct {
+		name  string
+		tbl   *ast.Table
+		array bool
+	}
+	tables := make([]outputTable, 0, len(tbl.Fields))
+	for pluginName, pluginVal := range tbl.Fields {
+		switch pluginSubTable := pluginVal.(type) {
+		// legacy [outputs.influxdb] support
+		case *ast.Table:
+			tables = append(tables, outputTable{name: pluginName, tbl: pluginSubTable})
+		case []*ast.Table:
+			for _, t := range pluginSubTable {
+				tables = append(tables, outputTable{name: pluginName, tbl: t, array: true})
+			}
+		default:
+			return fmt.Errorf("unsupported config format: %s",
+				pluginName)
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].tbl.Line < tables[j].tbl.Line
+	})
+
+	for _, t := range tables {
+		if err := c.addOutput(t.name, t.tbl); err != nil {
+			if t.array {
+				return fmt.Errorf("error parsing %s array, %w", t.name, err)
+			}
+			return fmt.Errorf("error parsing %s, %w", t.name, err)
+		}
+		if len(c.UnusedFields) > 0 {
+			return fmt.Errorf(
+				"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
+					"This is either a typo or this config option does not exist in this version.",
+				name, t.name, tbl.Line, keys(c.UnusedFields))
+		}
+	}
+	return nil
+}
+
+// addProcessors adds the processors of the given "processors" table.
+func (c *Config) addProcessors(name string, tbl *ast.Table) error {
+	for pluginName, pluginVal := range tbl.Fields {
+		switch pluginSubTable := pluginVal.(type) {
+		case []*ast.Table:
+			for _, t := range pluginSubTable {
+				if err := c.addProcessor(pluginName, t); err != nil {
+					return fmt.Errorf("error parsing %s, %w", pluginName, err)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported config format: %s",
+				pluginName)
+		}
+		if len(c.UnusedFields) > 0 {
+			return fmt.Errorf(
+				"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
+					"This is either a typo or this config option does not exist in this version.",
+				name,
+				pluginName,
+				tbl.Line,
+				keys(c.UnusedFields),
+			)
+		}
+	}
+	return nil
+}
+
+// addAggregators adds the aggregators of the given "aggregators" table.
+func (c *Config) addAggregators(name string, tbl *ast.Table) error {
+	for pluginName, pluginVal := range tbl.Fields {
+		switch pluginSubTable := pluginVal.(type) {
+		case []*ast.Table:
+			for _, t := range pluginSubTable {
+				if err := c.addAggregator(pluginName, t); err != nil {
+					return fmt.Errorf("error parsing %s, %w", pluginName, err)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported config format: %s",
+				pluginName)
+		}
+		if len(c.UnusedFields) > 0 {
+			return fmt.Errorf(
+				"plugin %s.%s: line %d: configuration specified the fields %q, but they were not used. "+
+					"This is either a typo or this config option does not exist in this version.",
+				name, pluginName, tbl.Line, keys(c.UnusedFields))
+		}
+	}
+	return nil
+}
+
 // trimBOM trims the Byte-Order-Marks from the beginning of the file.
 // this is for Windows compatibility only.
 // see https://github.com/influxdata/telegraf/issues/1378
@@ -720,7 +883,11 @@ func LoadConfigFile(config string) ([]byte, bool, error) {
 
 		switch u.Scheme {
 		case "https", "http":
-			data, err := fetchConfig(u)
+			source, err := newRemoteSource(u.String(), nil)
+			if err != nil {
+				return nil, true, err
+			}
+			data, err := source.fetch()
 			return data, true, err
 		default:
 			return nil, true, fmt.Errorf("scheme %q not supported", u.Scheme)
@@ -741,50 +908,46 @@ func LoadConfigFile(config string) ([]byte, bool, error) {
 	return buffer, false, nil
 }
 
-func fetchConfig(u *url.URL) ([]byte, error) {
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if v, exists := os.LookupEnv("INFLUX_TOKEN"); exists {
-		req.Header.Add("Authorization", "Token "+v)
+// isRemoteURL returns true if the configuration is loaded from a web server.
+func isRemoteURL(config string) bool {
+	if !fetchURLRe.MatchString(config) {
+		return false
 	}
-	req.Header.Add("Accept", "application/toml")
-	req.Header.Set("User-Agent", internal.ProductToken())
+	u, err := url.Parse(config)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
 
-	retries := 3
-	for i := 0; i <= retries; i++ {
-		body, err, retry := func() ([]byte, error, bool) {
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				return nil, fmt.Errorf("retry %d of %d failed connecting to HTTP config server: %w", i, retries, err), false
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode != http.StatusOK {
-				if i < retries {
-					log.Printf("Error getting HTTP config.  Retry %d of %d in %s.  Status=%d", i, retries, httpLoadConfigRetryInterval, resp.StatusCode)
-					return nil, nil, true
-				}
-				return nil, fmt.Errorf("retry %d of %d failed to retrieve remote config: %s", i, retries, resp.Status), false
+// fetchRemoteConfig loads the configuration from the given URL using the
+// remote-config settings loaded so far and keeps track of the source to
+// detect changes.
+func (c *Config) fetchRemoteConfig(address string) ([]byte, error) {
+	if c.remote != nil {
+		for k, v := range c.remote.Headers {
+			if err := c.linkSecret(v); err != nil {
+				return nil, fmt.Errorf("linking secret of header %q failed: %w", k, err)
 			}
-			body, err := io.ReadAll(resp.Body)
-			return body, err, false
-		}()
-
-		if err != nil {
-			return nil, err
-		}
-
-		if retry {
-			time.Sleep(httpLoadConfigRetryInterval)
-			continue
 		}
+	}
 
-		return body, err
+	source, err := newRemoteSource(address, c.remote)
+	if err != nil {
+		return nil, err
 	}
+	data, err := source.fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.RemoteSources = append(c.RemoteSources, source)
+	return data, nil
+}
 
-	return nil, nil
+// RemoteWatchInterval returns the interval for polling the configurations
+// loaded from URLs for changes. Zero disables polling.
+func (c *Config) RemoteWatchInterval() time.Duration {
+	if c.remote == nil {
+		return 0
+	}
+	return time.Duration(c.remote.WatchInterval)
 }
 
 // parseConfig loads a TOML configuration from a provided path and
@@ -825,7 +988,7 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.printUserDeprecation("aggregators", name, aggregator); err != nil {
+	if err := c.printUserDeprecation("aggregators", name, table.Line, aggregator); err != nil {
 		return err
 	}
 
@@ -862,7 +1025,7 @@ func (c *Config) addSecretStore(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.printUserDeprecation("secretstores", name, store); err != nil {
+	if err := c.printUserDeprecation("secretstores", name, table.Line, store); err != nil {
 		return err
 	}
 
@@ -877,29 +1040,49 @@ func (c *Config) addSecretStore(name string, table *ast.Table) error {
 		return fmt.Errorf("duplicate ID %q for secretstore %q", storeid, name)
 	}
 	c.SecretStores[storeid] = store
+
+	id, err := generatePluginID("secretstores."+name, table)
+	if err != nil {
+		return fmt.Errorf("generating ID for secret-store %q failed: %w", storeid, err)
+	}
+	c.secretStoreIDs[storeid] = id
+
 	return nil
 }
 
 func (c *Config) LinkSecrets() error {
 	for _, s := range unlinkedSecrets {
-		resolvers := make(map[string]telegraf.ResolveFunc)
-		for _, ref := range s.GetUnlinked() {
-			// Split the reference and lookup the resolver
-			storeid, key := splitLink(ref)
-			store, found := c.SecretStores[storeid]
-			if !found {
-				return fmt.Errorf("unknown secret-store for %q", ref)
-			}
-			resolver, err := store.GetResolver(key)
-			if err != nil {
-				return fmt.Errorf("retrieving resolver for %q failed: %w", ref, err)
-			}
-			resolvers[ref] = resolver
+		if err := c.linkSecret(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkSecret links the secret to the secret-stores it references. Secrets
+// already linked are skipped.
+func (c *Config) linkSecret(s *Secret) error {
+	if len(s.GetUnlinked()) == 0 {
+		return nil
+	}
+
+	resolvers := make(map[string]telegraf.ResolveFunc)
+	for _, ref := range s.GetUnlinked() {
+		// Split the reference and lookup the resolver
+		storeid, key := splitLink(ref)
+		store, found := c.SecretStores[storeid]
+		if !found {
+			return fmt.Errorf("unknown secret-store for %q", ref)
 		}
-		// Inject the resolver list into the secret
-		if err := s.Link(resolvers); err != nil {
-			return fmt.Errorf("retrieving resolver failed: %w", err)
+		resolver, err := store.GetResolver(key)
+		if err != nil {
+			return fmt.Errorf("retrieving resolver for %q failed: %w", ref, err)
 		}
+		resolvers[ref] = resolver
+	}
+	// Inject the resolver list into the secret
+	if err := s.Link(resolvers); err != nil {
+		return fmt.Errorf("retrieving resolver failed: %w", err)
 	}
 	return nil
 }
@@ -1100,7 +1283,7 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 		return nil, 0, fmt.Errorf("unmarshalling failed: %w", err)
 	}
 
-	err := c.printUserDeprecation("processors", name, processor)
+	err := c.printUserDeprecation("processors", name, table.Line, processor)
 	return streamingProcessor, optionTestCount, err
 }
 
@@ -1133,9 +1316,11 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
+	var serializer *models.RunningSerializer
 	if t, ok := output.(telegraf.SerializerPlugin); ok {
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		var err error
+		serializer, err = c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
@@ -1144,23 +1329,37 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		// Keep the old interface for backward compatibility
 		// DEPRECATED: Please switch your plugin to telegraf.Serializers
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		var err error
+		serializer, err = c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
 		t.SetSerializer(serializer)
 	}
 
-	outputConfig, err := c.buildOutput(name, table)
+	// Outputs defining their own log_level option, like the deprecated option
+	// of outputs.postgresql, do not use the general option
+	generalTable := table
+	if hasTomlOption(output, "log_level") {
+		generalTable = withoutField(table, "log_level")
+	}
+	outputConfig, err := c.buildOutput(name, generalTable)
 	if err != nil {
 		return err
 	}
+	if err := c.setOutputBufferName(outputConfig); err != nil {
+		return err
+	}
+	if outputConfig.RateLimit != nil && serializer != nil {
+		// Determine the size of the metrics in the format written by the output
+		outputConfig.RateLimit.Serializer = serializer.Serializer
+	}
 
 	if err := c.toml.UnmarshalTable(table, output); err != nil {
 		return err
 	}
 
-	if err := c.printUserDeprecation("outputs", name, output); err != nil {
+	if err := c.printUserDeprecation("outputs", name, table.Line, output); err != nil {
 		return err
 	}
 
@@ -1244,7 +1443,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.printUserDeprecation("inputs", name, input); err != nil {
+	if err := c.printUserDeprecation("inputs", name, table.Line, input); err != nil {
 		return err
 	}
 
@@ -1290,6 +1489,7 @@ func (c *Config) buildAggregator(name string, tbl *ast.Table) (*models.Aggregato
 	c.getFieldString(tbl, "name_suffix", &conf.MeasurementSuffix)
 	c.getFieldString(tbl, "name_override", &conf.NameOverride)
 	c.getFieldString(tbl, "alias", &conf.Alias)
+	c.getFieldString(tbl, "log_level", &conf.LogLevel)
 
 	conf.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1304,6 +1504,10 @@ func (c *Config) buildAggregator(name string, tbl *ast.Table) (*models.Aggregato
 		return nil, c.firstErr()
 	}
 
+	if err := models.CheckLogLevel(conf.LogLevel); err != nil {
+		return nil, fmt.Errorf("aggregator %q: %w", name, err)
+	}
+
 	var err error
 	conf.Filter, err = c.buildFilter("aggregators."+name, tbl)
 	if err != nil {
@@ -1311,7 +1515,8 @@ func (c *Config) buildAggregator(name string, tbl *ast.Table) (*models.Aggregato
 	}
 
 	// Generate an ID for the plugin
-	conf.ID, err = generatePluginID("aggregators."+name, tbl)
+	conf.Pipeline = c.pipeline
+	conf.ID, err = generatePluginID(c.pipelinePrefix()+"aggregators."+name, tbl)
 	return conf, err
 }
 
@@ -1323,11 +1528,16 @@ func (c *Config) buildProcessor(category, name string, tbl *ast.Table) (*models.
 
 	c.getFieldInt64(tbl, "order", &conf.Order)
 	c.getFieldString(tbl, "alias", &conf.Alias)
+	c.getFieldString(tbl, "log_level", &conf.LogLevel)
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
 
+	if err := models.CheckLogLevel(conf.LogLevel); err != nil {
+		return nil, fmt.Errorf("processor %q: %w", name, err)
+	}
+
 	var err error
 	conf.Filter, err = c.buildFilter(category+"."+name, tbl)
 	if err != nil {
@@ -1335,7 +1545,8 @@ func (c *Config) buildProcessor(category, name string, tbl *ast.Table) (*models.
 	}
 
 	// Generate an ID for the plugin
-	conf.ID, err = generatePluginID(category+"."+name, tbl)
+	conf.Pipeline = c.pipeline
+	conf.ID, err = generatePluginID(c.pipelinePrefix()+category+"."+name, tbl)
 	return conf, err
 }
 
@@ -1422,6 +1633,7 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 		Name:                    name,
 		AlwaysIncludeLocalTags:  c.Agent.AlwaysIncludeLocalTags,
 		AlwaysIncludeGlobalTags: c.Agent.AlwaysIncludeGlobalTags,
+		StartupErrorBehavior:    c.Agent.StartupErrorBehavior,
 	}
 	c.getFieldDuration(tbl, "interval", &cp.Interval)
 	c.getFieldDuration(tbl, "precision", &cp.Precision)
@@ -1431,6 +1643,9 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	c.getFieldString(tbl, "name_suffix", &cp.MeasurementSuffix)
 	c.getFieldString(tbl, "name_override", &cp.NameOverride)
 	c.getFieldString(tbl, "alias", &cp.Alias)
+	c.getFieldString(tbl, "startup_error_behavior", &cp.StartupErrorBehavior)
+	c.getFieldString(tbl, "log_level", &cp.LogLevel)
+	c.getFieldStringSlice(tbl, "pipelines", &cp.Pipelines)
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1445,6 +1660,14 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 		return nil, c.firstErr()
 	}
 
+	if err := checkStartupErrorBehavior(cp.StartupErrorBehavior); err != nil {
+		return nil, fmt.Errorf("input %q: %w", name, err)
+	}
+
+	if err := models.CheckLogLevel(cp.LogLevel); err != nil {
+		return nil, fmt.Errorf("input %q: %w", name, err)
+	}
+
 	var err error
 	cp.Filter, err = c.buildFilter("inputs."+name, tbl)
 	if err != nil {
@@ -1456,6 +1679,36 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	return cp, err
 }
 
+// setOutputBufferName assigns the name of the "disk" buffer directory to the
+// output. The name must not depend on the plugin's settings as changing the
+// configuration would otherwise orphan the buffered metrics. Outputs without
+// alias are numbered in order of their appearance.
+func (c *Config) setOutputBufferName(oc *models.OutputConfig) error {
+	if oc.BufferStrategy != "disk" {
+		return nil
+	}
+
+	if oc.Alias != "" {
+		oc.BufferName = oc.Name + "-" + url.PathEscape(oc.Alias)
+	} else {
+		var index int
+		for _, output := range c.Outputs {
+			if output.Config.Name == oc.Name && output.Config.Alias == "" {
+				index++
+			}
+		}
+		oc.BufferName = oc.Name + "-" + strconv.Itoa(index)
+	}
+
+	path := filepath.Join(oc.BufferDirectory, oc.BufferName)
+	for _, output := range c.Outputs {
+		if output.BufferPath() == path {
+			return fmt.Errorf("buffer directory %q of output %q already used by another output, use unique aliases", path, oc.Name)
+		}
+	}
+	return nil
+}
+
 // buildOutput parses output specific items from the ast.Table,
 // builds the filter and returns a
 // models.OutputConfig to be inserted into models.RunningInput
@@ -1466,8 +1719,12 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 		return nil, err
 	}
 	oc := &models.OutputConfig{
-		Name:   name,
-		Filter: filter,
+		Name:            name,
+		Filter:          filter,
+		BufferStrategy:  c.Agent.BufferStrategy,
+		BufferDirectory: c.Agent.BufferDirectory,
+
+		StartupErrorBehavior: c.Agent.StartupErrorBehavior,
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1477,7 +1734,11 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 
 	c.getFieldInt(tbl, "metric_buffer_limit", &oc.MetricBufferLimit)
 	c.getFieldInt(tbl, "metric_batch_size", &oc.MetricBatchSize)
+	c.getFieldString(tbl, "buffer_strategy", &oc.BufferStrategy)
+	c.getFieldString(tbl, "buffer_directory", &oc.BufferDirectory)
+	c.getFieldString(tbl, "startup_error_behavior", &oc.StartupErrorBehavior)
 	c.getFieldString(tbl, "alias", &oc.Alias)
+	c.getFieldString(tbl, "log_level", &oc.LogLevel)
 	c.getFieldString(tbl, "name_override", &oc.NameOverride)
 	c.getFieldString(tbl, "name_suffix", &oc.NameSuffix)
 	c.getFieldString(tbl, "name_prefix", &oc.NamePrefix)
@@ -1486,25 +1747,215 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 		return nil, c.firstErr()
 	}
 
+	switch oc.BufferStrategy {
+	case "", "memory":
+	case "disk":
+		if oc.BufferDirectory == "" {
+			return nil, fmt.Errorf("no buffer_directory specified for %q buffer strategy of output %q", oc.BufferStrategy, name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid buffer_strategy %q for output %q", oc.BufferStrategy, name)
+	}
+
+	if err := checkStartupErrorBehavior(oc.StartupErrorBehavior); err != nil {
+		return nil, fmt.Errorf("output %q: %w", name, err)
+	}
+
+	if err := models.CheckLogLevel(oc.LogLevel); err != nil {
+		return nil, fmt.Errorf("output %q: %w", name, err)
+	}
+
+	if node, found := tbl.Fields["dead_letter"]; found {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid 'dead_letter' setting for output %q, expected a table", name)
+		}
+		oc.DeadLetter, err = c.buildDeadLetter(name, subtbl)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", name, err)
+		}
+	}
+
+	if node, found := tbl.Fields["retry"]; found {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid 'retry' setting for output %q, expected a table", name)
+		}
+		oc.Retry, err = c.buildRetry(subtbl)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", name, err)
+		}
+	}
+
+	if node, found := tbl.Fields["rate_limit"]; found {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid 'rate_limit' setting for output %q, expected a table", name)
+		}
+		oc.RateLimit, err = c.buildRateLimit(subtbl)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", name, err)
+		}
+	}
+
 	// Generate an ID for the plugin
-	oc.ID, err = generatePluginID("outputs."+name, tbl)
+	oc.Pipeline = c.pipeline
+	oc.ID, err = generatePluginID(c.pipelinePrefix()+"outputs."+name, tbl)
 	return oc, err
 }
 
+// buildRetry parses the retry policy of an output.
+func (c *Config) buildRetry(tbl *ast.Table) (*models.RetryConfig, error) {
+	rc := &models.RetryConfig{}
+	c.getFieldInt(tbl, "max_attempts", &rc.MaxAttempts)
+	c.getFieldDuration(tbl, "initial_backoff", &rc.InitialBackoff)
+	c.getFieldDuration(tbl, "max_backoff", &rc.MaxBackoff)
+	c.getFieldInt(tbl, "circuit_breaker_threshold", &rc.CircuitBreakerThreshold)
+	c.getFieldDuration(tbl, "circuit_breaker_timeout", &rc.CircuitBreakerTimeout)
+	if c.hasErrs() {
+		return nil, c.firstErr()
+	}
+
+	for key := range tbl.Fields {
+		switch key {
+		case "max_attempts", "initial_backoff", "max_backoff", "circuit_breaker_threshold", "circuit_breaker_timeout":
+		default:
+			return nil, fmt.Errorf("unknown 'retry' setting %q", key)
+		}
+	}
+
+	switch {
+	case rc.MaxAttempts < 0:
+		return nil, errors.New("'max_attempts' of 'retry' cannot be negative")
+	case rc.CircuitBreakerThreshold < 0:
+		return nil, errors.New("'circuit_breaker_threshold' of 'retry' cannot be negative")
+	case rc.InitialBackoff < 0 || rc.MaxBackoff < 0 || rc.CircuitBreakerTimeout < 0:
+		return nil, errors.New("durations of 'retry' cannot be negative")
+	case rc.MaxBackoff > 0 && rc.MaxBackoff < rc.InitialBackoff:
+		return nil, errors.New("'max_backoff' of 'retry' cannot be less than 'initial_backoff'")
+	}
+	return rc, nil
+}
+
+// buildRateLimit parses the rate limit of an output.
+func (c *Config) buildRateLimit(tbl *ast.Table) (*models.RateLimitConfig, error) {
+	rc := &models.RateLimitConfig{}
+	c.getFieldInt(tbl, "metrics_per_second", &rc.MetricsPerSecond)
+	c.getFieldInt64(tbl, "bytes_per_second", &rc.BytesPerSecond)
+	c.getFieldDuration(tbl, "burst", &rc.Burst)
+	if c.hasErrs() {
+		return nil, c.firstErr()
+	}
+
+	for key := range tbl.Fields {
+		switch key {
+		case "metrics_per_second", "bytes_per_second", "burst":
+		default:
+			return nil, fmt.Errorf("unknown 'rate_limit' setting %q", key)
+		}
+	}
+
+	switch {
+	case rc.MetricsPerSecond < 0 || rc.BytesPerSecond < 0:
+		return nil, errors.New("limits of 'rate_limit' cannot be negative")
+	case rc.MetricsPerSecond == 0 && rc.BytesPerSecond == 0:
+		return nil, errors.New("'rate_limit' requires 'metrics_per_second' or 'bytes_per_second'")
+	case rc.Burst < 0:
+		return nil, errors.New("'burst' of 'rate_limit' cannot be negative")
+	}
+	return rc, nil
+}
+
+// buildDeadLetter parses the dead-letter target of an output.
+func (c *Config) buildDeadLetter(name string, tbl *ast.Table) (*models.DeadLetterConfig, error) {
+	dl := &models.DeadLetterConfig{}
+	c.getFieldString(tbl, "output", &dl.Output)
+	c.getFieldString(tbl, "file", &dl.File)
+	if c.hasErrs() {
+		return nil, c.firstErr()
+	}
+
+	switch {
+	case dl.Output != "" && dl.File != "":
+		return nil, errors.New("only one of 'output' or 'file' can be set for 'dead_letter'")
+	case dl.Output != "":
+		return dl, nil
+	case dl.File != "":
+		// Only pass the serializer options without the target options
+		stbl := *tbl
+		stbl.Fields = make(map[string]interface{}, len(tbl.Fields))
+		for k, v := range tbl.Fields {
+			if k != "file" && k != "output" {
+				stbl.Fields[k] = v
+			}
+		}
+		serializer, err := c.addSerializer(name+".dead_letter", &stbl)
+		if err != nil {
+			return nil, fmt.Errorf("creating dead-letter serializer failed: %w", err)
+		}
+		dl.Serializer = serializer
+		return dl, nil
+	}
+	return nil, errors.New("either 'output' or 'file' must be set for 'dead_letter'")
+}
+
+// checkDeadLetters ensures that the dead-letter outputs exist.
+func (c *Config) checkDeadLetters() error {
+	for _, output := range c.Outputs {
+		if output.Config.DeadLetter == nil || output.Config.DeadLetter.Output == "" {
+			continue
+		}
+		target := output.Config.DeadLetter.Output
+
+		var found bool
+		for _, candidate := range c.Outputs {
+			// Metrics cannot leave the pipeline of the output
+			if candidate.Config.Pipeline != output.Config.Pipeline {
+				continue
+			}
+			if candidate.Config.Alias == target || candidate.ID() == target {
+				if candidate == output {
+					return fmt.Errorf("output %s cannot be its own dead-letter output", output.LogName())
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("dead-letter output %q of %s not found", target, output.LogName())
+		}
+	}
+	return nil
+}
+
+func checkStartupErrorBehavior(behavior string) error {
+	if behavior == "" {
+		return nil
+	}
+	if err := choice.Check(behavior, models.StartupErrorBehaviors); err != nil {
+		return fmt.Errorf("invalid 'startup_error_behavior' setting: %w", err)
+	}
+	return nil
+}
+
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
+		"buffer_directory", "buffer_strategy",
 		"collection_jitter", "collection_offset",
-		"data_format", "delay", "drop", "drop_original",
+		"data_format", "dead_letter", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
+		"log_level",
 		"lvm", // What is this used for?
 		"metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
+		"pass", "period", "pipelines", "precision",
+		"rate_limit", "retry",
+		"startup_error_behavior",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags":
 
 	// Secret-store options to ignore
@@ -1555,6 +2006,37 @@ func (c *Config) resetMissingTomlFieldTracker() {
 	c.toml.MissingField = c.missingTomlField
 }
 
+// hasTomlOption returns true if the plugin defines an option with the given
+// key itself.
+func hasTomlOption(plugin interface{}, key string) bool {
+	t := reflect.TypeOf(plugin)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutField returns a shallow copy of the table without the given field.
+func withoutField(tbl *ast.Table, fieldName string) *ast.Table {
+	cpy := *tbl
+	cpy.Fields = make(map[string]interface{}, len(tbl.Fields))
+	for k, v := range tbl.Fields {
+		if k != fieldName {
+			cpy.Fields[k] = v
+		}
+	}
+	return &cpy
+}
+
 func (c *Config) getFieldString(tbl *ast.Table, fieldName string, target *string) {
 	if node, ok := tbl.Fields[fieldName]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -34,6 +35,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	_ "github.com/influxdata/telegraf/plugins/serializers/all" // Blank import to have all serializers for testing
+	jsonserializer "github.com/influxdata/telegraf/plugins/serializers/json"
 	promserializer "github.com/influxdata/telegraf/plugins/serializers/prometheus"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -515,6 +517,307 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_OutputBufferStrategy(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/buffer_strategy.toml"))
+	require.Len(t, c.Outputs, 3)
+
+	expectedStrategy := []string{"disk", "memory", "disk"}
+	expectedDirectory := []string{"/var/lib/telegraf/buffer", "/var/lib/telegraf/buffer", "/tmp/telegraf"}
+	expectedPath := []string{
+		filepath.Join("/var/lib/telegraf/buffer", "azure_monitor-0"),
+		"",
+		filepath.Join("/tmp/telegraf", "azure_monitor-2"),
+	}
+	for i, plugin := range c.Outputs {
+		require.Equal(t, expectedStrategy[i], plugin.Config.BufferStrategy)
+		require.Equal(t, expectedDirectory[i], plugin.Config.BufferDirectory)
+		require.Equal(t, expectedPath[i], plugin.BufferPath())
+	}
+	require.Empty(t, c.UnusedFields)
+}
+
+func TestConfig_OutputBufferStrategyInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadConfig("./testdata/buffer_strategy_invalid.toml"), `invalid buffer_strategy "cloud"`)
+}
+
+func TestConfig_OutputBufferNameStable(t *testing.T) {
+	// The buffer directory must not change with the output's settings
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = "/var/lib/telegraf/buffer"
+[[outputs.azure_monitor]]
+  alias = "primary"
+  namespace_prefix = "Telegraf/"
+`)))
+	require.Len(t, c.Outputs, 1)
+	path := c.Outputs[0].BufferPath()
+
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = "/var/lib/telegraf/buffer"
+[[outputs.azure_monitor]]
+  alias = "primary"
+  namespace_prefix = "Custom/"
+`)))
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, path, c.Outputs[0].BufferPath())
+	require.Equal(t, filepath.Join("/var/lib/telegraf/buffer", "azure_monitor-primary"), path)
+}
+
+func TestConfig_OutputBufferDuplicate(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = "/var/lib/telegraf/buffer"
+[[outputs.azure_monitor]]
+  alias = "primary"
+[[outputs.azure_monitor]]
+  alias = "primary"
+`))
+	require.ErrorContains(t, err, "already used by another output")
+}
+
+func TestConfig_OutputDeadLetter(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/dead_letter.toml"))
+	require.Len(t, c.Outputs, 2)
+
+	require.NotNil(t, c.Outputs[0].Config.DeadLetter)
+	require.Equal(t, "backup", c.Outputs[0].Config.DeadLetter.Output)
+	require.Nil(t, c.Outputs[0].Config.DeadLetter.Serializer)
+
+	require.NotNil(t, c.Outputs[1].Config.DeadLetter)
+	require.Equal(t, "/var/lib/telegraf/dead_letter.out", c.Outputs[1].Config.DeadLetter.File)
+	require.IsType(t, &models.RunningSerializer{}, c.Outputs[1].Config.DeadLetter.Serializer)
+	require.Empty(t, c.UnusedFields)
+}
+
+func TestConfig_OutputDeadLetterInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/dead_letter_invalid.toml"), "only one of 'output' or 'file' can be set")
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/dead_letter_unknown.toml"), `dead-letter output "backup" of outputs.azure_monitor not found`)
+}
+
+func TestConfig_OutputRetry(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/retry.toml"))
+	require.Len(t, c.Outputs, 2)
+
+	require.Nil(t, c.Outputs[0].Config.Retry)
+	expected := &models.RetryConfig{
+		MaxAttempts:             5,
+		InitialBackoff:          time.Second,
+		MaxBackoff:              time.Minute,
+		CircuitBreakerThreshold: 10,
+		CircuitBreakerTimeout:   5 * time.Minute,
+	}
+	require.Equal(t, expected, c.Outputs[1].Config.Retry)
+	require.Empty(t, c.UnusedFields)
+}
+
+func TestConfig_OutputRetryInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadConfig("./testdata/retry_invalid.toml"), "'max_backoff' of 'retry' cannot be less than 'initial_backoff'")
+}
+
+func TestConfig_OutputRateLimit(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/rate_limit.toml"))
+	require.Len(t, c.Outputs, 3)
+	require.Empty(t, c.UnusedFields)
+
+	outputs := make(map[string]*models.RunningOutput, len(c.Outputs))
+	for _, output := range c.Outputs {
+		key := output.Config.Alias
+		if key == "" {
+			key = output.Config.Name
+		}
+		outputs[key] = output
+	}
+	require.Len(t, outputs, 3)
+
+	require.Nil(t, outputs["unlimited"].Config.RateLimit)
+	expected := &models.RateLimitConfig{
+		MetricsPerSecond: 100,
+		BytesPerSecond:   10000,
+		Burst:            time.Minute,
+	}
+	require.Equal(t, expected, outputs["limited"].Config.RateLimit)
+
+	// The size of the metrics is determined by the serializer of the output
+	serialized := outputs["serializer_test_new"]
+	require.NotNil(t, serialized)
+	require.Equal(t, int64(10000), serialized.Config.RateLimit.BytesPerSecond)
+	require.IsType(t, &jsonserializer.Serializer{}, serialized.Config.RateLimit.Serializer)
+}
+
+func TestConfig_OutputRateLimitInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadConfig("./testdata/rate_limit_invalid.toml"), "'rate_limit' requires 'metrics_per_second' or 'bytes_per_second'")
+}
+
+func TestConfig_OutputGroups(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/output_groups.toml"))
+	require.Empty(t, c.UnusedFields)
+	require.Len(t, c.Outputs, 2)
+
+	// The group replaces its first output
+	require.Equal(t, "group", c.Outputs[0].Config.Name)
+	require.Equal(t, "azure", c.Outputs[0].Config.Alias)
+	require.Equal(t, 100, c.Outputs[0].MetricBatchSize)
+	require.Equal(t, "standalone", c.Outputs[1].Config.Alias)
+
+	group, ok := c.Outputs[0].Output.(*models.OutputGroup)
+	require.True(t, ok)
+	require.Equal(t, models.GroupStrategyFailover, group.Config.Strategy)
+	require.Equal(t, 5*time.Minute, group.Config.FailoverAfter)
+	require.Len(t, group.Outputs, 2)
+	require.Equal(t, "primary", group.Outputs[0].Config.Alias)
+	require.Equal(t, "secondary", group.Outputs[1].Config.Alias)
+}
+
+func TestConfig_OutputGroupsInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/output_groups_invalid.toml"), "number of 'weights' must match the number of 'outputs'")
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/output_groups_unknown.toml"), `output "secondary" of output group "azure" not found`)
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/output_groups_member.toml"), "metric_batch_size is not supported for outputs of a group")
+}
+
+func TestConfig_Pipelines(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/pipelines.toml"))
+	require.Empty(t, c.UnusedFields)
+	require.Equal(t, []string{"audit"}, c.Pipelines)
+
+	require.Len(t, c.Inputs, 2)
+	require.Empty(t, c.Inputs[0].Config.Pipelines)
+	require.Equal(t, []string{"audit", "default"}, c.Inputs[1].Config.Pipelines)
+	require.Equal(t, []string{"audit", ""}, c.Inputs[1].Pipelines())
+
+	// Identical plugins in different pipelines must have different IDs
+	require.Len(t, c.Processors, 2)
+	require.Empty(t, c.Processors[0].Config.Pipeline)
+	require.Equal(t, "audit", c.Processors[1].Config.Pipeline)
+	require.NotEqual(t, c.Processors[0].ID(), c.Processors[1].ID())
+
+	// The order of outputs from different tables is not deterministic
+	require.Len(t, c.Outputs, 2)
+	pipelines := make(map[string]string, len(c.Outputs))
+	for _, output := range c.Outputs {
+		pipelines[output.Config.Alias] = output.Config.Pipeline
+	}
+	require.Equal(t, map[string]string{"default": "", "audit": "audit"}, pipelines)
+}
+
+func TestConfig_PipelinesInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/pipelines_unknown.toml"), `pipeline "audit" of inputs.memcached not found`)
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[pipelines]]\n  name = \"default\"\n")), `pipeline name "default" is reserved`)
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[pipelines]]\n  name = \"a\"\n[[pipelines]]\n  name = \"a\"\n")), `duplicate pipeline "a"`)
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[pipelines]]\n  name = \"a\"\n  [[pipelines.inputs.memcached]]\n")), `invalid setting "inputs" for pipeline "a"`)
+}
+
+func TestConfig_Check(t *testing.T) {
+	c := config.NewConfig()
+	issues := c.Check("./testdata/check.toml", "./testdata/check_invalid.toml")
+
+	expected := []config.CheckIssue{
+		{
+			Message: "error loading config file ./testdata/check_invalid.toml: plugin inputs.check: line 1: " +
+				`configuration specified the fields ["timeout"], but they were not used. ` +
+				"This is either a typo or this config option does not exist in this version.",
+		},
+		{
+			Source:  "./testdata/check.toml",
+			Line:    1,
+			Plugin:  "inputs.check",
+			Message: `Option "server" of plugin "inputs.check" deprecated since version 0.0.0 and will be removed in 1.0.0: use 'servers' instead`,
+			Warning: true,
+		},
+	}
+	for _, issue := range expected {
+		require.Contains(t, issues, issue)
+	}
+	require.Len(t, c.DeprecationNotices, 1)
+	require.Equal(t, "W! ./testdata/check.toml:1: [inputs.check] "+expected[1].Message, expected[1].String())
+}
+
+func TestConfig_CheckInitialization(t *testing.T) {
+	c := config.NewConfig()
+	issues := c.Check("./testdata/check_init.toml")
+	require.Equal(t, []config.CheckIssue{
+		{
+			Plugin:  "inputs.check",
+			Message: "initialization failed: no servers configured",
+		},
+	}, issues)
+}
+
+func TestConfig_LogLevel(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  log_level = "debug"
+
+[[processors.processor]]
+  log_level = "error"
+
+[[outputs.http]]
+  log_level = "none"
+`)))
+	require.Empty(t, c.UnusedFields)
+	require.Equal(t, "debug", c.Inputs[0].Config.LogLevel)
+	require.Equal(t, "error", c.Processors[0].Config.LogLevel)
+	require.Equal(t, "none", c.Outputs[0].Config.LogLevel)
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[inputs.memcached]]\n  log_level = \"verbose\"\n")),
+		`input "memcached": invalid log_level "verbose"`)
+
+	// Plugins with their own log_level option do not use the general option
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte("[[outputs.log_level_test]]\n  log_level = \"none\"\n")))
+	require.Empty(t, c.UnusedFields)
+	require.Empty(t, c.Outputs[0].Config.LogLevel)
+	output, ok := c.Outputs[0].Output.(*MockupOutputPluginLogLevel)
+	require.True(t, ok)
+	require.Equal(t, "none", output.LogLevel)
+}
+
+func TestConfig_StartupErrorBehavior(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/startup_error_behavior.toml"))
+	require.Len(t, c.Outputs, 2)
+	require.Equal(t, "retry", c.Outputs[0].Config.StartupErrorBehavior)
+	require.Equal(t, "ignore", c.Outputs[1].Config.StartupErrorBehavior)
+	require.Empty(t, c.UnusedFields)
+}
+
+func TestConfig_StartupErrorBehaviorInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadConfig("./testdata/startup_error_behavior_invalid.toml"), "invalid 'startup_error_behavior' setting")
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -1473,6 +1776,24 @@ func (m *MockupOuputPlugin) Write(_ []telegraf.Metric) error {
 	return nil
 }
 
+/*** Mockup OUTPUT plugin with its own log-level option ***/
+type MockupOutputPluginLogLevel struct {
+	LogLevel string `toml:"log_level"`
+}
+
+func (*MockupOutputPluginLogLevel) Connect() error {
+	return nil
+}
+func (*MockupOutputPluginLogLevel) Close() error {
+	return nil
+}
+func (*MockupOutputPluginLogLevel) SampleConfig() string {
+	return "Mockup test output plugin"
+}
+func (*MockupOutputPluginLogLevel) Write(_ []telegraf.Metric) error {
+	return nil
+}
+
 /*** Mockup OUTPUT plugin for serializer testing to avoid cyclic dependencies ***/
 type MockupOutputPluginSerializerOld struct {
 	Serializer serializers.Serializer
@@ -1572,6 +1893,28 @@ func (m *MockupStatePlugin) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
 
+/*** Mockup INPUT plugin for configuration checks ***/
+type MockupCheckPlugin struct {
+	Servers []string `toml:"servers"`
+	Server  string   `toml:"server" deprecated:"0.0.0;use 'servers' instead"`
+	Fail    bool     `toml:"fail"`
+}
+
+func (m *MockupCheckPlugin) Init() error {
+	if m.Fail {
+		return errors.New("no servers configured")
+	}
+	return nil
+}
+
+func (*MockupCheckPlugin) SampleConfig() string {
+	return "Mockup test plugin"
+}
+
+func (*MockupCheckPlugin) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
 // Register the mockup plugin on loading
 func init() {
 	// Register the mockup input plugin for the required names
@@ -1602,6 +1945,9 @@ func init() {
 	inputs.Add("statetest", func() telegraf.Input {
 		return &MockupStatePlugin{}
 	})
+	inputs.Add("check", func() telegraf.Input {
+		return &MockupCheckPlugin{}
+	})
 
 	// Register the mockup processor plugin for the required names
 	processors.Add("parser_test", func() telegraf.Processor {
@@ -1627,6 +1973,9 @@ func init() {
 	outputs.Add("http", func() telegraf.Output {
 		return &MockupOuputPlugin{}
 	})
+	outputs.Add("log_level_test", func() telegraf.Output {
+		return &MockupOutputPluginLogLevel{}
+	})
 	outputs.Add("serializer_test_new", func() telegraf.Output {
 		return &MockupOutputPluginSerializerNew{}
 	})
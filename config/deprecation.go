@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -167,9 +168,58 @@ func (c *Config) collectDeprecationInfo(category, name string, plugin interface{
 	return info
 }
 
-func (c *Config) printUserDeprecation(category, name string, plugin interface{}) error {
+// DeprecationNotice describes a deprecated plugin or option used in the
+// configuration.
+type DeprecationNotice struct {
+	// Source is the configuration file and Line the line of the plugin
+	Source string
+	Line   int
+
+	Plugin   string
+	Option   string
+	LogLevel telegraf.Escalation
+
+	Since     string
+	RemovalIn string
+	Notice    string
+}
+
+func (n *DeprecationNotice) String() string {
+	if n.Option == "" {
+		return fmt.Sprintf("Plugin %q deprecated since version %s and will be removed in %s: %s",
+			n.Plugin, n.Since, n.RemovalIn, n.Notice)
+	}
+	return fmt.Sprintf("Option %q of plugin %q deprecated since version %s and will be removed in %s: %s",
+		n.Option, n.Plugin, n.Since, n.RemovalIn, n.Notice)
+}
+
+// addDeprecationNotice records the deprecation of the plugin or one of its
+// options. Plugins set up multiple times, like processors, are only recorded
+// once.
+func (c *Config) addDeprecationNotice(line int, plugin, option string, di *DeprecationInfo) {
+	if di.LogLevel == telegraf.None {
+		return
+	}
+
+	notice := DeprecationNotice{
+		Source:    c.source,
+		Line:      line,
+		Plugin:    plugin,
+		Option:    option,
+		LogLevel:  di.LogLevel,
+		Since:     di.info.Since,
+		RemovalIn: di.info.RemovalIn,
+		Notice:    di.info.Notice,
+	}
+	if !slices.Contains(c.DeprecationNotices, notice) {
+		c.DeprecationNotices = append(c.DeprecationNotices, notice)
+	}
+}
+
+func (c *Config) printUserDeprecation(category, name string, line int, plugin interface{}) error {
 	info := c.collectDeprecationInfo(category, name, plugin, false)
 	models.PrintPluginDeprecationNotice(info.LogLevel, info.Name, info.info)
+	c.addDeprecationNotice(line, info.Name, "", &info.DeprecationInfo)
 
 	if info.LogLevel == telegraf.Error {
 		return errors.New("plugin deprecated")
@@ -179,6 +229,7 @@ func (c *Config) printUserDeprecation(category, name string, plugin interface{})
 	deprecatedOptions := make([]string, 0)
 	for _, option := range info.Options {
 		models.PrintOptionDeprecationNotice(option.LogLevel, info.Name, option.Name, option.info)
+		c.addDeprecationNotice(line, info.Name, option.Name, &option)
 		if option.LogLevel == telegraf.Error {
 			deprecatedOptions = append(deprecatedOptions, option.Name)
 		}
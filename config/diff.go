@@ -0,0 +1,128 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// PluginChanges holds the differences for one type of plugins between two
+// configurations.
+type PluginChanges[T any] struct {
+	// Plugins is the resulting list of plugins in the order of the new
+	// configuration. Unchanged plugins are taken from the old configuration
+	// to keep the running instances.
+	Plugins []T
+
+	// Added contains the plugins of the new configuration to be started.
+	Added []T
+
+	// Removed contains the plugins of the old configuration to be stopped.
+	Removed []T
+
+	// Reordered is set if the order of the plugins changed.
+	Reordered bool
+}
+
+// Changed returns true if any plugin was added, removed or reordered.
+func (p *PluginChanges[T]) Changed() bool {
+	return len(p.Added) > 0 || len(p.Removed) > 0 || p.Reordered
+}
+
+// Diff describes the changes between two configurations.
+type Diff struct {
+	// RestartReason is set if the changes cannot be applied by restarting
+	// individual plugins but require a full restart of the agent.
+	RestartReason string
+
+	Inputs        PluginChanges[*models.RunningInput]
+	Processors    PluginChanges[*models.RunningProcessor]
+	Aggregators   PluginChanges[*models.RunningAggregator]
+	AggProcessors PluginChanges[*models.RunningProcessor]
+	Outputs       PluginChanges[*models.RunningOutput]
+}
+
+// Changed returns true if the configurations differ in any way.
+func (d *Diff) Changed() bool {
+	return d.RestartReason != "" ||
+		d.Inputs.Changed() ||
+		d.Processors.Changed() ||
+		d.Aggregators.Changed() ||
+		d.AggProcessors.Changed() ||
+		d.Outputs.Changed()
+}
+
+// Compare determines the differences between the old and the new
+// configuration. Plugins are matched by their configuration ID, so every
+// change to the settings of a plugin results in the old instance being removed
+// and the new one being added.
+func Compare(oldCfg, newCfg *Config) *Diff {
+	d := &Diff{}
+
+	switch {
+	case *oldCfg.Agent != *newCfg.Agent:
+		d.RestartReason = "agent settings changed"
+	case !maps.Equal(oldCfg.Tags, newCfg.Tags):
+		d.RestartReason = "global tags changed"
+	case !maps.Equal(oldCfg.secretStoreIDs, newCfg.secretStoreIDs):
+		d.RestartReason = "secret-stores changed"
+	case !slices.Equal(oldCfg.Pipelines, newCfg.Pipelines):
+		d.RestartReason = "pipelines changed"
+	}
+
+	d.Inputs = comparePlugins(oldCfg.Inputs, newCfg.Inputs,
+		func(p *models.RunningInput) string { return p.Config.ID },
+	)
+	d.Processors = comparePlugins(oldCfg.Processors, newCfg.Processors,
+		func(p *models.RunningProcessor) string { return p.Config.ID },
+	)
+	d.Aggregators = comparePlugins(oldCfg.Aggregators, newCfg.Aggregators,
+		func(p *models.RunningAggregator) string { return p.Config.ID },
+	)
+	d.AggProcessors = comparePlugins(oldCfg.AggProcessors, newCfg.AggProcessors,
+		func(p *models.RunningProcessor) string { return p.Config.ID },
+	)
+	d.Outputs = comparePlugins(oldCfg.Outputs, newCfg.Outputs,
+		func(p *models.RunningOutput) string { return p.Config.ID },
+	)
+
+	return d
+}
+
+func comparePlugins[T comparable](oldPlugins, newPlugins []T, id func(T) string) PluginChanges[T] {
+	// Plugins with identical configuration share the same ID, so we need to
+	// keep track of all instances per ID.
+	available := make(map[string][]T, len(oldPlugins))
+	for _, p := range oldPlugins {
+		available[id(p)] = append(available[id(p)], p)
+	}
+
+	var changes PluginChanges[T]
+	kept := make(map[T]bool, len(oldPlugins))
+	for _, p := range newPlugins {
+		candidates := available[id(p)]
+		if len(candidates) == 0 {
+			changes.Plugins = append(changes.Plugins, p)
+			changes.Added = append(changes.Added, p)
+			continue
+		}
+		changes.Plugins = append(changes.Plugins, candidates[0])
+		available[id(p)] = candidates[1:]
+		kept[candidates[0]] = true
+	}
+
+	for _, p := range oldPlugins {
+		if !kept[p] {
+			changes.Removed = append(changes.Removed, p)
+		}
+	}
+
+	// Only check the order if the set of plugins is unchanged, otherwise the
+	// order is irrelevant as the plugins have to be restarted anyway.
+	if len(changes.Added) == 0 && len(changes.Removed) == 0 {
+		changes.Reordered = !slices.Equal(oldPlugins, changes.Plugins)
+	}
+
+	return changes
+}
@@ -0,0 +1,185 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func loadConfigData(t *testing.T, data string) *config.Config {
+	t.Helper()
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(data)))
+	return c
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	data := `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[processors.processor]]
+  option = "foo"
+
+[[outputs.http]]
+  url = "http://localhost"
+`
+	oldCfg := loadConfigData(t, data)
+	newCfg := loadConfigData(t, data)
+
+	diff := config.Compare(oldCfg, newCfg)
+	require.False(t, diff.Changed())
+	require.Empty(t, diff.RestartReason)
+
+	// The running instances must be kept
+	require.Equal(t, oldCfg.Inputs, diff.Inputs.Plugins)
+	require.Equal(t, []*models.RunningProcessor(oldCfg.Processors), diff.Processors.Plugins)
+	require.Equal(t, oldCfg.Outputs, diff.Outputs.Plugins)
+}
+
+func TestComparePlugins(t *testing.T) {
+	oldCfg := loadConfigData(t, `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["foo"]
+
+[[outputs.http]]
+  url = "http://localhost"
+
+[[outputs.http]]
+  url = "http://remote"
+`)
+	newCfg := loadConfigData(t, `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["bar"]
+
+[[outputs.http]]
+  url = "http://localhost"
+
+[[outputs.http]]
+  url = "http://other"
+`)
+
+	diff := config.Compare(oldCfg, newCfg)
+	require.True(t, diff.Changed())
+	require.Empty(t, diff.RestartReason)
+
+	// Identical plugins are matched one-by-one. Use a single plugin type as
+	// the loading order of different types is not deterministic.
+	require.Len(t, diff.Inputs.Plugins, 2)
+	require.Same(t, oldCfg.Inputs[0], diff.Inputs.Plugins[0])
+	require.Same(t, newCfg.Inputs[1], diff.Inputs.Plugins[1])
+	require.Equal(t, newCfg.Inputs[1:], diff.Inputs.Added)
+	require.Equal(t, oldCfg.Inputs[1:], diff.Inputs.Removed)
+
+	require.Len(t, diff.Outputs.Plugins, 2)
+	require.Same(t, oldCfg.Outputs[0], diff.Outputs.Plugins[0])
+	require.Same(t, newCfg.Outputs[1], diff.Outputs.Plugins[1])
+	require.Equal(t, newCfg.Outputs[1:], diff.Outputs.Added)
+	require.Equal(t, oldCfg.Outputs[1:], diff.Outputs.Removed)
+
+	require.False(t, diff.Processors.Changed())
+	require.False(t, diff.Aggregators.Changed())
+}
+
+func TestCompareLogLevel(t *testing.T) {
+	oldCfg := loadConfigData(t, `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["remote"]
+`)
+	newCfg := loadConfigData(t, `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["remote"]
+  log_level = "debug"
+`)
+
+	// Only the plugin with the changed log-level is replaced
+	diff := config.Compare(oldCfg, newCfg)
+	require.Empty(t, diff.RestartReason)
+	require.Same(t, oldCfg.Inputs[0], diff.Inputs.Plugins[0])
+	require.Equal(t, newCfg.Inputs[1:], diff.Inputs.Added)
+	require.Equal(t, oldCfg.Inputs[1:], diff.Inputs.Removed)
+	require.Equal(t, "debug", diff.Inputs.Added[0].Config.LogLevel)
+}
+
+func TestCompareReordered(t *testing.T) {
+	oldCfg := loadConfigData(t, `
+[[processors.processor]]
+  option = "foo"
+
+[[processors.processor]]
+  option = "bar"
+`)
+	newCfg := loadConfigData(t, `
+[[processors.processor]]
+  option = "bar"
+
+[[processors.processor]]
+  option = "foo"
+`)
+
+	diff := config.Compare(oldCfg, newCfg)
+	require.True(t, diff.Processors.Changed())
+	require.True(t, diff.Processors.Reordered)
+	require.Empty(t, diff.Processors.Added)
+	require.Empty(t, diff.Processors.Removed)
+	require.Same(t, oldCfg.Processors[1], diff.Processors.Plugins[0])
+	require.Same(t, oldCfg.Processors[0], diff.Processors.Plugins[1])
+}
+
+func TestCompareRequiresRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldData  string
+		newData  string
+		expected string
+	}{
+		{
+			name:     "agent settings",
+			oldData:  "[agent]\n  interval = \"10s\"",
+			newData:  "[agent]\n  interval = \"20s\"",
+			expected: "agent settings changed",
+		},
+		{
+			name:     "global tags",
+			oldData:  "[global_tags]\n  dc = \"us-east-1\"",
+			newData:  "[global_tags]\n  dc = \"us-west-1\"",
+			expected: "global tags changed",
+		},
+		{
+			name:     "pipelines",
+			oldData:  "[[pipelines]]\n  name = \"audit\"",
+			newData:  "[[pipelines]]\n  name = \"archive\"",
+			expected: "pipelines changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCfg := loadConfigData(t, tt.oldData)
+			newCfg := loadConfigData(t, tt.newData)
+
+			diff := config.Compare(oldCfg, newCfg)
+			require.True(t, diff.Changed())
+			require.Equal(t, tt.expected, diff.RestartReason)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -409,3 +410,65 @@ func TestURLRetries3FailsThenPasses(t *testing.T) {
 	require.NoError(t, c.LoadConfig(ts.URL))
 	require.Equal(t, 4, responseCounter)
 }
+
+func TestURLRemoteConfigHeaders(t *testing.T) {
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[remote_config]
+  watch_interval = "1m"
+  headers = {Authorization = "Bearer mytoken"}
+`)))
+	require.NoError(t, c.LoadConfig(ts.URL))
+	require.Equal(t, "Bearer mytoken", auth)
+	require.Len(t, c.RemoteSources, 1)
+	require.Equal(t, time.Minute, c.RemoteWatchInterval())
+}
+
+func TestURLRemoteSourceChanged(t *testing.T) {
+	content := "[agent]\n  debug = true\n"
+	etag := `"v1"`
+	var requests, conditional int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer ts.Close()
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig(ts.URL))
+	require.Len(t, c.RemoteSources, 1)
+	source := c.RemoteSources[0]
+
+	// The server reports the content as unmodified
+	changed, err := source.Changed(context.Background())
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, 1, conditional)
+
+	// A new ETag with identical content must not trigger a reload
+	etag = `"v2"`
+	changed, err = source.Changed(context.Background())
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	// Changed content must be detected
+	etag = `"v3"`
+	content = "[agent]\n  debug = false\n"
+	changed, err = source.Changed(context.Background())
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, 4, requests)
+}
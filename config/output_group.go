@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// outputGroup is the configuration of an output group until the outputs of
+// all configuration files are loaded.
+type outputGroup struct {
+	config       *models.OutputGroupConfig
+	outputConfig *models.OutputConfig
+	outputs      []string
+}
+
+type outputGroupSettings struct {
+	Strategy      string   `toml:"strategy"`
+	Outputs       []string `toml:"outputs"`
+	FailoverAfter Duration `toml:"failover_after"`
+	Weights       []int    `toml:"weights"`
+}
+
+func (c *Config) addOutputGroup(name string, tbl *ast.Table) error {
+	for _, g := range c.outputGroups {
+		if g.config.Name == name {
+			return fmt.Errorf("duplicate output group %q", name)
+		}
+	}
+
+	var settings outputGroupSettings
+	if err := c.toml.UnmarshalTable(tbl, &settings); err != nil {
+		return err
+	}
+
+	if settings.Strategy == "" {
+		settings.Strategy = models.GroupStrategyFailover
+	}
+	switch settings.Strategy {
+	case models.GroupStrategyFailover, models.GroupStrategyRoundRobin:
+		if len(settings.Weights) > 0 {
+			return fmt.Errorf("'weights' cannot be used with strategy %q", settings.Strategy)
+		}
+	case models.GroupStrategyHash:
+		if len(settings.Weights) > 0 && len(settings.Weights) != len(settings.Outputs) {
+			return errors.New("number of 'weights' must match the number of 'outputs'")
+		}
+		for _, w := range settings.Weights {
+			if w <= 0 {
+				return errors.New("'weights' must be positive")
+			}
+		}
+	default:
+		return fmt.Errorf("invalid strategy %q", settings.Strategy)
+	}
+	if len(settings.Outputs) == 0 {
+		return errors.New("no 'outputs' specified")
+	}
+
+	oc, err := c.buildOutput("group", tbl)
+	if err != nil {
+		return err
+	}
+	oc.Alias = name
+	if err := c.setOutputBufferName(oc); err != nil {
+		return err
+	}
+
+	c.outputGroups = append(c.outputGroups, &outputGroup{
+		config: &models.OutputGroupConfig{
+			Name:          name,
+			Strategy:      settings.Strategy,
+			FailoverAfter: time.Duration(settings.FailoverAfter),
+			Weights:       settings.Weights,
+		},
+		outputConfig: oc,
+		outputs:      settings.Outputs,
+	})
+
+	return nil
+}
+
+// buildOutputGroups replaces the outputs belonging to a group by the group.
+// This has to happen after loading all configuration files as the outputs of
+// a group might be spread across files.
+func (c *Config) buildOutputGroups() error {
+	if len(c.outputGroups) == 0 {
+		return nil
+	}
+
+	groups := make(map[*models.RunningOutput]*models.RunningOutput, len(c.Outputs))
+	for _, g := range c.outputGroups {
+		members := make([]*models.RunningOutput, 0, len(g.outputs))
+		ids := []string{g.outputConfig.ID}
+		for _, target := range g.outputs {
+			var member *models.RunningOutput
+			for _, candidate := range c.Outputs {
+				if candidate.Config.Alias == target || candidate.ID() == target {
+					member = candidate
+					break
+				}
+			}
+			if member == nil {
+				// Outputs might be excluded on the command-line
+				if len(c.OutputFilters) > 0 {
+					continue
+				}
+				return fmt.Errorf("output %q of output group %q not found", target, g.config.Name)
+			}
+			if _, found := groups[member]; found {
+				return fmt.Errorf("output %q cannot be part of multiple output groups", target)
+			}
+			if err := c.checkOutputGroupMember(member); err != nil {
+				return fmt.Errorf("output %q of output group %q: %w", target, g.config.Name, err)
+			}
+			if len(members) > 0 && member.Config.Pipeline != members[0].Config.Pipeline {
+				return fmt.Errorf("outputs of output group %q must be in the same pipeline", g.config.Name)
+			}
+			groups[member] = nil
+			members = append(members, member)
+			ids = append(ids, member.ID())
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		// Include the outputs in the ID to detect changes when reloading
+		g.outputConfig.ID = combinePluginIDs(ids...)
+		g.outputConfig.Pipeline = members[0].Config.Pipeline
+
+		group := models.NewOutputGroup(g.config, members)
+		ro := models.NewRunningOutput(group, g.outputConfig, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+		for _, member := range members {
+			groups[member] = ro
+		}
+	}
+
+	// Replace the first output of each group by the group and remove the
+	// others to keep the order of the outputs
+	outputs := make([]*models.RunningOutput, 0, len(c.Outputs))
+	added := make(map[*models.RunningOutput]bool, len(c.outputGroups))
+	for _, output := range c.Outputs {
+		group, found := groups[output]
+		if !found {
+			outputs = append(outputs, output)
+			continue
+		}
+		if !added[group] {
+			outputs = append(outputs, group)
+			added[group] = true
+		}
+	}
+	c.Outputs = outputs
+	c.outputGroups = nil
+
+	return nil
+}
+
+// checkOutputGroupMember rejects settings of an output not applied when the
+// output is part of a group as the group writes to the output directly.
+func (c *Config) checkOutputGroupMember(output *models.RunningOutput) error {
+	oc := output.Config
+	var setting string
+	switch {
+	case oc.MetricBatchSize != 0:
+		setting = "metric_batch_size"
+	case oc.MetricBufferLimit != 0:
+		setting = "metric_buffer_limit"
+	case oc.BufferStrategy != c.Agent.BufferStrategy:
+		setting = "buffer_strategy"
+	case oc.BufferDirectory != c.Agent.BufferDirectory:
+		setting = "buffer_directory"
+	case oc.FlushInterval != 0:
+		setting = "flush_interval"
+	case oc.FlushJitter != 0:
+		setting = "flush_jitter"
+	case oc.Retry != nil:
+		setting = "retry"
+	case oc.RateLimit != nil:
+		setting = "rate_limit"
+	case oc.DeadLetter != nil:
+		setting = "dead_letter"
+	case oc.Filter.IsActive():
+		setting = "metric filtering"
+	case oc.NameOverride != "" || oc.NamePrefix != "" || oc.NameSuffix != "":
+		setting = "name modification"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s is not supported for outputs of a group, set it on the group instead", setting)
+}
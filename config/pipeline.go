@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// addPipeline adds the processors, aggregators and outputs of a pipeline.
+// The plugins are added to the configuration like all other plugins but are
+// marked as belonging to the pipeline.
+func (c *Config) addPipeline(tbl *ast.Table) error {
+	var name string
+	c.getFieldString(tbl, "name", &name)
+	if c.hasErrs() {
+		return c.firstErr()
+	}
+
+	switch {
+	case name == "":
+		return errors.New("pipeline without name")
+	case name == models.DefaultPipeline:
+		return fmt.Errorf("pipeline name %q is reserved", name)
+	case slices.Contains(c.Pipelines, name):
+		return fmt.Errorf("duplicate pipeline %q", name)
+	}
+	c.Pipelines = append(c.Pipelines, name)
+
+	c.pipeline = name
+	defer func() { c.pipeline = "" }()
+
+	for key, val := range tbl.Fields {
+		if key == "name" {
+			continue
+		}
+
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid setting %q for pipeline %q", key, name)
+		}
+
+		category := "pipelines." + name + "." + key
+		switch key {
+		case "processors":
+			if err := c.addProcessors(category, subTable); err != nil {
+				return err
+			}
+		case "aggregators":
+			if err := c.addAggregators(category, subTable); err != nil {
+				return err
+			}
+		case "outputs":
+			if err := c.addOutputs(category, subTable); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid setting %q for pipeline %q", key, name)
+		}
+	}
+
+	return nil
+}
+
+// pipelinePrefix returns the prefix for the IDs of plugins in the pipeline
+// currently being parsed. Including the pipeline in the ID distinguishes
+// identically configured plugins of different pipelines.
+func (c *Config) pipelinePrefix() string {
+	if c.pipeline == "" {
+		return ""
+	}
+	return "pipelines." + c.pipeline + "."
+}
+
+// checkPipelines checks that the pipelines referenced by the inputs exist.
+// This has to happen after loading all configuration files as the pipelines
+// might be defined in other files than the inputs.
+func (c *Config) checkPipelines() error {
+	for _, input := range c.Inputs {
+		for _, name := range input.Config.Pipelines {
+			if name != models.DefaultPipeline && !slices.Contains(c.Pipelines, name) {
+				return fmt.Errorf("pipeline %q of %s not found", name, input.LogName())
+			}
+		}
+	}
+	return nil
+}
@@ -77,3 +77,13 @@ func generatePluginID(prefix string, table *ast.Table) (string, error) {
 
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
+
+// combinePluginIDs generates an ID from the IDs of multiple plugins.
+func combinePluginIDs(ids ...string) string {
+	hash := sha256.New()
+	for _, id := range ids {
+		hash.Write([]byte(id))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
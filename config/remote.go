@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// Default timeout for requests to the configuration server
+const remoteConfigTimeout = 30 * time.Second
+
+// RemoteConfig contains the settings for loading configurations from URLs.
+type RemoteConfig struct {
+	WatchInterval Duration           `toml:"watch_interval"`
+	Retries       int                `toml:"retries"`
+	RetryInterval Duration           `toml:"retry_interval"`
+	Timeout       Duration           `toml:"timeout"`
+	Headers       map[string]*Secret `toml:"headers"`
+	tls.ClientConfig
+}
+
+func newRemoteConfig() *RemoteConfig {
+	return &RemoteConfig{
+		Retries:       3,
+		RetryInterval: Duration(httpLoadConfigRetryInterval),
+		Timeout:       Duration(remoteConfigTimeout),
+	}
+}
+
+// RemoteSource is a configuration loaded from a URL. It keeps track of the
+// loaded version to detect changes on the server.
+type RemoteSource struct {
+	URL string
+
+	config *RemoteConfig
+	client *http.Client
+
+	etag         string
+	lastModified string
+	hash         [sha256.Size]byte
+}
+
+func newRemoteSource(address string, cfg *RemoteConfig) (*RemoteSource, error) {
+	if cfg == nil {
+		cfg = newRemoteConfig()
+	}
+
+	tlsCfg, err := cfg.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating TLS configuration failed: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+
+	return &RemoteSource{
+		URL:    address,
+		config: cfg,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.Timeout),
+		},
+	}, nil
+}
+
+// fetch retrieves the configuration, retrying on failures.
+func (s *RemoteSource) fetch() ([]byte, error) {
+	retries := s.config.Retries
+	interval := time.Duration(s.config.RetryInterval)
+	for i := 0; ; i++ {
+		body, _, err := s.request(context.Background(), false)
+		if err == nil {
+			return body, nil
+		}
+		if i >= retries {
+			return nil, fmt.Errorf("retry %d of %d failed to retrieve remote config: %w", i, retries, err)
+		}
+		log.Printf("W! Error getting HTTP config. Retry %d of %d in %s: %v", i, retries, interval, err)
+		time.Sleep(interval)
+	}
+}
+
+// Changed checks if the configuration on the server differs from the loaded
+// one. The server is asked to only send the content if it was modified since
+// the last request and the content is compared to detect servers without
+// support for conditional requests.
+func (s *RemoteSource) Changed(ctx context.Context) (bool, error) {
+	hash := s.hash
+	body, modified, err := s.request(ctx, true)
+	if err != nil || !modified {
+		return false, err
+	}
+	return sha256.Sum256(body) != hash, nil
+}
+
+// request retrieves the configuration from the server and returns false for
+// modified if the server reported the content as unchanged.
+func (s *RemoteSource) request(ctx context.Context, conditional bool) (body []byte, modified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if v, exists := os.LookupEnv("INFLUX_TOKEN"); exists {
+		req.Header.Add("Authorization", "Token "+v)
+	}
+	req.Header.Add("Accept", "application/toml")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	for k, v := range s.config.Headers {
+		secret, err := v.Get()
+		if err != nil {
+			return nil, false, fmt.Errorf("getting header %q failed: %w", k, err)
+		}
+		req.Header.Set(k, secret.String())
+		secret.Destroy()
+		if k == "Host" || k == "host" {
+			req.Host = req.Header.Get(k)
+		}
+	}
+	if conditional {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("connecting to HTTP config server failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if conditional {
+			return nil, false, nil
+		}
+		fallthrough
+	default:
+		return nil, false, errors.New(resp.Status)
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	s.hash = sha256.Sum256(body)
+
+	return body, true, nil
+}
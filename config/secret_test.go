@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/awnumar/memguard"
@@ -484,6 +486,21 @@ func TestSecretStoreDeclarationValidID(t *testing.T) {
 	}
 }
 
+func TestSecretStoreCheckUnknown(t *testing.T) {
+	defer func() { unlinkedSecrets = make([]*Secret, 0) }()
+
+	cfg := []byte(`
+[[inputs.mockup]]
+  secret = "@{mock:test}"
+`)
+	fn := filepath.Join(t.TempDir(), "telegraf.conf")
+	require.NoError(t, os.WriteFile(fn, cfg, 0600))
+
+	c := NewConfig()
+	issues := c.Check(fn)
+	require.Equal(t, []CheckIssue{{Message: `secret: unknown secret-store for "@{mock:test}"`}}, issues)
+}
+
 type SecretImplTestSuite struct {
 	suite.Suite
 	protected bool
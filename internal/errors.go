@@ -0,0 +1,54 @@
+package internal
+
+import "errors"
+
+// ErrNotConnected is returned by plugins that failed to start or connect and
+// are retried according to their startup-error behavior.
+var ErrNotConnected = errors.New("not connected")
+
+// FatalError indicates a plugin error that cannot be recovered from. The
+// agent removes the plugin from further processing instead of aborting.
+type FatalError struct {
+	Err error
+}
+
+func (e *FatalError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FatalError) Unwrap() error {
+	return e.Err
+}
+
+// RejectError indicates that an output permanently refused to write metrics,
+// e.g. due to invalid data. Retrying the write cannot succeed so the metrics
+// are dropped instead of being kept in the buffer.
+type RejectError struct {
+	Err error
+}
+
+func (e *RejectError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+// PartialWriteError indicates that an output only wrote part of a batch. The
+// metrics at the indices in MetricsAccept were written successfully and the
+// ones in MetricsReject were permanently refused. All other metrics of the
+// batch failed with Err and are kept for retrying.
+type PartialWriteError struct {
+	Err           error
+	MetricsAccept []int
+	MetricsReject []int
+}
+
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,70 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket limits the rate at which a resource, e.g. a number of metrics
+// or bytes, is consumed. The bucket is refilled continuously with 'rate'
+// tokens per second and holds at most the tokens refilled within 'burst',
+// allowing short bursts after idle periods.
+type TokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+
+	sync.Mutex
+}
+
+// NewTokenBucket returns a full bucket refilled with 'rate' tokens per second
+// and holding the tokens of 'burst' but at least one token.
+func NewTokenBucket(rate float64, burst time.Duration) *TokenBucket {
+	capacity := max(rate*burst.Seconds(), 1)
+	return &TokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     time.Now(),
+	}
+}
+
+// Available returns the number of tokens available at the given time. The
+// number is negative if more tokens were taken than available.
+func (b *TokenBucket) Available(now time.Time) float64 {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(now)
+	return b.tokens
+}
+
+// Full returns true if the bucket holds its full capacity at the given time.
+func (b *TokenBucket) Full(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// Take removes the given number of tokens from the bucket. Taking more tokens
+// than available is allowed to pass items larger than the capacity of the
+// bucket, the excess is subtracted from the following refills.
+func (b *TokenBucket) Take(now time.Time, n float64) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(now)
+	b.tokens -= n
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+	b.tokens = min(b.tokens+elapsed.Seconds()*b.rate, b.capacity)
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// logEntry is a log message split into its parts
+type logEntry struct {
+	Time     string `json:"time,omitempty"`
+	Level    string `json:"level"`
+	Category string `json:"category,omitempty"`
+	Plugin   string `json:"plugin,omitempty"`
+	Alias    string `json:"alias,omitempty"`
+	Message  string `json:"msg"`
+
+	severity byte
+	source   string
+}
+
+var levelNames = map[byte]string{
+	'D': "debug",
+	'I': "info",
+	'W': "warn",
+	'E': "error",
+}
+
+// parseLogEntry splits a log line of the form "E! [inputs.cpu::alias] msg"
+// into its parts. Lines without level are treated as informational.
+func parseLogEntry(b []byte) logEntry {
+	line := strings.TrimRight(string(b), "\r\n")
+
+	entry := logEntry{severity: 'I'}
+	if loc := prefixRegex.FindStringIndex(line); loc != nil {
+		entry.severity = line[loc[0]]
+		line = strings.TrimLeft(line[loc[1]:], " ")
+	}
+	entry.Level = levelNames[entry.severity]
+
+	// The source of the message is either a plugin like "inputs.cpu::alias"
+	// or a part of Telegraf like "agent"
+	if strings.HasPrefix(line, "[") {
+		if end := strings.Index(line, "] "); end > 0 {
+			source := line[1:end]
+			name, alias, _ := strings.Cut(source, "::")
+			if name != "" && !strings.Contains(name, " ") {
+				entry.source = source
+				entry.Category, entry.Plugin, _ = strings.Cut(name, ".")
+				entry.Alias = alias
+				line = line[end+2:]
+			}
+		}
+	}
+	entry.Message = line
+
+	return entry
+}
+
+// jsonWriter writes each log line as a JSON object
+type jsonWriter struct {
+	writer   io.Writer
+	timezone *time.Location
+}
+
+func (j *jsonWriter) Write(b []byte) (int, error) {
+	entry := parseLogEntry(b)
+	entry.Time = time.Now().In(j.timezone).Format(time.RFC3339)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return 0, err
+	}
+	if _, err := j.writer.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
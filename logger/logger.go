@@ -2,17 +2,20 @@ package logger
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/wlog"
 
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/rotate"
+	"github.com/influxdata/telegraf/models"
 )
 
 var prefixRegex = regexp.MustCompile("^[DIWE]!")
@@ -20,6 +23,12 @@ var prefixRegex = regexp.MustCompile("^[DIWE]!")
 const (
 	LogTargetFile   = "file"
 	LogTargetStderr = "stderr"
+	LogTargetSyslog = "syslog"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
 // LogConfig contains the log configuration settings
@@ -28,8 +37,14 @@ type LogConfig struct {
 	Debug bool
 	//will set the log level to ERROR
 	Quiet bool
-	//stderr, stdout, file or eventlog (Windows only)
+	//stderr, stdout, file, syslog or eventlog (Windows only)
 	LogTarget string
+	// text or json, the eventlog target ignores the format
+	LogFormat string
+	// address of the syslog server for the syslog target, e.g.
+	// "udp://localhost:514". Empty string is interpreted as the local
+	// syslog socket.
+	SyslogAddress string
 	// will direct the logging output to a file. Empty string is
 	// interpreted as stderr. If there is an error opening the file the
 	// logger will fall back to stderr
@@ -57,13 +72,49 @@ func registerLogger(name string, loggerCreator creator) {
 	loggerRegistry[name] = loggerCreator
 }
 
+// unfilteredWriter is implemented by log writers able to write messages
+// bypassing the filtering by log-level
+type unfilteredWriter interface {
+	unfiltered() io.Writer
+}
+
+type writerFunc func(b []byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
+
 type telegrafLog struct {
 	writer         io.Writer
+	output         io.Writer
 	internalWriter io.Writer
 	timezone       *time.Location
+	json           bool
+	sync.Mutex
 }
 
 func (t *telegrafLog) Write(b []byte) (n int, err error) {
+	return t.write(t.writer, b)
+}
+
+func (t *telegrafLog) unfiltered() io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		return t.write(t.output, b)
+	})
+}
+
+func (t *telegrafLog) write(w io.Writer, b []byte) (n int, err error) {
+	t.Lock()
+	defer t.Unlock()
+
+	// The JSON writer adds the time itself
+	if t.json {
+		if !prefixRegex.Match(b) {
+			b = append([]byte("I! "), b...)
+		}
+		return w.Write(b)
+	}
+
 	var line []byte
 	timeToPrint := time.Now().In(t.timezone)
 
@@ -73,7 +124,7 @@ func (t *telegrafLog) Write(b []byte) (n int, err error) {
 		line = append([]byte(timeToPrint.Format(time.RFC3339)+" "), b...)
 	}
 
-	return t.writer.Write(line)
+	return w.Write(line)
 }
 
 func (t *telegrafLog) Close() error {
@@ -92,23 +143,42 @@ func (t *telegrafLog) Close() error {
 
 // newTelegrafWriter returns a logging-wrapped writer.
 func newTelegrafWriter(w io.Writer, c LogConfig) (io.Writer, error) {
-	timezoneName := c.LogWithTimezone
-	if strings.EqualFold(timezoneName, "local") {
-		timezoneName = "Local"
+	tz, err := loadTimezone(c.LogWithTimezone)
+	if err != nil {
+		return nil, err
 	}
 
-	tz, err := time.LoadLocation(timezoneName)
-	if err != nil {
-		return nil, errors.New("error while setting logging timezone: " + err.Error())
+	if c.LogFormat == LogFormatJSON {
+		jw := &jsonWriter{writer: w, timezone: tz}
+		return &telegrafLog{
+			writer:         wlog.NewWriter(jw),
+			output:         jw,
+			internalWriter: w,
+			timezone:       tz,
+			json:           true,
+		}, nil
 	}
 
 	return &telegrafLog{
 		writer:         wlog.NewWriter(w),
+		output:         w,
 		internalWriter: w,
 		timezone:       tz,
 	}, nil
 }
 
+func loadTimezone(name string) (*time.Location, error) {
+	if strings.EqualFold(name, "local") {
+		name = "Local"
+	}
+
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("error while setting logging timezone: " + err.Error())
+	}
+	return tz, nil
+}
+
 // SetupLogging configures the logging output.
 func SetupLogging(cfg LogConfig) error {
 	_, err := newLogWriter(cfg)
@@ -149,6 +219,12 @@ func (t *telegrafLogCreator) CreateLogger(cfg LogConfig) (io.Writer, error) {
 var actualLogger io.Writer
 
 func newLogWriter(cfg LogConfig) (io.Writer, error) {
+	switch cfg.LogFormat {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.LogFormat)
+	}
+
 	log.SetFlags(0)
 	if cfg.Debug {
 		wlog.SetLevel(wlog.DEBUG)
@@ -176,6 +252,14 @@ func newLogWriter(cfg LogConfig) (io.Writer, error) {
 	log.SetOutput(logWriter)
 	actualLogger = logWriter
 
+	// Allow plugins with a more verbose log-level than the global one to
+	// write their messages
+	if uw, ok := logWriter.(unfilteredWriter); ok {
+		models.SetUnfilteredLogOutput(uw.unfiltered())
+	} else {
+		models.SetUnfilteredLogOutput(nil)
+	}
+
 	return logWriter, nil
 }
 
@@ -184,4 +268,5 @@ func init() {
 	registerLogger("", tlc)
 	registerLogger(LogTargetStderr, tlc)
 	registerLogger(LogTargetFile, tlc)
+	registerLogger(LogTargetSyslog, &syslogLoggerCreator{})
 }
@@ -2,15 +2,21 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/go-syslog/v3/rfc5424"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
 )
 
 func TestWriteLogToFile(t *testing.T) {
@@ -145,6 +151,193 @@ func TestLogTargetSettings(t *testing.T) {
 	require.Equal(t, logger.internalWriter, os.Stderr)
 }
 
+func TestWritePluginLogLevelToFile(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	cfg := createBasicLogConfig(tmpfile.Name())
+	cfg.Quiet = true
+	err = SetupLogging(cfg)
+	require.NoError(t, err)
+
+	l := models.NewLogger("inputs", "test", "")
+	l.SetLogLevel("debug")
+	l.Debug("TEST")
+	log.Printf("D! TEST") // <- should be ignored
+
+	f, err := os.ReadFile(tmpfile.Name())
+	require.NoError(t, err)
+	require.Equal(t, []byte("Z D! [inputs.test] TEST\n"), f[19:])
+}
+
+func TestWriteJSONLogToFile(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	cfg := createBasicLogConfig(tmpfile.Name())
+	cfg.LogFormat = LogFormatJSON
+	cfg.LogWithTimezone = "UTC"
+	err = SetupLogging(cfg)
+	require.NoError(t, err)
+	log.Printf("E! [inputs.cpu::mycpu] <failed> & stopped")
+	log.Printf("D! [agent] TEST") // <- should be ignored
+	log.Printf("TEST")
+
+	f, err := os.ReadFile(tmpfile.Name())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(f)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	require.Contains(t, entry, "time")
+	delete(entry, "time")
+	require.Equal(t, map[string]interface{}{
+		"level":    "error",
+		"category": "inputs",
+		"plugin":   "cpu",
+		"alias":    "mycpu",
+		"msg":      "<failed> & stopped",
+	}, entry)
+
+	entry = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	delete(entry, "time")
+	require.Equal(t, map[string]interface{}{"level": "info", "msg": "TEST"}, entry)
+}
+
+func TestInvalidLogFormat(t *testing.T) {
+	cfg := LogConfig{LogFormat: "xml"}
+	require.EqualError(t, SetupLogging(cfg), `invalid log format "xml"`)
+}
+
+func TestParseLogEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected logEntry
+	}{
+		{
+			name:     "plugin with alias",
+			line:     "W! [outputs.influxdb_v2::primary] buffer full\n",
+			expected: logEntry{Level: "warn", Category: "outputs", Plugin: "influxdb_v2", Alias: "primary", Message: "buffer full"},
+		},
+		{
+			name:     "agent",
+			line:     "D! [agent] Connecting outputs",
+			expected: logEntry{Level: "debug", Category: "agent", Message: "Connecting outputs"},
+		},
+		{
+			name:     "without source",
+			line:     "E! [no source] here",
+			expected: logEntry{Level: "error", Message: "[no source] here"},
+		},
+		{
+			name:     "without level",
+			line:     "Loading config",
+			expected: logEntry{Level: "info", Message: "Loading config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := parseLogEntry([]byte(tt.line))
+			entry.severity = 0
+			entry.source = ""
+			require.Equal(t, tt.expected, entry)
+		})
+	}
+}
+
+func TestSyslogLogger(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	actualLogger = nil
+	cfg := LogConfig{
+		LogTarget:       LogTargetSyslog,
+		SyslogAddress:   "udp://" + conn.LocalAddr().String(),
+		LogWithTimezone: "UTC",
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, SetupLogging(LogConfig{})) }()
+	_, isSyslogLogger := actualLogger.(*syslogLog)
+	require.True(t, isSyslogLogger)
+
+	log.Printf("D! [inputs.cpu] TEST") // <- should be ignored
+	log.Printf("W! [inputs.cpu::my cpu] TEST")
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg, err := rfc5424.NewParser().Parse(buf[:n])
+	require.NoError(t, err)
+	m := msg.(*rfc5424.SyslogMessage)
+	require.Equal(t, uint8(28), *m.Priority)
+	require.Equal(t, "telegraf", *m.Appname)
+	require.Equal(t, "inputs.cpu::my_cpu", *m.MsgID)
+	require.Equal(t, "TEST", *m.Message)
+}
+
+func TestSyslogLoggerReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	logger := &syslogLogger{
+		network:  "tcp",
+		address:  address,
+		timezone: time.UTC,
+		hostname: "-",
+		procid:   "1",
+	}
+	require.NoError(t, logger.connect())
+	logger.start()
+	defer logger.Close()
+
+	// Writing must not block while the server is down
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.NoError(t, listener.Close())
+	start := time.Now()
+	for i := 0; i < 2*syslogQueueSize; i++ {
+		_, err := logger.Write([]byte("I! [inputs.cpu] TEST"))
+		require.NoError(t, err)
+	}
+	require.Less(t, time.Since(start), time.Second)
+
+	// The number of dropped messages is reported after reconnecting
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			select {
+			case <-time.After(100 * time.Millisecond):
+				if _, err := logger.Write([]byte("I! [inputs.cpu] TEST")); err != nil {
+					return
+				}
+			case <-logger.done:
+				return
+			}
+		}
+	}()
+
+	conn, err = listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Contains(t, string(buf[:n]), "log messages while disconnected from syslog")
+}
+
 func BenchmarkTelegrafLogWrite(b *testing.B) {
 	var msg = []byte("test")
 	var buf bytes.Buffer
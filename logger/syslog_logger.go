@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/go-syslog/v3/rfc5424"
+	"github.com/influxdata/wlog"
+)
+
+// Facility "daemon" as defined in RFC5424
+const syslogFacility = 3
+
+var syslogSeverities = map[byte]uint8{
+	'D': 7,
+	'I': 6,
+	'W': 4,
+	'E': 3,
+}
+
+// Locations of the local syslog socket on the different platforms
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Bounds of the delay between attempts to reconnect to the syslog server
+const (
+	syslogMinBackoff = time.Second
+	syslogMaxBackoff = time.Minute
+)
+
+// Number of messages queued for sending, further messages are dropped
+const syslogQueueSize = 1000
+
+// syslogMessage is a log entry queued for sending
+type syslogMessage struct {
+	entry logEntry
+	ts    time.Time
+}
+
+// syslogLogger sends the log messages to syslog in the background so logging
+// never blocks on the connection. Messages are dropped while the connection
+// is down and reconnecting is delayed with an exponential backoff.
+type syslogLogger struct {
+	network  string
+	address  string
+	json     bool
+	timezone *time.Location
+	hostname string
+	procid   string
+
+	queue   chan syslogMessage
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+
+	// State of the connection, only used by the sending goroutine after start
+	conn    net.Conn
+	backoff time.Duration
+	retry   time.Time
+}
+
+func (s *syslogLogger) connect() error {
+	if s.address != "" {
+		conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogLocalSockets {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				s.network = network
+				s.conn = conn
+				return nil
+			}
+		}
+	}
+	return errors.New("no local syslog socket found")
+}
+
+// start starts sending the queued messages, the logger must be connected
+func (s *syslogLogger) start() {
+	s.queue = make(chan syslogMessage, syslogQueueSize)
+	s.done = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.backoff = syslogMinBackoff
+
+	go func() {
+		defer close(s.stopped)
+		for {
+			select {
+			case msg := <-s.queue:
+				s.send(msg)
+			case <-s.done:
+				// Send the messages queued before closing
+				for {
+					select {
+					case msg := <-s.queue:
+						s.send(msg)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+func (s *syslogLogger) Write(b []byte) (int, error) {
+	select {
+	case <-s.done:
+		return 0, errors.New("syslog logger closed")
+	default:
+	}
+
+	select {
+	case s.queue <- syslogMessage{entry: parseLogEntry(b), ts: time.Now()}:
+	default:
+		s.dropped.Add(1)
+	}
+	return len(b), nil
+}
+
+// send writes the message to the connection, reconnecting if the connection
+// broke, e.g. on a restart of the syslog daemon. Messages are dropped while
+// waiting for the next reconnect attempt.
+func (s *syslogLogger) send(msg syslogMessage) {
+	if s.conn != nil {
+		if s.writeMessage(msg) == nil {
+			return
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	now := time.Now()
+	if now.Before(s.retry) {
+		s.dropped.Add(1)
+		return
+	}
+	if err := s.connect(); err != nil {
+		s.retry = now.Add(s.backoff)
+		s.backoff = min(2*s.backoff, syslogMaxBackoff)
+		s.dropped.Add(1)
+		return
+	}
+	s.backoff = syslogMinBackoff
+
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		notice := logEntry{
+			Level:    levelNames['W'],
+			Message:  "Dropped " + strconv.FormatInt(dropped, 10) + " log messages while disconnected from syslog",
+			severity: 'W',
+		}
+		if err := s.writeMessage(syslogMessage{entry: notice, ts: now}); err != nil {
+			s.dropped.Add(dropped)
+		}
+	}
+	if err := s.writeMessage(msg); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.dropped.Add(1)
+	}
+}
+
+func (s *syslogLogger) writeMessage(msg syslogMessage) error {
+	buf, err := s.format(msg.entry, msg.ts)
+	if err != nil {
+		// Messages that cannot be formatted will never succeed
+		return nil
+	}
+	_, err = s.conn.Write(buf)
+	return err
+}
+
+// format creates a RFC5424 message from the entry with framing suitable for
+// the connection
+func (s *syslogLogger) format(entry logEntry, ts time.Time) ([]byte, error) {
+	text := entry.Message
+	if s.json {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(entry); err != nil {
+			return nil, err
+		}
+		text = strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	sm := &rfc5424.SyslogMessage{}
+	sm.SetPriority(syslogFacility*8 + syslogSeverities[entry.severity])
+	sm.SetVersion(1)
+	sm.SetTimestamp(ts.In(s.timezone).Format("2006-01-02T15:04:05.000000Z07:00"))
+	sm.SetHostname(s.hostname)
+	sm.SetAppname("telegraf")
+	sm.SetProcID(s.procid)
+	sm.SetMsgID(syslogMsgID(entry.source))
+	sm.SetMessage(text)
+	msg, err := sm.String()
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.network {
+	case "tcp", "tcp4", "tcp6":
+		// Use octet-counting framing as described in RFC6587
+		return []byte(strconv.Itoa(len(msg)) + " " + msg), nil
+	case "unix":
+		return []byte(msg + "\n"), nil
+	}
+	return []byte(msg), nil
+}
+
+func (s *syslogLogger) Close() error {
+	s.once.Do(func() { close(s.done) })
+	<-s.stopped
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// syslogMsgID converts the source of the message, e.g. the plugin, to a
+// valid message ID containing at most 32 printable ASCII characters
+func syslogMsgID(source string) string {
+	id := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, source)
+	if len(id) > 32 {
+		id = id[:32]
+	}
+	return id
+}
+
+// syslogLog adds the default log-level and filters the messages before
+// passing them to the syslog logger
+type syslogLog struct {
+	writer io.Writer
+	logger *syslogLogger
+	sync.Mutex
+}
+
+func (t *syslogLog) Write(b []byte) (int, error) {
+	return t.write(t.writer, b)
+}
+
+func (t *syslogLog) unfiltered() io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		return t.write(t.logger, b)
+	})
+}
+
+func (t *syslogLog) write(w io.Writer, b []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if !prefixRegex.Match(b) {
+		b = append([]byte("I! "), b...)
+	}
+	return w.Write(b)
+}
+
+func (t *syslogLog) Close() error {
+	return t.logger.Close()
+}
+
+type syslogLoggerCreator struct{}
+
+func (*syslogLoggerCreator) CreateLogger(cfg LogConfig) (io.Writer, error) {
+	tz, err := loadTimezone(cfg.LogWithTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	logger := &syslogLogger{
+		json:     cfg.LogFormat == LogFormatJSON,
+		timezone: tz,
+		hostname: hostname,
+		procid:   strconv.Itoa(os.Getpid()),
+	}
+	if cfg.SyslogAddress != "" {
+		network, address, found := strings.Cut(cfg.SyslogAddress, "://")
+		if !found {
+			log.Printf("E! Invalid syslog address %q, using stderr", cfg.SyslogAddress)
+			return newTelegrafWriter(os.Stderr, cfg)
+		}
+		switch network {
+		case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+		default:
+			log.Printf("E! Unsupported syslog network %q, using stderr", network)
+			return newTelegrafWriter(os.Stderr, cfg)
+		}
+		logger.network = network
+		logger.address = address
+	}
+
+	if err := logger.connect(); err != nil {
+		log.Printf("E! Unable to connect to syslog (%s), using stderr", err)
+		return newTelegrafWriter(os.Stderr, cfg)
+	}
+	logger.start()
+
+	return &syslogLog{
+		writer: wlog.NewWriter(logger),
+		logger: logger,
+	}, nil
+}
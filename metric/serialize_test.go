@@ -0,0 +1,61 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestSerializeRoundtrip(t *testing.T) {
+	m := New(
+		"cpu",
+		map[string]string{
+			"host": "localhost",
+			"cpu":  "cpu0",
+		},
+		map[string]interface{}{
+			"float":    42.5,
+			"int":      int64(-23),
+			"uint":     uint64(23),
+			"string":   "foo",
+			"bool":     true,
+			"negative": -1.0,
+		},
+		time.Unix(1710000000, 123456789),
+		telegraf.Counter,
+	)
+
+	buf, err := ToBytes(m)
+	require.NoError(t, err)
+
+	actual, err := FromBytes(buf)
+	require.NoError(t, err)
+	require.Equal(t, m.Name(), actual.Name())
+	require.Equal(t, m.Tags(), actual.Tags())
+	require.Equal(t, m.Fields(), actual.Fields())
+	require.Equal(t, m.Time().UnixNano(), actual.Time().UnixNano())
+	require.Equal(t, m.Type(), actual.Type())
+	require.Equal(t, m.HashID(), actual.HashID())
+}
+
+func TestSerializeTrackingMetric(t *testing.T) {
+	m := New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+
+	buf, err := ToBytes(tm)
+	require.NoError(t, err)
+
+	actual, err := FromBytes(buf)
+	require.NoError(t, err)
+	_, isTracking := actual.(telegraf.TrackingMetric)
+	require.False(t, isTracking)
+	require.Equal(t, m.Fields(), actual.Fields())
+}
+
+func TestDeserializeInvalid(t *testing.T) {
+	_, err := FromBytes([]byte("invalid"))
+	require.Error(t, err)
+}
@@ -0,0 +1,666 @@
+package models
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	// Size after which a new write-ahead log segment is started.
+	diskBufferSegmentSize = 16 * 1024 * 1024
+
+	// Size of the header of each record consisting of the payload length
+	// and the payload's CRC32 checksum.
+	diskBufferHeaderSize = 8
+
+	diskBufferSegmentExt = ".wal"
+	diskBufferHeadFile   = "head"
+)
+
+// DiskBuffer stores metrics in a segmented write-ahead log on disk so they
+// survive restarts of Telegraf. Each metric is assigned a monotonically
+// increasing index; segment files are named by the index of their first
+// record and are removed as soon as all of their metrics are written or
+// dropped. The index of the oldest metric still pending is kept in a
+// separate head file.
+//
+// Outputs add metrics one at a time, so records are not synced when added but
+// before reading the next batch on flush and when closing the buffer. A crash
+// might lose the metrics added since the last flush. The head file is synced
+// on every change.
+type DiskBuffer struct {
+	sync.Mutex
+	BufferStats
+
+	path     string
+	cap      int
+	segments []*diskSegment // ordered from oldest to newest
+	writer   *os.File       // newest segment opened for appending
+	dirty    bool           // records appended to the writer are not synced
+
+	first uint64 // index of the first/oldest metric
+	next  uint64 // index assigned to the next metric added
+
+	batchFirst   uint64   // index of the first metric in the batch
+	batchSize    int      // number of log entries covered by the batch
+	batchIndices []uint64 // index of each metric returned in the batch
+
+	// Position of the record following the last batch read to avoid
+	// rescanning the segment for subsequent batches.
+	readIndex  uint64
+	readOffset int64
+
+	// Metrics with delivery tracking are kept in memory as the tracking
+	// information cannot be persisted.
+	tracking map[uint64]telegraf.Metric
+}
+
+type diskSegment struct {
+	path  string
+	first uint64 // index of the first record in the segment
+	count int    // number of records in the segment
+	size  int64  // size of the segment in bytes
+}
+
+func (s *diskSegment) end() uint64 {
+	return s.first + uint64(s.count)
+}
+
+// NewDiskBuffer returns a buffer storing metrics in the given directory.
+// Metrics left over in the directory from a previous run are restored.
+func NewDiskBuffer(name string, alias string, capacity int, path string) (*DiskBuffer, error) {
+	if path == "" {
+		return nil, errors.New("no directory given for disk buffer")
+	}
+	if err := os.MkdirAll(path, 0750); err != nil {
+		return nil, fmt.Errorf("creating buffer directory failed: %w", err)
+	}
+
+	b := &DiskBuffer{
+		BufferStats: NewBufferStats(name, alias, capacity),
+		path:        path,
+		cap:         capacity,
+		tracking:    make(map[uint64]telegraf.Metric),
+	}
+	if err := b.restore(); err != nil {
+		return nil, err
+	}
+
+	// Drop the oldest metrics if the restored buffer exceeds the capacity
+	if n := b.length(); n > b.cap {
+		b.dropOldest(n - b.cap)
+		if err := b.commit(); err != nil {
+			return nil, err
+		}
+	}
+	b.BufferSize.Set(int64(b.length()))
+
+	return b, nil
+}
+
+// Len returns the number of metrics currently in the buffer.
+func (b *DiskBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *DiskBuffer) length() int {
+	return int(b.next - b.first)
+}
+
+// Add adds metrics to the buffer and returns number of dropped metrics.
+func (b *DiskBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	dropped := 0
+	for _, m := range metrics {
+		if b.length() >= b.cap {
+			b.dropOldest(1)
+			dropped++
+		}
+
+		if err := b.append(m); err != nil {
+			b.metricDropped(m, DropReasonError)
+			dropped++
+			continue
+		}
+		b.metricAdded()
+	}
+
+	// Get rid of segments containing only dropped metrics. The head is not
+	// persisted here as dropping overflowing metrics is repeated on restore.
+	if dropped > 0 {
+		_ = b.cleanup()
+	}
+
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+// Batch returns a slice containing up to batchSize of the oldest metrics not
+// yet dropped.  Metrics are ordered from oldest to newest in the batch.  The
+// batch must not be modified by the client.
+func (b *DiskBuffer) Batch(batchSize int) []telegraf.Metric {
+	b.Lock()
+	defer b.Unlock()
+
+	// Persist the metrics added since the last flush
+	b.sync()
+
+	n := min(b.length(), batchSize)
+	b.batchFirst = b.first
+	b.batchSize = 0
+	b.batchIndices = b.batchIndices[:0]
+	if n == 0 {
+		return make([]telegraf.Metric, 0)
+	}
+
+	out := make([]telegraf.Metric, 0, n)
+	count, _ := b.read(b.first, n, func(index uint64, data []byte) {
+		if m, found := b.tracking[index]; found {
+			out = append(out, m)
+			b.batchIndices = append(b.batchIndices, index)
+			return
+		}
+
+		m, err := metric.FromBytes(data)
+		if err != nil {
+			AgentMetricsDropped.Incr(1)
+			b.MetricsDropped.Incr(1)
+			return
+		}
+		out = append(out, m)
+		b.batchIndices = append(b.batchIndices, index)
+	})
+	b.batchSize = count
+
+	return out
+}
+
+// Accept marks the batch, acquired from Batch(), as successfully written.
+func (b *DiskBuffer) Accept(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.remove(batch, func(_ int, m telegraf.Metric) {
+		b.metricWritten(m)
+	})
+}
+
+// Drop removes the batch, acquired from Batch(), from the buffer and marks it
+// as dropped for the given reason.
+func (b *DiskBuffer) Drop(batch []telegraf.Metric, reason string) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.remove(batch, func(_ int, m telegraf.Metric) {
+		b.metricDropped(m, reason)
+	})
+}
+
+// Partial accepts and drops the given metrics of the batch, acquired from
+// Batch(). As the log cannot contain gaps, the remaining metrics are appended
+// to the end of the log again and are written after metrics added meanwhile.
+func (b *DiskBuffer) Partial(batch []telegraf.Metric, accept []int, drop map[int]string) {
+	b.Lock()
+	defer b.Unlock()
+
+	accepted := make(map[int]bool, len(accept))
+	for _, i := range accept {
+		accepted[i] = true
+	}
+
+	// Append the remaining metrics before removing the batch to not lose
+	// them on a crash in between
+	for i, m := range batch {
+		if _, found := drop[i]; found || accepted[i] || !b.inBatch(i) {
+			continue
+		}
+		if err := b.append(m); err != nil {
+			b.metricDropped(m, DropReasonError)
+		}
+	}
+	b.sync()
+
+	b.remove(batch, func(i int, m telegraf.Metric) {
+		if accepted[i] {
+			b.metricWritten(m)
+		} else if reason, found := drop[i]; found {
+			b.metricDropped(m, reason)
+		}
+	})
+}
+
+// inBatch returns true if the metric at the given index of the batch is still
+// contained in the buffer. Metrics might have been dropped from the batch due
+// to overflow while the batch was written.
+func (b *DiskBuffer) inBatch(i int) bool {
+	return i < len(b.batchIndices) && b.batchIndices[i] >= b.first
+}
+
+// remove removes the batch from the buffer calling the given function for
+// every metric still contained in the buffer.
+func (b *DiskBuffer) remove(batch []telegraf.Metric, fn func(int, telegraf.Metric)) {
+	for i, m := range batch {
+		if !b.inBatch(i) {
+			continue
+		}
+		delete(b.tracking, b.batchIndices[i])
+		fn(i, m)
+	}
+
+	if end := b.batchFirst + uint64(b.batchSize); end > b.first {
+		b.first = end
+	}
+	_ = b.commit()
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Reject returns the batch, acquired from Batch(), to the buffer and marks it
+// as unsent. As the metrics are never removed from the log, there is nothing
+// to restore.
+func (b *DiskBuffer) Reject(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Close persists the state of the buffer and closes the log. Metrics still in
+// the buffer are restored when opening the buffer again.
+func (b *DiskBuffer) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.commit(); err != nil {
+		return err
+	}
+	if b.writer == nil {
+		return nil
+	}
+	err := b.writer.Sync()
+	if cerr := b.writer.Close(); err == nil {
+		err = cerr
+	}
+	b.writer = nil
+	b.dirty = false
+	return err
+}
+
+func (b *DiskBuffer) resetBatch() {
+	b.batchFirst = 0
+	b.batchSize = 0
+	b.batchIndices = b.batchIndices[:0]
+}
+
+// dropOldest removes the given number of oldest metrics from the buffer.
+func (b *DiskBuffer) dropOldest(count int) {
+	for i := 0; i < count && b.first < b.next; i++ {
+		m, found := b.tracking[b.first]
+		if found {
+			delete(b.tracking, b.first)
+		} else if b.dropHandler != nil {
+			// Only restore the metric if someone is interested in it
+			_, _ = b.read(b.first, 1, func(_ uint64, data []byte) {
+				m, _ = metric.FromBytes(data)
+			})
+		}
+		if m != nil {
+			b.metricDropped(m, DropReasonOverflow)
+		} else {
+			AgentMetricsDropped.Incr(1)
+			b.MetricsDropped.Incr(1)
+		}
+		b.first++
+	}
+}
+
+// append writes the metric as new record to the end of the log.
+func (b *DiskBuffer) append(m telegraf.Metric) error {
+	data, err := metric.ToBytes(m)
+	if err != nil {
+		return err
+	}
+
+	if b.writer == nil || b.segments[len(b.segments)-1].size >= diskBufferSegmentSize {
+		if err := b.rotate(); err != nil {
+			return err
+		}
+	}
+
+	record := make([]byte, diskBufferHeaderSize+len(data))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(data))
+	copy(record[diskBufferHeaderSize:], data)
+
+	segment := b.segments[len(b.segments)-1]
+	n, err := b.writer.Write(record)
+	if err != nil {
+		// Cut off the partial record to keep the segment consistent
+		if n > 0 {
+			_ = b.writer.Truncate(segment.size)
+			_, _ = b.writer.Seek(segment.size, io.SeekStart)
+		}
+		return fmt.Errorf("writing to buffer failed: %w", err)
+	}
+	segment.size += int64(n)
+	segment.count++
+	b.dirty = true
+
+	if _, ok := m.(telegraf.TrackingMetric); ok {
+		b.tracking[b.next] = m
+	}
+	b.next++
+
+	return nil
+}
+
+// sync persists the records appended to the newest segment. The metrics are
+// still readable on failure, they are just not guaranteed to survive a crash.
+func (b *DiskBuffer) sync() {
+	if b.writer == nil || !b.dirty {
+		return
+	}
+	if err := b.writer.Sync(); err == nil {
+		b.dirty = false
+	}
+}
+
+// rotate finalizes the current segment and starts a new one.
+func (b *DiskBuffer) rotate() error {
+	if b.writer != nil {
+		if err := b.writer.Sync(); err != nil {
+			return err
+		}
+		if err := b.writer.Close(); err != nil {
+			return err
+		}
+		b.writer = nil
+		b.dirty = false
+	}
+
+	segment := &diskSegment{
+		path:  filepath.Join(b.path, fmt.Sprintf("%020d%s", b.next, diskBufferSegmentExt)),
+		first: b.next,
+	}
+	f, err := os.OpenFile(segment.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("creating buffer segment failed: %w", err)
+	}
+	b.writer = f
+	b.segments = append(b.segments, segment)
+
+	// Make sure the new segment survives a crash
+	if err := syncDir(b.path); err != nil {
+		return fmt.Errorf("syncing buffer directory failed: %w", err)
+	}
+
+	return nil
+}
+
+// read calls the given function for up to count records starting at the
+// given index and returns the number of records read.
+func (b *DiskBuffer) read(from uint64, count int, fn func(uint64, []byte)) (int, error) {
+	var read int
+	for _, segment := range b.segments {
+		if read >= count {
+			break
+		}
+		if segment.end() <= from {
+			continue
+		}
+
+		n, err := b.readSegment(segment, from, count-read, fn)
+		read += n
+		from += uint64(n)
+		if err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+func (b *DiskBuffer) readSegment(segment *diskSegment, from uint64, count int, fn func(uint64, []byte)) (int, error) {
+	f, err := os.Open(segment.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	// Start at the cached read position if possible, otherwise skip the
+	// records preceding the requested index.
+	index := segment.first
+	var offset int64
+	if b.readIndex == from && b.readIndex > segment.first && b.readIndex < segment.end() {
+		index = b.readIndex
+		offset = b.readOffset
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+
+	reader := bufio.NewReader(f)
+	header := make([]byte, diskBufferHeaderSize)
+	var read int
+	for index < segment.end() && read < count {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return read, err
+		}
+		length := int(binary.LittleEndian.Uint32(header[0:4]))
+		if index < from {
+			if _, err := reader.Discard(length); err != nil {
+				return read, err
+			}
+		} else {
+			data := make([]byte, length)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return read, err
+			}
+			fn(index, data)
+			read++
+		}
+		offset += int64(diskBufferHeaderSize + length)
+		index++
+	}
+	b.readIndex = index
+	b.readOffset = offset
+
+	return read, nil
+}
+
+// commit removes segments no longer required and persists the head.
+func (b *DiskBuffer) commit() error {
+	if err := b.cleanup(); err != nil {
+		return err
+	}
+
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], b.first)
+	fn := filepath.Join(b.path, diskBufferHeadFile)
+	tmp := fn + ".tmp"
+	if err := writeFileSync(tmp, buf[:]); err != nil {
+		return fmt.Errorf("writing buffer head failed: %w", err)
+	}
+	if err := os.Rename(tmp, fn); err != nil {
+		return fmt.Errorf("writing buffer head failed: %w", err)
+	}
+	if err := syncDir(b.path); err != nil {
+		return fmt.Errorf("syncing buffer directory failed: %w", err)
+	}
+	return nil
+}
+
+// writeFileSync writes the data to the given file and syncs the file to disk.
+func writeFileSync(fn string, data []byte) error {
+	f, err := os.OpenFile(fn, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir syncs the given directory to persist created, removed or renamed
+// files. Windows does not support syncing directories.
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// cleanup removes all segments only containing metrics before the head.
+func (b *DiskBuffer) cleanup() error {
+	for len(b.segments) > 0 && b.segments[0].end() <= b.first {
+		// Keep the segment we are currently writing to as long as it has
+		// still room for more records.
+		if len(b.segments) == 1 && b.writer != nil && b.segments[0].size < diskBufferSegmentSize {
+			break
+		}
+		if len(b.segments) == 1 && b.writer != nil {
+			_ = b.writer.Close()
+			b.writer = nil
+			b.dirty = false
+		}
+		if err := os.Remove(b.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing buffer segment failed: %w", err)
+		}
+		b.segments = b.segments[1:]
+	}
+	return nil
+}
+
+// restore loads the state of the buffer from the directory and repairs
+// segments damaged by an unclean shutdown.
+func (b *DiskBuffer) restore() error {
+	entries, err := os.ReadDir(b.path)
+	if err != nil {
+		return fmt.Errorf("reading buffer directory failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, diskBufferSegmentExt) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(name, diskBufferSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		b.segments = append(b.segments, &diskSegment{
+			path:  filepath.Join(b.path, name),
+			first: first,
+		})
+	}
+	sort.Slice(b.segments, func(i, j int) bool { return b.segments[i].first < b.segments[j].first })
+
+	segments := make([]*diskSegment, 0, len(b.segments))
+	for _, segment := range b.segments {
+		if err := segment.scan(); err != nil {
+			return err
+		}
+		if segment.count == 0 {
+			_ = os.Remove(segment.path)
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	b.segments = segments
+
+	// Remove segments not continuing the log as we cannot determine the
+	// index of their records.
+	for i := 1; i < len(b.segments); i++ {
+		if b.segments[i].first != b.segments[i-1].end() {
+			for _, segment := range b.segments[i:] {
+				_ = os.Remove(segment.path)
+			}
+			b.segments = b.segments[:i]
+			break
+		}
+	}
+
+	buf, err := os.ReadFile(filepath.Join(b.path, diskBufferHeadFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading buffer head failed: %w", err)
+	}
+	if len(buf) == 8 {
+		b.first = binary.LittleEndian.Uint64(buf)
+	}
+
+	if len(b.segments) > 0 {
+		b.first = max(b.first, b.segments[0].first)
+		b.next = b.segments[len(b.segments)-1].end()
+	}
+	b.next = max(b.next, b.first)
+
+	return b.commit()
+}
+
+// scan determines the number of valid records in the segment and truncates
+// any damaged data at the end of the segment.
+func (s *diskSegment) scan() error {
+	f, err := os.OpenFile(s.path, os.O_RDWR, 0640)
+	if err != nil {
+		return fmt.Errorf("opening buffer segment failed: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	header := make([]byte, diskBufferHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			break
+		}
+		data := make([]byte, binary.LittleEndian.Uint32(header[0:4]))
+		if _, err := io.ReadFull(reader, data); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(header[4:8]) {
+			break
+		}
+		s.size += int64(diskBufferHeaderSize + len(data))
+		s.count++
+	}
+
+	if err := f.Truncate(s.size); err != nil {
+		return fmt.Errorf("truncating buffer segment failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestDiskBuffer(t *testing.T, path string, capacity int) *DiskBuffer {
+	t.Helper()
+
+	b, err := NewDiskBuffer("test", "", capacity, path)
+	require.NoError(t, err)
+	b.MetricsAdded.Set(0)
+	b.MetricsWritten.Set(0)
+	b.MetricsDropped.Set(0)
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestDiskBuffer_NoDirectory(t *testing.T) {
+	_, err := NewBuffer("test", "", 5, "disk", "")
+	require.ErrorContains(t, err, "no directory")
+}
+
+func TestDiskBuffer_InvalidStrategy(t *testing.T) {
+	_, err := NewBuffer("test", "", 5, "foo", t.TempDir())
+	require.ErrorContains(t, err, "invalid buffer strategy")
+}
+
+func TestDiskBuffer_LenEmpty(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 5)
+
+	require.Equal(t, 0, b.Len())
+	require.Empty(t, b.Batch(2))
+}
+
+func TestDiskBuffer_BatchAccept(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 10)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+	require.Equal(t, 3, b.Len())
+
+	batch := b.Batch(2)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(1), MetricTime(2)}, batch)
+	require.Equal(t, 3, b.Len())
+
+	b.Accept(batch)
+	require.Equal(t, 1, b.Len())
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+
+	batch = b.Batch(2)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(3)}, batch)
+	b.Accept(batch)
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, int64(3), b.MetricsWritten.Get())
+}
+
+func TestDiskBuffer_BatchReject(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 10)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+
+	batch := b.Batch(2)
+	b.Reject(batch)
+	require.Equal(t, 3, b.Len())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(1), MetricTime(2), MetricTime(3)},
+		batch,
+	)
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+}
+
+func TestDiskBuffer_Overflow(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 3)
+	dropped := b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4), MetricTime(5))
+	require.Equal(t, 2, dropped)
+	require.Equal(t, 3, b.Len())
+	require.Equal(t, int64(2), b.MetricsDropped.Get())
+
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(3), MetricTime(4), MetricTime(5)},
+		batch,
+	)
+}
+
+func TestDiskBuffer_OverflowDuringBatch(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 3)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+
+	batch := b.Batch(2)
+	b.Add(MetricTime(4))
+	b.Accept(batch)
+
+	// The first metric of the batch was dropped, so only the second one is
+	// counted as written.
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(3), MetricTime(4)}, batch)
+}
+
+func TestDiskBuffer_Partial(t *testing.T) {
+	dir := t.TempDir()
+
+	b := newTestDiskBuffer(t, dir, 10)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+
+	batch := b.Batch(3)
+	b.Partial(batch, []int{0}, map[int]string{2: DropReasonRejected})
+	require.Equal(t, 2, b.Len())
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+	require.NoError(t, b.Close())
+
+	// The remaining metrics are appended to the log again and must survive
+	// a restart
+	b = newTestDiskBuffer(t, dir, 10)
+	require.Equal(t, 2, b.Len())
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(4), MetricTime(2)}, batch)
+}
+
+func TestDiskBuffer_Restore(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewDiskBuffer("test", "", 10, dir)
+	require.NoError(t, err)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+	b.Accept(b.Batch(1))
+	b.Reject(b.Batch(2))
+	require.NoError(t, b.Close())
+
+	b = newTestDiskBuffer(t, dir, 10)
+	require.Equal(t, 3, b.Len())
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(2), MetricTime(3), MetricTime(4)},
+		batch,
+	)
+}
+
+func TestDiskBuffer_RestoreOverCapacity(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewDiskBuffer("test", "", 10, dir)
+	require.NoError(t, err)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+	require.NoError(t, b.Close())
+
+	b = newTestDiskBuffer(t, dir, 2)
+	require.Equal(t, 2, b.Len())
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(3), MetricTime(4)}, batch)
+}
+
+func TestDiskBuffer_RestoreTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewDiskBuffer("test", "", 10, dir)
+	require.NoError(t, err)
+	b.Add(MetricTime(1), MetricTime(2))
+	require.NoError(t, b.Close())
+
+	// Simulate a crash in the middle of writing a record
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+diskBufferSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	f, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0x10, 0x00, 0x00, 0x00, 0xde, 0xad})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	b = newTestDiskBuffer(t, dir, 10)
+	require.Equal(t, 2, b.Len())
+	b.Add(MetricTime(3))
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(1), MetricTime(2), MetricTime(3)},
+		batch,
+	)
+}
+
+func TestDiskBuffer_SegmentsRemoved(t *testing.T) {
+	dir := t.TempDir()
+	b := newTestDiskBuffer(t, dir, 10)
+
+	b.Add(MetricTime(1), MetricTime(2))
+	require.NoError(t, b.Close())
+
+	b = newTestDiskBuffer(t, dir, 10)
+	b.Add(MetricTime(3))
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+diskBufferSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+
+	b.Accept(b.Batch(2))
+	segments, err = filepath.Glob(filepath.Join(dir, "*"+diskBufferSegmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+}
+
+func TestDiskBuffer_TrackingMetrics(t *testing.T) {
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	b := newTestDiskBuffer(t, t.TempDir(), 10)
+	tm, _ := metric.WithTracking(MetricTime(1), notify)
+	b.Add(tm, MetricTime(2))
+
+	batch := b.Batch(2)
+	require.Len(t, batch, 2)
+	_, isTracking := batch[0].(telegraf.TrackingMetric)
+	require.True(t, isTracking)
+
+	b.Reject(batch)
+	require.Empty(t, delivered)
+
+	b.Accept(b.Batch(2))
+	require.Len(t, delivered, 1)
+	require.True(t, delivered[0].Delivered())
+}
+
+func TestDiskBuffer_TrackingMetricsDropped(t *testing.T) {
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		delivered = append(delivered, di)
+	}
+
+	b := newTestDiskBuffer(t, t.TempDir(), 1)
+	tm, _ := metric.WithTracking(MetricTime(1), notify)
+	b.Add(tm)
+	b.Add(MetricTime(2))
+
+	require.Len(t, delivered, 1)
+	require.False(t, delivered[0].Delivered())
+}
+
+func BenchmarkDiskBufferAddMetrics(b *testing.B) {
+	buf, err := NewDiskBuffer("test", "", b.N+1, b.TempDir())
+	require.NoError(b, err)
+	defer buf.Close()
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	for n := 0; n < b.N; n++ {
+		buf.Add(m)
+	}
+}
+
+func TestDiskBuffer_DropHandler(t *testing.T) {
+	b := newTestDiskBuffer(t, t.TempDir(), 3)
+
+	var dropped []telegraf.Metric
+	var reasons []string
+	b.SetDropHandler(func(m telegraf.Metric, reason string) {
+		dropped = append(dropped, m)
+		reasons = append(reasons, reason)
+	})
+
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+	batch := b.Batch(2)
+	b.Drop(batch, DropReasonRejected)
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(1), MetricTime(2), MetricTime(3)}, dropped)
+	require.Equal(t, []string{DropReasonOverflow, DropReasonRejected, DropReasonRejected}, reasons)
+	require.Equal(t, int64(3), b.MetricsDropped.Get())
+	require.Equal(t, int64(0), b.MetricsWritten.Get())
+	require.Equal(t, 1, b.Len())
+}
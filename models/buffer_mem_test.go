@@ -47,14 +47,14 @@ func MetricTime(sec int64) telegraf.Metric {
 }
 
 func BenchmarkAddMetrics(b *testing.B) {
-	buf := NewBuffer("test", "", 10000)
+	buf := NewMemoryBuffer("test", "", 10000)
 	m := Metric()
 	for n := 0; n < b.N; n++ {
 		buf.Add(m)
 	}
 }
 
-func setup(b *Buffer) *Buffer {
+func setup(b *MemoryBuffer) *MemoryBuffer {
 	b.MetricsAdded.Set(0)
 	b.MetricsWritten.Set(0)
 	b.MetricsDropped.Set(0)
@@ -62,14 +62,14 @@ func setup(b *Buffer) *Buffer {
 }
 
 func TestBuffer_LenEmpty(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	require.Equal(t, 0, b.Len())
 }
 
 func TestBuffer_LenOne(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m)
 
 	require.Equal(t, 1, b.Len())
@@ -77,7 +77,7 @@ func TestBuffer_LenOne(t *testing.T) {
 
 func TestBuffer_LenFull(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m, m, m)
 
 	require.Equal(t, 5, b.Len())
@@ -85,7 +85,7 @@ func TestBuffer_LenFull(t *testing.T) {
 
 func TestBuffer_LenOverfill(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	setup(b)
 	b.Add(m, m, m, m, m, m)
 
@@ -93,14 +93,14 @@ func TestBuffer_LenOverfill(t *testing.T) {
 }
 
 func TestBuffer_BatchLenZero(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	batch := b.Batch(0)
 
 	require.Empty(t, batch)
 }
 
 func TestBuffer_BatchLenBufferEmpty(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	batch := b.Batch(2)
 
 	require.Empty(t, batch)
@@ -108,7 +108,7 @@ func TestBuffer_BatchLenBufferEmpty(t *testing.T) {
 
 func TestBuffer_BatchLenUnderfill(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m)
 	batch := b.Batch(2)
 
@@ -117,7 +117,7 @@ func TestBuffer_BatchLenUnderfill(t *testing.T) {
 
 func TestBuffer_BatchLenFill(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m)
 	batch := b.Batch(2)
 	require.Len(t, batch, 2)
@@ -125,7 +125,7 @@ func TestBuffer_BatchLenFill(t *testing.T) {
 
 func TestBuffer_BatchLenExact(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m)
 	batch := b.Batch(2)
 	require.Len(t, batch, 2)
@@ -133,7 +133,7 @@ func TestBuffer_BatchLenExact(t *testing.T) {
 
 func TestBuffer_BatchLenLargerThanBuffer(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(6)
 	require.Len(t, batch, 5)
@@ -141,7 +141,7 @@ func TestBuffer_BatchLenLargerThanBuffer(t *testing.T) {
 
 func TestBuffer_BatchWrap(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(2)
 	b.Accept(batch)
@@ -151,7 +151,7 @@ func TestBuffer_BatchWrap(t *testing.T) {
 }
 
 func TestBuffer_BatchLatest(t *testing.T) {
-	b := setup(NewBuffer("test", "", 4))
+	b := setup(NewMemoryBuffer("test", "", 4))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -165,7 +165,7 @@ func TestBuffer_BatchLatest(t *testing.T) {
 }
 
 func TestBuffer_BatchLatestWrap(t *testing.T) {
-	b := setup(NewBuffer("test", "", 4))
+	b := setup(NewMemoryBuffer("test", "", 4))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -181,7 +181,7 @@ func TestBuffer_BatchLatestWrap(t *testing.T) {
 }
 
 func TestBuffer_MultipleBatch(t *testing.T) {
-	b := setup(NewBuffer("test", "", 10))
+	b := setup(NewMemoryBuffer("test", "", 10))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -207,7 +207,7 @@ func TestBuffer_MultipleBatch(t *testing.T) {
 }
 
 func TestBuffer_RejectWithRoom(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -230,7 +230,7 @@ func TestBuffer_RejectWithRoom(t *testing.T) {
 }
 
 func TestBuffer_RejectNothingNewFull(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -253,7 +253,7 @@ func TestBuffer_RejectNothingNewFull(t *testing.T) {
 }
 
 func TestBuffer_RejectNoRoom(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 
 	b.Add(MetricTime(2))
@@ -282,7 +282,7 @@ func TestBuffer_RejectNoRoom(t *testing.T) {
 }
 
 func TestBuffer_RejectRoomExact(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	batch := b.Batch(2)
@@ -306,7 +306,7 @@ func TestBuffer_RejectRoomExact(t *testing.T) {
 }
 
 func TestBuffer_RejectRoomOverwriteOld(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -331,7 +331,7 @@ func TestBuffer_RejectRoomOverwriteOld(t *testing.T) {
 }
 
 func TestBuffer_RejectPartialRoom(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 
 	b.Add(MetricTime(2))
@@ -358,7 +358,7 @@ func TestBuffer_RejectPartialRoom(t *testing.T) {
 }
 
 func TestBuffer_RejectNewMetricsWrapped(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -401,7 +401,7 @@ func TestBuffer_RejectNewMetricsWrapped(t *testing.T) {
 }
 
 func TestBuffer_RejectWrapped(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -432,7 +432,7 @@ func TestBuffer_RejectWrapped(t *testing.T) {
 }
 
 func TestBuffer_RejectAdjustFirst(t *testing.T) {
-	b := setup(NewBuffer("test", "", 10))
+	b := setup(NewMemoryBuffer("test", "", 10))
 	b.Add(MetricTime(1))
 	b.Add(MetricTime(2))
 	b.Add(MetricTime(3))
@@ -480,7 +480,7 @@ func TestBuffer_RejectAdjustFirst(t *testing.T) {
 
 func TestBuffer_AddDropsOverwrittenMetrics(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	b.Add(m, m, m, m, m)
@@ -491,16 +491,30 @@ func TestBuffer_AddDropsOverwrittenMetrics(t *testing.T) {
 
 func TestBuffer_AcceptRemovesBatch(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m)
 	batch := b.Batch(2)
 	b.Accept(batch)
 	require.Equal(t, 1, b.Len())
 }
 
+func TestBuffer_Partial(t *testing.T) {
+	b := setup(NewMemoryBuffer("test", "", 5))
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+
+	batch := b.Batch(4)
+	b.Partial(batch, []int{0}, map[int]string{2: DropReasonRejected})
+	require.Equal(t, 2, b.Len())
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(2), MetricTime(4)}, batch)
+}
+
 func TestBuffer_RejectLeavesBatch(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m)
 	batch := b.Batch(2)
 	b.Reject(batch)
@@ -509,7 +523,7 @@ func TestBuffer_RejectLeavesBatch(t *testing.T) {
 
 func TestBuffer_AcceptWritesOverwrittenBatch(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(5)
@@ -522,7 +536,7 @@ func TestBuffer_AcceptWritesOverwrittenBatch(t *testing.T) {
 
 func TestBuffer_BatchRejectDropsOverwrittenBatch(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(5)
@@ -535,7 +549,7 @@ func TestBuffer_BatchRejectDropsOverwrittenBatch(t *testing.T) {
 
 func TestBuffer_MetricsOverwriteBatchAccept(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(3)
@@ -547,7 +561,7 @@ func TestBuffer_MetricsOverwriteBatchAccept(t *testing.T) {
 
 func TestBuffer_MetricsOverwriteBatchReject(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(3)
@@ -559,7 +573,7 @@ func TestBuffer_MetricsOverwriteBatchReject(t *testing.T) {
 
 func TestBuffer_MetricsBatchAcceptRemoved(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(3)
@@ -571,7 +585,7 @@ func TestBuffer_MetricsBatchAcceptRemoved(t *testing.T) {
 
 func TestBuffer_WrapWithBatch(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 
 	b.Add(m, m, m)
 	b.Batch(3)
@@ -582,7 +596,7 @@ func TestBuffer_WrapWithBatch(t *testing.T) {
 
 func TestBuffer_BatchNotRemoved(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m, m, m)
 	b.Batch(2)
 	require.Equal(t, 5, b.Len())
@@ -590,7 +604,7 @@ func TestBuffer_BatchNotRemoved(t *testing.T) {
 
 func TestBuffer_BatchRejectAcceptNoop(t *testing.T) {
 	m := Metric()
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(m, m, m, m, m)
 	batch := b.Batch(2)
 	b.Reject(batch)
@@ -606,7 +620,7 @@ func TestBuffer_AcceptCallsMetricAccept(t *testing.T) {
 			accept++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(mm, mm, mm)
 	batch := b.Batch(2)
 	b.Accept(batch)
@@ -621,7 +635,7 @@ func TestBuffer_AddCallsMetricRejectWhenNoBatch(t *testing.T) {
 			reject++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	setup(b)
 	b.Add(mm, mm, mm, mm, mm)
 	b.Add(mm, mm)
@@ -636,7 +650,7 @@ func TestBuffer_AddCallsMetricRejectWhenNotInBatch(t *testing.T) {
 			reject++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	setup(b)
 	b.Add(mm, mm, mm, mm, mm)
 	batch := b.Batch(2)
@@ -654,7 +668,7 @@ func TestBuffer_RejectCallsMetricRejectWithOverwritten(t *testing.T) {
 			reject++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(mm, mm, mm, mm, mm)
 	batch := b.Batch(5)
 	b.Add(mm, mm)
@@ -671,7 +685,7 @@ func TestBuffer_AddOverwriteAndReject(t *testing.T) {
 			reject++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(mm, mm, mm, mm, mm)
 	batch := b.Batch(5)
 	b.Add(mm, mm, mm, mm, mm)
@@ -695,7 +709,7 @@ func TestBuffer_AddOverwriteAndRejectOffset(t *testing.T) {
 			accept++
 		},
 	}
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	b.Add(mm, mm, mm)
 	b.Add(mm, mm, mm, mm)
 	require.Equal(t, 2, reject)
@@ -714,7 +728,7 @@ func TestBuffer_AddOverwriteAndRejectOffset(t *testing.T) {
 }
 
 func TestBuffer_RejectEmptyBatch(t *testing.T) {
-	b := setup(NewBuffer("test", "", 5))
+	b := setup(NewMemoryBuffer("test", "", 5))
 	batch := b.Batch(2)
 	b.Add(MetricTime(1))
 	b.Reject(batch)
@@ -724,3 +738,24 @@ func TestBuffer_RejectEmptyBatch(t *testing.T) {
 		require.NotNil(t, m)
 	}
 }
+
+func TestBuffer_DropHandler(t *testing.T) {
+	b := setup(NewMemoryBuffer("test", "", 3))
+
+	var dropped []telegraf.Metric
+	var reasons []string
+	b.SetDropHandler(func(m telegraf.Metric, reason string) {
+		dropped = append(dropped, m)
+		reasons = append(reasons, reason)
+	})
+
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4))
+	batch := b.Batch(2)
+	b.Drop(batch, DropReasonRejected)
+
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(1), MetricTime(2), MetricTime(3)}, dropped)
+	require.Equal(t, []string{DropReasonOverflow, DropReasonRejected, DropReasonRejected}, reasons)
+	require.Equal(t, int64(3), b.MetricsDropped.Get())
+	require.Equal(t, int64(0), b.MetricsWritten.Get())
+	require.Equal(t, 1, b.Len())
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Tags added to the metrics sent to a dead-letter target
+const (
+	DeadLetterReasonTag = "dead_letter_reason"
+	DeadLetterSourceTag = "dead_letter_source"
+)
+
+const deadLetterQueueSize = 1000
+
+// DeadLetterConfig configures the target receiving the metrics dropped by an
+// output. Either Output or File must be set.
+type DeadLetterConfig struct {
+	// Output is the alias or ID of the output receiving the dropped metrics.
+	Output string
+
+	// File is the path of the file the dropped metrics are appended to using
+	// the given serializer.
+	File       string
+	Serializer telegraf.Serializer
+}
+
+// DeadLetterRouter delivers a dead-letter metric to the output with the given
+// alias or ID.
+type DeadLetterRouter func(target string, metric telegraf.Metric) error
+
+// deadLetter forwards the metrics dropped by an output to the configured
+// target. Metrics are queued to decouple the target from the buffer of the
+// dropping output.
+type deadLetter struct {
+	config *DeadLetterConfig
+	source string
+	log    telegraf.Logger
+
+	router DeadLetterRouter
+	file   *os.File
+
+	queue chan telegraf.Metric
+	done  chan struct{}
+
+	sync.Mutex
+	closed bool
+
+	MetricsSent    selfstat.Stat
+	MetricsDropped selfstat.Stat
+}
+
+func newDeadLetter(config *DeadLetterConfig, source string, log telegraf.Logger, tags map[string]string) *deadLetter {
+	return &deadLetter{
+		config: config,
+		source: source,
+		log:    log,
+		MetricsSent: selfstat.Register(
+			"write",
+			"dead_letter_sent",
+			tags,
+		),
+		MetricsDropped: selfstat.Register(
+			"write",
+			"dead_letter_dropped",
+			tags,
+		),
+	}
+}
+
+// start opens the target and starts forwarding the metrics.
+func (d *deadLetter) start() error {
+	if d.config.File != "" {
+		f, err := os.OpenFile(d.config.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			return fmt.Errorf("opening dead-letter file failed: %w", err)
+		}
+		d.file = f
+	}
+
+	d.queue = make(chan telegraf.Metric, deadLetterQueueSize)
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+		for m := range d.queue {
+			if err := d.send(m); err != nil {
+				d.log.Errorf("Sending metric to dead-letter target failed: %v", err)
+				d.MetricsDropped.Incr(1)
+				continue
+			}
+			d.MetricsSent.Incr(1)
+		}
+	}()
+
+	return nil
+}
+
+// add queues a copy of the given metric for the dead-letter target. Metrics
+// already being dead-letters are not forwarded again to avoid loops.
+func (d *deadLetter) add(m telegraf.Metric, reason string) {
+	if _, found := m.GetTag(DeadLetterReasonTag); found {
+		return
+	}
+
+	// Use an untracked copy as the original metric is rejected
+	dl := metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), m.Type())
+	dl.AddTag(DeadLetterReasonTag, reason)
+	dl.AddTag(DeadLetterSourceTag, d.source)
+
+	d.Lock()
+	defer d.Unlock()
+	if d.closed || d.queue == nil {
+		d.MetricsDropped.Incr(1)
+		return
+	}
+	select {
+	case d.queue <- dl:
+	default:
+		d.MetricsDropped.Incr(1)
+	}
+}
+
+// setRouter sets the function delivering metrics to the dead-letter output.
+func (d *deadLetter) setRouter(router DeadLetterRouter) {
+	d.Lock()
+	defer d.Unlock()
+	d.router = router
+}
+
+func (d *deadLetter) send(m telegraf.Metric) error {
+	if d.file != nil {
+		buf, err := d.config.Serializer.Serialize(m)
+		if err != nil {
+			return err
+		}
+		_, err = d.file.Write(buf)
+		return err
+	}
+
+	d.Lock()
+	router := d.router
+	d.Unlock()
+	if router == nil {
+		return errors.New("no router for dead-letter output")
+	}
+	return router(d.config.Output, m)
+}
+
+// close forwards the remaining metrics and closes the target.
+func (d *deadLetter) close() error {
+	d.Lock()
+	if d.closed || d.queue == nil {
+		d.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.Unlock()
+
+	<-d.done
+	if d.file != nil {
+		return d.file.Close()
+	}
+	return nil
+}
@@ -142,12 +142,7 @@ func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
 	}
 
 	if f.metricFilter != nil {
-		result, _, err := f.metricFilter.Eval(map[string]interface{}{
-			"name":   metric.Name(),
-			"tags":   metric.Tags(),
-			"fields": metric.Fields(),
-			"time":   metric.Time(),
-		})
+		result, _, err := f.metricFilter.Eval(MetricActivation(metric))
 		if err != nil {
 			return true, err
 		}
@@ -246,22 +241,7 @@ func (f *Filter) compileMetricFilter() error {
 	}
 
 	// Declare the computation environment for the filter including custom functions
-	env, err := cel.NewEnv(
-		cel.Declarations(
-			decls.NewVar("name", decls.String),
-			decls.NewVar("tags", decls.NewMapType(decls.String, decls.String)),
-			decls.NewVar("fields", decls.NewMapType(decls.String, decls.Dyn)),
-			decls.NewVar("time", decls.Timestamp),
-		),
-		cel.Function(
-			"now",
-			cel.Overload("now", nil, cel.TimestampType),
-			cel.SingletonFunctionBinding(func(_ ...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }),
-		),
-		ext.Encoders(),
-		ext.Math(),
-		ext.Strings(),
-	)
+	env, err := NewMetricEnvironment()
 	if err != nil {
 		return fmt.Errorf("creating environment failed: %w", err)
 	}
@@ -284,6 +264,39 @@ func (f *Filter) compileMetricFilter() error {
 	return err
 }
 
+// NewMetricEnvironment returns the CEL environment for evaluating expressions
+// on a metric with "name", "tags", "fields" and "time" bound to the metric.
+func NewMetricEnvironment(opts ...cel.EnvOption) (*cel.Env, error) {
+	options := []cel.EnvOption{
+		cel.Declarations(
+			decls.NewVar("name", decls.String),
+			decls.NewVar("tags", decls.NewMapType(decls.String, decls.String)),
+			decls.NewVar("fields", decls.NewMapType(decls.String, decls.Dyn)),
+			decls.NewVar("time", decls.Timestamp),
+		),
+		cel.Function(
+			"now",
+			cel.Overload("now", nil, cel.TimestampType),
+			cel.SingletonFunctionBinding(func(_ ...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }),
+		),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	}
+	return cel.NewEnv(append(options, opts...)...)
+}
+
+// MetricActivation returns the variables of the given metric for evaluating
+// programs of the environment returned by NewMetricEnvironment.
+func MetricActivation(metric telegraf.Metric) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   metric.Name(),
+		"tags":   metric.Tags(),
+		"fields": metric.Fields(),
+		"time":   metric.Time(),
+	}
+}
+
 func ShouldPassFilters(include filter.Filter, exclude filter.Filter, key string) bool {
 	if include != nil && exclude != nil {
 		return include.Match(key) && !exclude.Match(key)
@@ -1,10 +1,16 @@
 package models
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/fatih/color"
+	"github.com/influxdata/wlog"
+
 	"github.com/influxdata/telegraf"
 )
 
@@ -12,6 +18,49 @@ import (
 type Logger struct {
 	OnErrs []func()
 	Name   string // Name is the plugin name, will be printed in the `[]`.
+
+	// level overrides the global log-level if set
+	level wlog.Level
+}
+
+// logLevels are the valid log-levels for plugins. The "trace" and "none"
+// levels exist for compatibility with plugins using the option for
+// third-party libraries.
+var logLevels = map[string]wlog.Level{
+	"none":  wlog.OFF,
+	"error": wlog.ERROR,
+	"warn":  wlog.WARN,
+	"info":  wlog.INFO,
+	"debug": wlog.DEBUG,
+	"trace": wlog.DEBUG,
+}
+
+// unfilteredOutput receives the messages of plugins with a log-level more
+// verbose than the global log-level
+var unfilteredOutput struct {
+	sync.RWMutex
+	w io.Writer
+}
+
+// SetUnfilteredLogOutput sets the output for messages of plugins with a
+// log-level more verbose than the global log-level. The output must not
+// filter messages by their log-level. If the output is nil, the messages
+// are written to the standard logger and are subject to its filtering.
+func SetUnfilteredLogOutput(w io.Writer) {
+	unfilteredOutput.Lock()
+	defer unfilteredOutput.Unlock()
+	unfilteredOutput.w = w
+}
+
+// CheckLogLevel returns an error if the given plugin log-level is invalid.
+func CheckLogLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	if _, found := logLevels[level]; !found {
+		return fmt.Errorf("invalid log_level %q", level)
+	}
+	return nil
 }
 
 // NewLogger creates a new logger instance
@@ -21,6 +70,12 @@ func NewLogger(pluginType, name, alias string) *Logger {
 	}
 }
 
+// SetLogLevel overrides the global log-level for the logger. Empty or
+// invalid levels use the global log-level.
+func (l *Logger) SetLogLevel(level string) {
+	l.level = logLevels[level]
+}
+
 // OnErr defines a callback that triggers only when errors are about to be written to the log
 func (l *Logger) OnErr(f func()) {
 	l.OnErrs = append(l.OnErrs, f)
@@ -31,7 +86,7 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 	for _, f := range l.OnErrs {
 		f()
 	}
-	log.Printf("E! ["+l.Name+"] "+format, args...)
+	l.print(wlog.ERROR, fmt.Sprintf("E! ["+l.Name+"] "+format, args...))
 }
 
 // Error logs an error message, patterned after log.Print.
@@ -39,37 +94,65 @@ func (l *Logger) Error(args ...interface{}) {
 	for _, f := range l.OnErrs {
 		f()
 	}
-	log.Print(append([]interface{}{"E! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.ERROR, fmt.Sprint(append([]interface{}{"E! [" + l.Name + "] "}, args...)...))
 }
 
 // Debugf logs a debug message, patterned after log.Printf.
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	log.Printf("D! ["+l.Name+"] "+format, args...)
+	l.print(wlog.DEBUG, fmt.Sprintf("D! ["+l.Name+"] "+format, args...))
 }
 
 // Debug logs a debug message, patterned after log.Print.
 func (l *Logger) Debug(args ...interface{}) {
-	log.Print(append([]interface{}{"D! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.DEBUG, fmt.Sprint(append([]interface{}{"D! [" + l.Name + "] "}, args...)...))
 }
 
 // Warnf logs a warning message, patterned after log.Printf.
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	log.Printf("W! ["+l.Name+"] "+format, args...)
+	l.print(wlog.WARN, fmt.Sprintf("W! ["+l.Name+"] "+format, args...))
 }
 
 // Warn logs a warning message, patterned after log.Print.
 func (l *Logger) Warn(args ...interface{}) {
-	log.Print(append([]interface{}{"W! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.WARN, fmt.Sprint(append([]interface{}{"W! [" + l.Name + "] "}, args...)...))
 }
 
 // Infof logs an information message, patterned after log.Printf.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	log.Printf("I! ["+l.Name+"] "+format, args...)
+	l.print(wlog.INFO, fmt.Sprintf("I! ["+l.Name+"] "+format, args...))
 }
 
 // Info logs an information message, patterned after log.Print.
 func (l *Logger) Info(args ...interface{}) {
-	log.Print(append([]interface{}{"I! [" + l.Name + "] "}, args...)...)
+	l.print(wlog.INFO, fmt.Sprint(append([]interface{}{"I! [" + l.Name + "] "}, args...)...))
+}
+
+// print writes the message to the standard logger if the logger follows
+// the global log-level, otherwise it filters the message by the level of
+// the logger and bypasses the global filtering.
+func (l *Logger) print(level wlog.Level, msg string) {
+	if l.level == 0 {
+		log.Print(msg)
+		return
+	}
+	if level < l.level {
+		return
+	}
+	if level >= wlog.LogLevel() {
+		log.Print(msg)
+		return
+	}
+
+	unfilteredOutput.RLock()
+	defer unfilteredOutput.RUnlock()
+	if unfilteredOutput.w == nil {
+		log.Print(msg)
+		return
+	}
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	unfilteredOutput.w.Write([]byte(msg)) //nolint:errcheck // There is no way to report logging errors
 }
 
 // logName returns the log-friendly name/type.
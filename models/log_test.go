@@ -8,9 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/wlog"
+	"github.com/stretchr/testify/require"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/selfstat"
-	"github.com/stretchr/testify/require"
 )
 
 func TestErrorCounting(t *testing.T) {
@@ -29,6 +31,57 @@ func TestErrorCounting(t *testing.T) {
 	require.Equal(t, int64(2), reg.Get())
 }
 
+func TestLoggerLogLevel(t *testing.T) {
+	var filtered, unfiltered bytes.Buffer
+
+	previous := log.Writer()
+	previousFlags := log.Flags()
+	log.SetOutput(wlog.NewWriter(&filtered))
+	log.SetFlags(0)
+	SetUnfilteredLogOutput(&unfiltered)
+	wlog.SetLevel(wlog.INFO)
+	defer func() {
+		log.SetOutput(previous)
+		log.SetFlags(previousFlags)
+		SetUnfilteredLogOutput(nil)
+	}()
+
+	// Follow the global log-level
+	l := NewLogger("inputs", "test", "")
+	l.Debug("dropped")
+	l.Info("global")
+
+	// More verbose than the global log-level
+	l = NewLogger("inputs", "test", "debug")
+	l.SetLogLevel("debug")
+	l.Debugf("verbose %d", 1)
+	l.Info("global")
+
+	// Less verbose than the global log-level
+	l = NewLogger("inputs", "test", "error")
+	l.SetLogLevel("error")
+	l.Warn("dropped")
+	l.Errorf("global")
+
+	// Silenced
+	l = NewLogger("inputs", "test", "none")
+	l.SetLogLevel("none")
+	l.Error("dropped")
+
+	expected := "I! [inputs.test] global\n" +
+		"I! [inputs.test::debug] global\n" +
+		"E! [inputs.test::error] global\n"
+	require.Equal(t, expected, filtered.String())
+	require.Equal(t, "D! [inputs.test::debug] verbose 1\n", unfiltered.String())
+}
+
+func TestCheckLogLevel(t *testing.T) {
+	for _, level := range []string{"", "none", "error", "warn", "info", "debug", "trace"} {
+		require.NoError(t, CheckLogLevel(level))
+	}
+	require.EqualError(t, CheckLogLevel("verbose"), `invalid log_level "verbose"`)
+}
+
 func TestPluginDeprecation(t *testing.T) {
 	info := telegraf.DeprecationInfo{
 		Since:     "1.23.0",
@@ -0,0 +1,310 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Strategies for distributing the metrics of an output group
+const (
+	GroupStrategyFailover   = "failover"
+	GroupStrategyRoundRobin = "round_robin"
+	GroupStrategyHash       = "hash"
+)
+
+// OutputGroupConfig configures how an output group distributes metrics to
+// its outputs.
+type OutputGroupConfig struct {
+	Name     string
+	Strategy string
+
+	// FailoverAfter is the time the active output of a "failover" group must
+	// be failing before switching to the next output.
+	FailoverAfter time.Duration
+
+	// Weights are the relative shares of the outputs for the "hash" strategy.
+	Weights []int
+}
+
+// OutputGroup is an output writing to a set of outputs according to the
+// configured strategy. The group is wrapped in a single RunningOutput so all
+// outputs share one buffer and metrics are only removed from the buffer once
+// written to any of the outputs.
+type OutputGroup struct {
+	Config  *OutputGroupConfig
+	Outputs []*RunningOutput
+	Log     telegraf.Logger `toml:"-"`
+
+	connected []bool
+
+	// State of the "failover" strategy
+	active       int
+	failingSince time.Time
+	switched     time.Time
+
+	// State of the "round_robin" strategy
+	next int
+
+	// Cumulative weights of the "hash" strategy
+	shards []uint64
+
+	sync.Mutex
+}
+
+// NewOutputGroup creates a group writing to the given outputs.
+func NewOutputGroup(config *OutputGroupConfig, outputs []*RunningOutput) *OutputGroup {
+	g := &OutputGroup{
+		Config:    config,
+		Outputs:   outputs,
+		connected: make([]bool, len(outputs)),
+	}
+
+	var total uint64
+	g.shards = make([]uint64, 0, len(outputs))
+	for i := range outputs {
+		weight := 1
+		if i < len(config.Weights) && config.Weights[i] > 0 {
+			weight = config.Weights[i]
+		}
+		total += uint64(weight)
+		g.shards = append(g.shards, total)
+	}
+
+	return g
+}
+
+func (*OutputGroup) SampleConfig() string {
+	return ""
+}
+
+func (g *OutputGroup) Init() error {
+	for _, output := range g.Outputs {
+		if p, ok := output.Output.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("initializing %s failed: %w", output.LogName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Connect connects all outputs of the group. The group is usable as long as
+// one output connected, the others are connected again on write.
+func (g *OutputGroup) Connect() error {
+	g.Lock()
+	defer g.Unlock()
+
+	errs := make([]error, 0, len(g.Outputs))
+	for i := range g.Outputs {
+		if err := g.connect(i); err != nil {
+			g.Outputs[i].Log().Errorf("Connecting failed: %v", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(g.Outputs) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (g *OutputGroup) connect(i int) error {
+	if g.connected[i] {
+		return nil
+	}
+	if err := g.Outputs[i].Output.Connect(); err != nil {
+		return err
+	}
+	g.connected[i] = true
+	return nil
+}
+
+func (g *OutputGroup) Close() error {
+	g.Lock()
+	defer g.Unlock()
+
+	for i, output := range g.Outputs {
+		if !g.connected[i] {
+			continue
+		}
+		if err := output.Output.Close(); err != nil {
+			output.Log().Errorf("Error closing output: %v", err)
+		}
+		g.connected[i] = false
+	}
+	return nil
+}
+
+func (g *OutputGroup) Write(metrics []telegraf.Metric) error {
+	g.Lock()
+	defer g.Unlock()
+
+	switch g.Config.Strategy {
+	case GroupStrategyRoundRobin:
+		return g.writeRoundRobin(metrics)
+	case GroupStrategyHash:
+		return g.writeHash(metrics)
+	default:
+		return g.writeFailover(metrics)
+	}
+}
+
+// writeFailover writes to the active output and switches to the next output
+// if the active one fails for longer than the failover time. The primary
+// output is retried after each failover period.
+func (g *OutputGroup) writeFailover(metrics []telegraf.Metric) error {
+	now := time.Now()
+	if g.active != 0 && now.Sub(g.switched) >= g.Config.FailoverAfter {
+		g.switched = now
+		if err := g.writeTo(0, metrics); err == nil {
+			g.Log.Infof("Failing back to %s", g.Outputs[0].LogName())
+			g.active = 0
+			g.failingSince = time.Time{}
+			return nil
+		}
+	}
+
+	err := g.writeTo(g.active, metrics)
+	if isHandled(err) {
+		g.failingSince = time.Time{}
+		return err
+	}
+
+	if g.failingSince.IsZero() {
+		g.failingSince = now
+	}
+	if now.Sub(g.failingSince) < g.Config.FailoverAfter || len(g.Outputs) < 2 {
+		return err
+	}
+
+	failing := g.Outputs[g.active].LogName()
+	g.active = (g.active + 1) % len(g.Outputs)
+	g.failingSince = time.Time{}
+	g.switched = now
+	g.Log.Warnf("Failing over from %s to %s", failing, g.Outputs[g.active].LogName())
+
+	if err := g.writeTo(g.active, metrics); err != nil {
+		g.failingSince = now
+		return err
+	}
+	return nil
+}
+
+// writeRoundRobin writes each batch to the next output, trying the remaining
+// outputs on failure.
+func (g *OutputGroup) writeRoundRobin(metrics []telegraf.Metric) error {
+	start := g.next
+	g.next = (g.next + 1) % len(g.Outputs)
+	return g.writeFrom(start, metrics)
+}
+
+// writeHash shards the metrics across the outputs by the series hash with the
+// share of each output given by its weight. Failed shards are not written to
+// other outputs to keep each series on its output. If only some of the shards
+// are written, the result is a partial write so only the failed shards are
+// retried.
+func (g *OutputGroup) writeHash(metrics []telegraf.Metric) error {
+	total := g.shards[len(g.shards)-1]
+	shards := make([][]int, len(g.Outputs))
+	for idx, m := range metrics {
+		h := m.HashID() % total
+		for i, limit := range g.shards {
+			if h < limit {
+				shards[i] = append(shards[i], idx)
+				break
+			}
+		}
+	}
+
+	var errs []error
+	var accept, reject []int
+	for i, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+		batch := make([]telegraf.Metric, 0, len(shard))
+		for _, idx := range shard {
+			batch = append(batch, metrics[idx])
+		}
+
+		err := g.writeTo(i, batch)
+		if err == nil {
+			accept = append(accept, shard...)
+			continue
+		}
+		errs = append(errs, err)
+
+		// Map the indices of the shard to the indices of the whole batch
+		var partialErr *internal.PartialWriteError
+		switch {
+		case errors.As(err, &partialErr):
+			for _, idx := range partialErr.MetricsAccept {
+				accept = append(accept, shard[idx])
+			}
+			for _, idx := range partialErr.MetricsReject {
+				reject = append(reject, shard[idx])
+			}
+		case isRejected(err):
+			reject = append(reject, shard...)
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(accept) == 0 && len(reject) == 0:
+		return errors.Join(errs...)
+	}
+	return &internal.PartialWriteError{
+		Err:           errors.Join(errs...),
+		MetricsAccept: accept,
+		MetricsReject: reject,
+	}
+}
+
+// writeFrom writes the metrics to the output with the given index and to the
+// following outputs until one handles the metrics.
+func (g *OutputGroup) writeFrom(start int, metrics []telegraf.Metric) error {
+	errs := make([]error, 0, len(g.Outputs))
+	for i := range g.Outputs {
+		err := g.writeTo((start+i)%len(g.Outputs), metrics)
+		if isHandled(err) {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (g *OutputGroup) writeTo(i int, metrics []telegraf.Metric) error {
+	output := g.Outputs[i]
+	if err := g.connect(i); err != nil {
+		output.Log().Errorf("Connecting failed: %v", err)
+		return fmt.Errorf("%s: %w", output.LogName(), err)
+	}
+
+	start := time.Now()
+	err := output.Output.Write(metrics)
+	output.WriteTime.Incr(time.Since(start).Nanoseconds())
+	if err != nil {
+		output.Log().Errorf("Writing %d metrics failed: %v", len(metrics), err)
+		return fmt.Errorf("%s: %w", output.LogName(), err)
+	}
+	return nil
+}
+
+// isHandled returns true if the output wrote or permanently rejected at least
+// part of the metrics so the write must not be repeated on another output.
+func isHandled(err error) bool {
+	var partialErr *internal.PartialWriteError
+	return err == nil || errors.As(err, &partialErr) || isRejected(err)
+}
+
+func isRejected(err error) bool {
+	var rejectErr *internal.RejectError
+	return errors.As(err, &rejectErr)
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestOutputGroup(t *testing.T, config *OutputGroupConfig, outputs ...*mockOutput) *RunningOutput {
+	t.Helper()
+
+	members := make([]*RunningOutput, 0, len(outputs))
+	for _, output := range outputs {
+		members = append(members, NewRunningOutput(output, &OutputConfig{Name: "test"}, 0, 0))
+	}
+	group := NewOutputGroup(config, members)
+	ro := NewRunningOutput(group, &OutputConfig{Name: "group", Alias: config.Name}, 10, 100)
+	require.NoError(t, ro.Init())
+	require.NoError(t, ro.Connect())
+	t.Cleanup(ro.Close)
+
+	return ro
+}
+
+func TestOutputGroupFailover(t *testing.T) {
+	primary := &mockOutput{failWrite: true}
+	secondary := &mockOutput{}
+	ro := newTestOutputGroup(t, &OutputGroupConfig{
+		Name:          "test",
+		Strategy:      GroupStrategyFailover,
+		FailoverAfter: 100 * time.Millisecond,
+	}, primary, secondary)
+
+	// Metrics stay in the shared buffer while the primary is failing
+	for _, m := range first5 {
+		ro.AddMetric(m)
+	}
+	require.Error(t, ro.Write())
+	require.Equal(t, 5, ro.BufferLength())
+	require.Empty(t, secondary.Metrics())
+
+	// The secondary output takes over after the failover time
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	require.Len(t, secondary.Metrics(), 5)
+
+	// The primary output is used again once recovered
+	primary.Lock()
+	primary.failWrite = false
+	primary.Unlock()
+	for _, m := range next5 {
+		ro.AddMetric(m)
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ro.Write())
+	require.Len(t, primary.Metrics(), 5)
+	require.Len(t, secondary.Metrics(), 5)
+}
+
+func TestOutputGroupRoundRobin(t *testing.T) {
+	outputs := []*mockOutput{{}, {}, {failWrite: true}}
+	ro := newTestOutputGroup(t, &OutputGroupConfig{
+		Name:     "test",
+		Strategy: GroupStrategyRoundRobin,
+	}, outputs...)
+
+	for i := 0; i < 3; i++ {
+		ro.AddMetric(first5[i])
+		require.NoError(t, ro.Write())
+	}
+
+	// The batch of the failing output is written to the next output
+	require.Len(t, outputs[0].Metrics(), 2)
+	require.Len(t, outputs[1].Metrics(), 1)
+	require.Empty(t, outputs[2].Metrics())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{first5[0], first5[2]}, outputs[0].Metrics())
+}
+
+func TestOutputGroupHash(t *testing.T) {
+	outputs := []*mockOutput{{}, {}}
+	ro := newTestOutputGroup(t, &OutputGroupConfig{
+		Name:     "test",
+		Strategy: GroupStrategyHash,
+		Weights:  []int{1, 3},
+	}, outputs...)
+
+	var series []telegraf.Metric
+	for i := 0; i < 100; i++ {
+		m := testutil.TestMetric(i, "metric")
+		m.AddTag("series", string(rune('a'+i%26))+string(rune('a'+i/26)))
+		series = append(series, m)
+		ro.AddMetric(m)
+	}
+	require.NoError(t, ro.Write())
+
+	// Each series is always written to the same output
+	require.Len(t, append(outputs[0].Metrics(), outputs[1].Metrics()...), 100)
+	require.Less(t, len(outputs[0].Metrics()), len(outputs[1].Metrics()))
+	group := ro.Output.(*OutputGroup)
+	for _, m := range outputs[0].Metrics() {
+		require.Less(t, m.HashID()%group.shards[1], group.shards[0])
+	}
+	for _, m := range outputs[1].Metrics() {
+		require.GreaterOrEqual(t, m.HashID()%group.shards[1], group.shards[0])
+	}
+
+	// Shards of failing outputs are not written to the remaining outputs
+	written := len(outputs[0].Metrics())
+	outputs[1].Lock()
+	outputs[1].failWrite = true
+	outputs[1].Unlock()
+	for _, m := range series {
+		ro.AddMetric(m)
+	}
+	require.Error(t, ro.Write())
+	require.Greater(t, len(outputs[0].Metrics()), written)
+	for _, m := range outputs[0].Metrics() {
+		require.Less(t, m.HashID()%group.shards[1], group.shards[0])
+	}
+}
+
+func TestOutputGroupHashPartial(t *testing.T) {
+	healthy := &mockOutput{}
+	failing := &mockOutput{failWrite: true}
+	members := []*RunningOutput{
+		NewRunningOutput(healthy, &OutputConfig{Name: "test"}, 0, 0),
+		NewRunningOutput(failing, &OutputConfig{Name: "test"}, 0, 0),
+	}
+	group := NewOutputGroup(&OutputGroupConfig{Name: "test", Strategy: GroupStrategyHash}, members)
+	ro := NewRunningOutput(group, &OutputConfig{Name: "group", Alias: "test"}, 100, 100)
+	require.NoError(t, ro.Init())
+	require.NoError(t, ro.Connect())
+	t.Cleanup(ro.Close)
+
+	var series []telegraf.Metric
+	for i := 0; i < 20; i++ {
+		m := testutil.TestMetric(i, "metric")
+		m.AddTag("series", string(rune('a'+i)))
+		series = append(series, m)
+		ro.AddMetric(m)
+	}
+
+	// The shard of the failing output is kept in the buffer while the other
+	// shard is written only once
+	require.Error(t, ro.Write())
+	written := len(healthy.Metrics())
+	require.NotZero(t, written)
+	require.Equal(t, len(series)-written, ro.BufferLength())
+	require.Error(t, ro.Write())
+	require.Len(t, healthy.Metrics(), written)
+
+	// The failed shard is written to its own output after recovering
+	failing.Lock()
+	failing.failWrite = false
+	failing.Unlock()
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	all := append(healthy.Metrics(), failing.Metrics()...)
+	testutil.RequireMetricsEqual(t, series, all, testutil.SortMetrics())
+	for _, m := range failing.Metrics() {
+		require.GreaterOrEqual(t, m.HashID()%group.shards[1], group.shards[0])
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+// DefaultPipeline is the name referring to the processors, aggregators and
+// outputs defined outside of any pipeline.
+const DefaultPipeline = "default"
+
+// Pipelines returns the names of the pipelines fed by the input. The default
+// pipeline is denoted by an empty name.
+func (r *RunningInput) Pipelines() []string {
+	if len(r.Config.Pipelines) == 0 {
+		return []string{""}
+	}
+
+	pipelines := make([]string, 0, len(r.Config.Pipelines))
+	for _, name := range r.Config.Pipelines {
+		if name == DefaultPipeline {
+			name = ""
+		}
+		pipelines = append(pipelines, name)
+	}
+	return pipelines
+}
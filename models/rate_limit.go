@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/limiter"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Default time span of unused rate-limit budget an output can accumulate.
+const DefaultRateLimitBurst = time.Second
+
+// RateLimitConfig defines the maximum rate at which an output is written.
+type RateLimitConfig struct {
+	// MetricsPerSecond is the maximum number of metrics written per second.
+	// Zero disables the limit.
+	MetricsPerSecond int
+
+	// BytesPerSecond is the maximum number of serialized bytes written per
+	// second. Zero disables the limit.
+	BytesPerSecond int64
+
+	// Burst is the time span of unused budget accumulated while the output
+	// is idle, e.g. one minute for per-minute quotas.
+	Burst time.Duration
+
+	// Serializer determines the size of the metrics, defaults to influx line
+	// protocol if unset.
+	Serializer telegraf.Serializer
+}
+
+// rateLimiter restricts the batches of an output to the configured rates.
+type rateLimiter struct {
+	metrics    *limiter.TokenBucket
+	bytes      *limiter.TokenBucket
+	serializer telegraf.Serializer
+
+	// throttled is the last time the output was found without budget, zero
+	// if the output is not throttled
+	throttled time.Time
+}
+
+func newRateLimiter(cfg *RateLimitConfig) *rateLimiter {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = DefaultRateLimitBurst
+	}
+
+	l := &rateLimiter{serializer: cfg.Serializer}
+	if cfg.MetricsPerSecond > 0 {
+		l.metrics = limiter.NewTokenBucket(float64(cfg.MetricsPerSecond), burst)
+	}
+	if cfg.BytesPerSecond > 0 {
+		l.bytes = limiter.NewTokenBucket(float64(cfg.BytesPerSecond), burst)
+		if l.serializer == nil {
+			s := &influx.Serializer{}
+			_ = s.Init()
+			l.serializer = s
+		}
+	}
+	return l
+}
+
+// batchSize returns the number of metrics allowed to be written at the given
+// time limited to the given maximum.
+func (l *rateLimiter) batchSize(now time.Time, limit int) int {
+	if l.metrics == nil {
+		return limit
+	}
+	return min(int(l.metrics.Available(now)), limit)
+}
+
+// fit returns the number of leading metrics of the batch fitting into the
+// available bytes at the given time along with their size. A single metric
+// exceeding the budget is allowed if the budget is fully available.
+func (l *rateLimiter) fit(now time.Time, batch []telegraf.Metric) (n int, size int64) {
+	if l.bytes == nil {
+		return len(batch), 0
+	}
+
+	available := l.bytes.Available(now)
+	for _, m := range batch {
+		// Metrics failing to serialize are passed on to let the output
+		// report the error
+		buf, _ := l.serializer.Serialize(m)
+		if float64(size+int64(len(buf))) > available {
+			if n == 0 && l.bytes.Full(now) {
+				return 1, int64(len(buf))
+			}
+			break
+		}
+		size += int64(len(buf))
+		n++
+	}
+	return n, size
+}
+
+// take consumes the budget for writing the given number of metrics and bytes.
+func (l *rateLimiter) take(now time.Time, n int, size int64) {
+	if l.metrics != nil {
+		l.metrics.Take(now, float64(n))
+	}
+	if l.bytes != nil {
+		l.bytes.Take(now, float64(size))
+	}
+}
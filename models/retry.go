@@ -0,0 +1,111 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Default time the circuit breaker stays open before probing the output again.
+const DefaultCircuitBreakerTimeout = time.Minute
+
+// RetryConfig defines how an output retries failed writes.
+type RetryConfig struct {
+	// MaxAttempts is the number of failed writes of a batch after which the
+	// batch is dropped. Zero means retrying forever.
+	MaxAttempts int
+
+	// InitialBackoff is the delay after the first failed write, doubling with
+	// each subsequent failure up to MaxBackoff. Zero disables the backoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// CircuitBreakerThreshold is the number of consecutive failed writes after
+	// which the output is not written anymore for CircuitBreakerTimeout.
+	// Zero disables the circuit breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerTimeout   time.Duration
+}
+
+// retryPolicy keeps track of the failed writes of an output according to the
+// retry configuration.
+type retryPolicy struct {
+	config *RetryConfig
+
+	// attempts is the number of failed writes of the current batch
+	attempts int
+	// failures is the number of consecutive failed writes
+	failures int
+
+	next      time.Time
+	openUntil time.Time
+	open      bool
+}
+
+// allow returns true if the output can be written at the given time.
+func (p *retryPolicy) allow(now time.Time) bool {
+	if p.open && now.Before(p.openUntil) {
+		return false
+	}
+	return !now.Before(p.next)
+}
+
+// success resets the policy after a successful write and returns true if
+// the circuit breaker was open.
+func (p *retryPolicy) success() bool {
+	wasOpen := p.open
+	p.attempts = 0
+	p.failures = 0
+	p.next = time.Time{}
+	p.openUntil = time.Time{}
+	p.open = false
+	return wasOpen
+}
+
+// dropped resets the attempts after the current batch was dropped.
+func (p *retryPolicy) dropped() {
+	p.attempts = 0
+}
+
+// failure records a failed write at the given time. It returns true for
+// exhausted if the batch should be dropped and true for opened if the
+// circuit breaker opened due to this failure.
+func (p *retryPolicy) failure(now time.Time) (exhausted, opened bool) {
+	p.attempts++
+	p.failures++
+
+	if p.config.InitialBackoff > 0 {
+		p.next = now.Add(p.backoff())
+	}
+
+	if p.config.CircuitBreakerThreshold > 0 && p.failures >= p.config.CircuitBreakerThreshold {
+		timeout := p.config.CircuitBreakerTimeout
+		if timeout <= 0 {
+			timeout = DefaultCircuitBreakerTimeout
+		}
+		opened = !p.open
+		p.open = true
+		p.openUntil = now.Add(timeout)
+	}
+
+	exhausted = p.config.MaxAttempts > 0 && p.attempts >= p.config.MaxAttempts
+	return exhausted, opened
+}
+
+// backoff returns the randomized delay for the current number of consecutive
+// failures. The delay is chosen between half and the full exponential value
+// to spread the retries of multiple instances.
+func (p *retryPolicy) backoff() time.Duration {
+	delay := p.config.InitialBackoff
+	for i := 1; i < p.failures && delay < math.MaxInt64/2; i++ {
+		if p.config.MaxBackoff > 0 && delay >= p.config.MaxBackoff {
+			break
+		}
+		delay *= 2
+	}
+	if p.config.MaxBackoff > 0 && delay > p.config.MaxBackoff {
+		delay = p.config.MaxBackoff
+	}
+	return delay/2 + internal.RandomDuration(delay/2)
+}
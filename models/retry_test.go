@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &retryPolicy{config: &RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	}}
+
+	now := time.Now()
+	require.True(t, p.allow(now))
+
+	// The delay doubles with each failure up to the maximum
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		exhausted, opened := p.failure(now)
+		require.False(t, exhausted)
+		require.False(t, opened)
+		require.False(t, p.allow(now))
+		require.GreaterOrEqual(t, p.next.Sub(now), expected/2)
+		require.LessOrEqual(t, p.next.Sub(now), expected)
+		require.True(t, p.allow(now.Add(expected)))
+	}
+
+	// Success resets the backoff
+	require.False(t, p.success())
+	require.True(t, p.allow(now))
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	p := &retryPolicy{config: &RetryConfig{MaxAttempts: 3}}
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		exhausted, _ := p.failure(now)
+		require.False(t, exhausted)
+		require.True(t, p.allow(now))
+	}
+	exhausted, _ := p.failure(now)
+	require.True(t, exhausted)
+
+	// The next batch gets all attempts again
+	p.dropped()
+	exhausted, _ = p.failure(now)
+	require.False(t, exhausted)
+}
+
+func TestRetryPolicyCircuitBreaker(t *testing.T) {
+	p := &retryPolicy{config: &RetryConfig{
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerTimeout:   time.Minute,
+	}}
+
+	now := time.Now()
+	_, opened := p.failure(now)
+	require.False(t, opened)
+	require.True(t, p.allow(now))
+	_, opened = p.failure(now)
+	require.True(t, opened)
+	require.False(t, p.allow(now))
+	require.False(t, p.allow(now.Add(59*time.Second)))
+
+	// After the timeout a single write is probed and failures reopen the
+	// circuit immediately
+	now = now.Add(time.Minute)
+	require.True(t, p.allow(now))
+	_, opened = p.failure(now)
+	require.False(t, opened)
+	require.False(t, p.allow(now))
+
+	// A successful write closes the circuit
+	require.True(t, p.success())
+	require.True(t, p.allow(now))
+}
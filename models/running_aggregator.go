@@ -31,6 +31,7 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 
 	aggErrorsRegister := selfstat.Register("aggregate", "errors", tags)
 	logger := NewLogger("aggregators", config.Name, config.Alias)
+	logger.SetLogLevel(config.LogLevel)
 	logger.OnErr(func() {
 		aggErrorsRegister.Incr(1)
 	})
@@ -79,6 +80,13 @@ type AggregatorConfig struct {
 	MeasurementSuffix string
 	Tags              map[string]string
 	Filter            Filter
+
+	// Pipeline is the name of the pipeline the aggregator belongs to, empty
+	// for the default pipeline
+	Pipeline string
+
+	// LogLevel overrides the global log-level for the plugin if set
+	LogLevel string
 }
 
 func (r *RunningAggregator) LogName() string {
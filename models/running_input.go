@@ -1,9 +1,12 @@
 package models
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -20,9 +23,18 @@ type RunningInput struct {
 	log         telegraf.Logger
 	defaultTags map[string]string
 
+	// State of service inputs guarded by startLock as starting is retried in
+	// the gather loop. The accumulator passed to Start is kept to be used for
+	// retries instead of the rounding accumulator passed to Gather.
+	startLock sync.Mutex
+	started   bool
+	retries   uint64
+	startAcc  telegraf.Accumulator
+
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
+	StartupErrors   selfstat.Stat
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -33,6 +45,7 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 
 	inputErrorsRegister := selfstat.Register("gather", "errors", tags)
 	logger := NewLogger("inputs", config.Name, config.Alias)
+	logger.SetLogLevel(config.LogLevel)
 	logger.OnErr(func() {
 		inputErrorsRegister.Incr(1)
 		GlobalGatherErrors.Incr(1)
@@ -57,6 +70,11 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"gather_timeouts",
 			tags,
 		),
+		StartupErrors: selfstat.Register(
+			"gather",
+			"startup_errors",
+			tags,
+		),
 		log: logger,
 	}
 }
@@ -71,6 +89,15 @@ type InputConfig struct {
 	CollectionOffset time.Duration
 	Precision        time.Duration
 
+	StartupErrorBehavior string
+
+	// LogLevel overrides the global log-level for the plugin if set
+	LogLevel string
+
+	// Pipelines are the names of the pipelines the input feeds, the default
+	// pipeline is used if empty
+	Pipelines []string
+
 	NameOverride            string
 	MeasurementPrefix       string
 	MeasurementSuffix       string
@@ -98,6 +125,55 @@ func (r *RunningInput) Init() error {
 	return nil
 }
 
+// Start starts service inputs and handles startup errors according to the
+// configured startup-error behavior. Errors wrapping an internal.FatalError
+// signal that the input should be removed from further processing.
+func (r *RunningInput) Start(acc telegraf.Accumulator) error {
+	if plugin, ok := r.Input.(telegraf.ServiceInput); ok {
+		r.startLock.Lock()
+		r.retries++
+		r.startAcc = acc
+		err := plugin.Start(acc)
+		if err == nil {
+			r.started = true
+		}
+		r.startLock.Unlock()
+
+		if err != nil {
+			r.StartupErrors.Incr(1)
+			return handleStartupError(r.log, r.Config.StartupErrorBehavior, err)
+		}
+	}
+
+	if r.Config.StartupErrorBehavior == "probe" {
+		if err := r.Probe(); err != nil {
+			r.Stop()
+			return &internal.FatalError{Err: fmt.Errorf("probing failed: %w", err)}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops service inputs that were started successfully.
+func (r *RunningInput) Stop() {
+	r.startLock.Lock()
+	defer r.startLock.Unlock()
+
+	if plugin, ok := r.Input.(telegraf.ServiceInput); ok && (r.started || r.retries == 0) {
+		plugin.Stop()
+	}
+	r.started = false
+}
+
+// Probe checks the availability of the input if the plugin supports probing.
+func (r *RunningInput) Probe() error {
+	if p, ok := r.Input.(telegraf.ProbePlugin); ok {
+		return p.Probe()
+	}
+	return nil
+}
+
 func (r *RunningInput) ID() string {
 	if p, ok := r.Input.(telegraf.PluginWithID); ok {
 		return p.ID()
@@ -145,6 +221,10 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 }
 
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
+	if err := r.retryStart(); err != nil {
+		return err
+	}
+
 	start := time.Now()
 	err := r.Input.Gather(acc)
 	elapsed := time.Since(start)
@@ -152,6 +232,31 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	return err
 }
 
+// retryStart starts service inputs if a previous start failed.
+func (r *RunningInput) retryStart() error {
+	plugin, ok := r.Input.(telegraf.ServiceInput)
+	if !ok {
+		return nil
+	}
+
+	r.startLock.Lock()
+	defer r.startLock.Unlock()
+
+	if r.started || r.retries == 0 {
+		return nil
+	}
+
+	r.retries++
+	if err := plugin.Start(r.startAcc); err != nil {
+		r.StartupErrors.Incr(1)
+		return fmt.Errorf("%w: %w", internal.ErrNotConnected, err)
+	}
+	r.started = true
+	r.log.Infof("Successfully started after %d attempts", r.retries)
+
+	return nil
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
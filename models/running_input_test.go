@@ -1,12 +1,14 @@
 package models
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
@@ -433,3 +435,120 @@ type testInput struct{}
 func (t *testInput) Description() string                 { return "" }
 func (t *testInput) SampleConfig() string                { return "" }
 func (t *testInput) Gather(_ telegraf.Accumulator) error { return nil }
+
+func TestRunningInputStartupBehaviorError(t *testing.T) {
+	input := &mockServiceInput{startErr: errors.New("connection refused")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "error"})
+
+	err := ri.Start(&testutil.Accumulator{})
+	require.ErrorContains(t, err, "connection refused")
+	var fatalErr *internal.FatalError
+	require.False(t, errors.As(err, &fatalErr))
+}
+
+func TestRunningInputStartupBehaviorRetry(t *testing.T) {
+	input := &mockServiceInput{startErr: errors.New("connection refused")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "retry"})
+
+	var acc testutil.Accumulator
+	require.NoError(t, ri.Start(&acc))
+	require.Equal(t, 1, input.startCalls)
+
+	// Gathering should try to start the plugin and fail without gathering
+	err := ri.Gather(&acc)
+	require.ErrorIs(t, err, internal.ErrNotConnected)
+	require.Equal(t, 2, input.startCalls)
+	require.Zero(t, input.gatherCalls)
+
+	// Gathering should succeed after the plugin could be started
+	input.startErr = nil
+	require.NoError(t, ri.Gather(&acc))
+	require.Equal(t, 3, input.startCalls)
+	require.Equal(t, 1, input.gatherCalls)
+
+	require.NoError(t, ri.Gather(&acc))
+	require.Equal(t, 3, input.startCalls)
+	require.Equal(t, 2, input.gatherCalls)
+
+	ri.Stop()
+	require.Equal(t, 1, input.stopCalls)
+}
+
+func TestRunningInputStartupBehaviorRetryAccumulator(t *testing.T) {
+	input := &mockServiceInput{startErr: errors.New("connection refused")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "retry"})
+
+	// Retries must use the accumulator passed to Start and not the one used
+	// for gathering
+	var startAcc, gatherAcc testutil.Accumulator
+	require.NoError(t, ri.Start(&startAcc))
+
+	input.startErr = nil
+	require.NoError(t, ri.Gather(&gatherAcc))
+	require.Equal(t, 2, input.startCalls)
+	require.Same(t, &startAcc, input.startAcc)
+}
+
+func TestRunningInputStartupBehaviorRetryNeverStarted(t *testing.T) {
+	input := &mockServiceInput{startErr: errors.New("connection refused")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "retry"})
+
+	require.NoError(t, ri.Start(&testutil.Accumulator{}))
+	ri.Stop()
+	require.Zero(t, input.stopCalls)
+}
+
+func TestRunningInputStartupBehaviorIgnore(t *testing.T) {
+	input := &mockServiceInput{startErr: errors.New("connection refused")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "ignore"})
+
+	err := ri.Start(&testutil.Accumulator{})
+	var fatalErr *internal.FatalError
+	require.ErrorAs(t, err, &fatalErr)
+	require.ErrorContains(t, err, "connection refused")
+}
+
+func TestRunningInputStartupBehaviorProbe(t *testing.T) {
+	input := &mockServiceInput{probeErr: errors.New("resource unavailable")}
+	ri := NewRunningInput(input, &InputConfig{Name: "test", StartupErrorBehavior: "probe"})
+
+	err := ri.Start(&testutil.Accumulator{})
+	var fatalErr *internal.FatalError
+	require.ErrorAs(t, err, &fatalErr)
+	require.ErrorContains(t, err, "resource unavailable")
+	require.Equal(t, 1, input.stopCalls)
+
+	input.probeErr = nil
+	require.NoError(t, ri.Start(&testutil.Accumulator{}))
+}
+
+type mockServiceInput struct {
+	startErr error
+	probeErr error
+
+	startCalls  int
+	stopCalls   int
+	gatherCalls int
+	startAcc    telegraf.Accumulator
+}
+
+func (t *mockServiceInput) SampleConfig() string { return "" }
+
+func (t *mockServiceInput) Start(acc telegraf.Accumulator) error {
+	t.startCalls++
+	t.startAcc = acc
+	return t.startErr
+}
+
+func (t *mockServiceInput) Stop() {
+	t.stopCalls++
+}
+
+func (t *mockServiceInput) Probe() error {
+	return t.probeErr
+}
+
+func (t *mockServiceInput) Gather(_ telegraf.Accumulator) error {
+	t.gatherCalls++
+	return nil
+}
@@ -1,11 +1,15 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -29,6 +33,32 @@ type OutputConfig struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// BufferStrategy is either "memory" or "disk" and BufferDirectory is the
+	// base directory for persisting the buffer of the "disk" strategy.
+	// BufferName is the name of the output's subdirectory and must be unique
+	// and stable across configuration changes.
+	BufferStrategy  string
+	BufferDirectory string
+	BufferName      string
+
+	StartupErrorBehavior string
+
+	// DeadLetter is the target for metrics dropped by the output
+	DeadLetter *DeadLetterConfig
+
+	// Retry is the policy for failed writes, retrying on every flush if unset
+	Retry *RetryConfig
+
+	// RateLimit restricts the rate of written metrics, unlimited if unset
+	RateLimit *RateLimitConfig
+
+	// Pipeline is the name of the pipeline the output belongs to, empty for
+	// the default pipeline
+	Pipeline string
+
+	// LogLevel overrides the global log-level for the plugin if set
+	LogLevel string
+
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
@@ -45,13 +75,23 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
-	MetricsFiltered selfstat.Stat
-	WriteTime       selfstat.Stat
+	MetricsFiltered    selfstat.Stat
+	WriteTime          selfstat.Stat
+	StartupErrors      selfstat.Stat
+	WriteRetries       selfstat.Stat
+	CircuitBreakerOpen selfstat.Stat
+	ThrottledTime      selfstat.Stat
 
 	BatchReady chan time.Time
 
-	buffer *Buffer
-	log    telegraf.Logger
+	started bool
+	retries uint64
+
+	buffer     Buffer
+	deadLetter *deadLetter
+	retry      *retryPolicy
+	rateLimit  *rateLimiter
+	log        telegraf.Logger
 
 	aggMutex sync.Mutex
 }
@@ -69,6 +109,7 @@ func NewRunningOutput(
 
 	writeErrorsRegister := selfstat.Register("write", "errors", tags)
 	logger := NewLogger("outputs", config.Name, config.Alias)
+	logger.SetLogLevel(config.LogLevel)
 	logger.OnErr(func() {
 		writeErrorsRegister.Incr(1)
 	})
@@ -88,7 +129,7 @@ func NewRunningOutput(
 	}
 
 	ro := &RunningOutput{
-		buffer:            NewBuffer(config.Name, config.Alias, bufferLimit),
+		buffer:            NewMemoryBuffer(config.Name, config.Alias, bufferLimit),
 		BatchReady:        make(chan time.Time, 1),
 		Output:            output,
 		Config:            config,
@@ -104,8 +145,25 @@ func NewRunningOutput(
 			"write_time_ns",
 			tags,
 		),
+		StartupErrors: selfstat.Register(
+			"write",
+			"startup_errors",
+			tags,
+		),
 		log: logger,
 	}
+	if config.DeadLetter != nil {
+		ro.deadLetter = newDeadLetter(config.DeadLetter, ro.LogName(), logger, tags)
+	}
+	if config.Retry != nil {
+		ro.retry = &retryPolicy{config: config.Retry}
+		ro.WriteRetries = selfstat.Register("write", "retries", tags)
+		ro.CircuitBreakerOpen = selfstat.Register("write", "circuit_breaker_open", tags)
+	}
+	if config.RateLimit != nil {
+		ro.rateLimit = newRateLimiter(config.RateLimit)
+		ro.ThrottledTime = selfstat.Register("write", "throttled_time_ns", tags)
+	}
 
 	return ro
 }
@@ -120,6 +178,23 @@ func (r *RunningOutput) metricFiltered(metric telegraf.Metric) {
 }
 
 func (r *RunningOutput) Init() error {
+	// Persistent buffers are created here instead of in the constructor to
+	// avoid touching the disk when only loading the configuration.
+	if r.Config.BufferStrategy != "" && r.Config.BufferStrategy != "memory" {
+		buffer, err := NewBuffer(r.Config.Name, r.Config.Alias, r.MetricBufferLimit, r.Config.BufferStrategy, r.BufferPath())
+		if err != nil {
+			return fmt.Errorf("creating %q buffer failed: %w", r.Config.BufferStrategy, err)
+		}
+		r.buffer = buffer
+	}
+
+	if r.deadLetter != nil {
+		if err := r.deadLetter.start(); err != nil {
+			return err
+		}
+		r.buffer.SetDropHandler(r.deadLetter.add)
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -129,6 +204,76 @@ func (r *RunningOutput) Init() error {
 	return nil
 }
 
+// Connect connects the output and handles connection errors according to the
+// configured startup-error behavior. Errors wrapping an internal.FatalError
+// signal that the output should be removed from further processing.
+func (r *RunningOutput) Connect() error {
+	r.retries++
+	if err := r.Output.Connect(); err != nil {
+		r.StartupErrors.Incr(1)
+		return handleStartupError(r.log, r.Config.StartupErrorBehavior, err)
+	}
+	r.started = true
+
+	if r.Config.StartupErrorBehavior == "probe" {
+		if err := r.Probe(); err != nil {
+			r.Close()
+			return &internal.FatalError{Err: fmt.Errorf("probing failed: %w", err)}
+		}
+	}
+
+	return nil
+}
+
+// Probe checks the availability of the output if the plugin supports probing.
+func (r *RunningOutput) Probe() error {
+	if p, ok := r.Output.(telegraf.ProbePlugin); ok {
+		return p.Probe()
+	}
+	return nil
+}
+
+// reconnect retries to connect outputs that failed to connect on startup.
+func (r *RunningOutput) reconnect() error {
+	if r.started || r.retries == 0 {
+		return nil
+	}
+
+	r.retries++
+	if err := r.Output.Connect(); err != nil {
+		r.StartupErrors.Incr(1)
+		return fmt.Errorf("%w: %w", internal.ErrNotConnected, err)
+	}
+	r.started = true
+	r.log.Infof("Successfully connected after %d attempts", r.retries)
+	return nil
+}
+
+// SetDeadLetterRouter sets the function delivering dropped metrics to the
+// dead-letter output.
+func (r *RunningOutput) SetDeadLetterRouter(router DeadLetterRouter) {
+	if r.deadLetter != nil {
+		r.deadLetter.setRouter(router)
+	}
+}
+
+// BufferPath returns the directory of the output's "disk" buffer or an empty
+// string if the output does not persist its buffer.
+func (r *RunningOutput) BufferPath() string {
+	if r.Config.BufferStrategy != "disk" || r.Config.BufferDirectory == "" {
+		return ""
+	}
+
+	name := r.Config.BufferName
+	if name == "" {
+		name = r.Config.Name
+		if r.Config.Alias != "" {
+			name += "-" + r.Config.Alias
+		}
+	}
+	return filepath.Join(r.Config.BufferDirectory, name)
+}
+
 func (r *RunningOutput) ID() string {
 	if p, ok := r.Output.(telegraf.PluginWithID); ok {
 		return p.ID()
@@ -188,6 +333,17 @@ func (r *RunningOutput) AddMetric(metric telegraf.Metric) {
 // Write writes all metrics to the output, stopping when all have been sent on
 // or error.
 func (r *RunningOutput) Write() error {
+	return r.write(false)
+}
+
+// WriteFinal writes all metrics to the output like Write but ignores the
+// retry policy and the rate limit. It is used for the last flush when
+// stopping, as metrics kept in a memory buffer would be lost otherwise.
+func (r *RunningOutput) WriteFinal() error {
+	return r.write(true)
+}
+
+func (r *RunningOutput) write(final bool) error {
 	if output, ok := r.Output.(telegraf.AggregatingOutput); ok {
 		r.aggMutex.Lock()
 		metrics := output.Push()
@@ -198,48 +354,235 @@ func (r *RunningOutput) Write() error {
 
 	atomic.StoreInt64(&r.newMetricsCount, 0)
 
+	// Keep the metrics in the buffer until the output is connected
+	if err := r.reconnect(); err != nil {
+		return err
+	}
+	if !final && !r.writeAllowed() {
+		return nil
+	}
+
 	// Only process the metrics in the buffer now.  Metrics added while we are
 	// writing will be sent on the next call.
 	nBuffer := r.buffer.Len()
 	nBatches := nBuffer/r.MetricBatchSize + 1
 	for i := 0; i < nBatches; i++ {
-		batch := r.buffer.Batch(r.MetricBatchSize)
+		var batch []telegraf.Metric
+		if final {
+			batch = r.buffer.Batch(r.MetricBatchSize)
+		} else {
+			batch = r.nextBatch()
+		}
 		if len(batch) == 0 {
 			break
 		}
 
-		err := r.writeMetrics(batch)
-		if err != nil {
-			r.buffer.Reject(batch)
+		if err := r.writeDone(batch, r.writeMetrics(batch)); err != nil {
 			return err
 		}
-		r.buffer.Accept(batch)
 	}
 	return nil
 }
 
 // WriteBatch writes a single batch of metrics to the output.
 func (r *RunningOutput) WriteBatch() error {
-	batch := r.buffer.Batch(r.MetricBatchSize)
+	if err := r.reconnect(); err != nil {
+		return err
+	}
+	if !r.writeAllowed() {
+		return nil
+	}
+
+	batch := r.nextBatch()
 	if len(batch) == 0 {
 		return nil
 	}
 
-	err := r.writeMetrics(batch)
+	return r.writeDone(batch, r.writeMetrics(batch))
+}
+
+// writeDone updates the buffer and the retry policy with the result of
+// writing the batch. An error is returned if metrics of the batch are kept
+// for retrying.
+func (r *RunningOutput) writeDone(batch []telegraf.Metric, err error) error {
+	var partialErr *internal.PartialWriteError
+	if errors.As(err, &partialErr) {
+		return r.writePartial(batch, partialErr)
+	}
+	if r.rejected(batch, err) {
+		return nil
+	}
 	if err != nil {
-		r.buffer.Reject(batch)
-		return err
+		return r.writeFailed(batch, err)
+	}
+	r.writeSucceeded(batch)
+	return nil
+}
+
+// writeAllowed returns false if the retry policy delays writing the output
+// due to a backoff or an open circuit breaker.
+func (r *RunningOutput) writeAllowed() bool {
+	if r.retry == nil || r.retry.allow(time.Now()) {
+		return true
+	}
+	r.log.Debug("Delaying write due to retry policy")
+	return false
+}
+
+// nextBatch returns the next batch from the buffer restricted to the rate
+// limit. The batch is empty if the buffer is empty or the output is throttled,
+// in which case the metrics are kept in the buffer.
+func (r *RunningOutput) nextBatch() []telegraf.Metric {
+	if r.rateLimit == nil {
+		return r.buffer.Batch(r.MetricBatchSize)
+	}
+	if r.buffer.Len() == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var batch []telegraf.Metric
+	var size int64
+	if n := r.rateLimit.batchSize(now, r.MetricBatchSize); n > 0 {
+		batch = r.buffer.Batch(n)
+
+		// Shrink the batch to the metrics fitting into the byte budget
+		var fitting int
+		fitting, size = r.rateLimit.fit(now, batch)
+		if fitting < len(batch) {
+			r.buffer.Reject(batch)
+			batch = nil
+			if fitting > 0 {
+				batch = r.buffer.Batch(fitting)
+			}
+		}
+	}
+
+	// Account the time spent without budget
+	if !r.rateLimit.throttled.IsZero() {
+		r.ThrottledTime.Incr(now.Sub(r.rateLimit.throttled).Nanoseconds())
+		r.rateLimit.throttled = time.Time{}
 	}
+	if len(batch) == 0 {
+		r.rateLimit.throttled = now
+		r.log.Debug("Delaying write due to rate limit")
+		return nil
+	}
+
+	r.rateLimit.take(now, len(batch), size)
+	return batch
+}
+
+// writeSucceeded accepts the written batch and resets the retry policy.
+func (r *RunningOutput) writeSucceeded(batch []telegraf.Metric) {
 	r.buffer.Accept(batch)
+	r.retrySucceeded()
+}
 
-	return nil
+func (r *RunningOutput) retrySucceeded() {
+	if r.retry != nil && r.retry.success() {
+		r.CircuitBreakerOpen.Set(0)
+		r.log.Info("Circuit breaker closed after successful write")
+	}
+}
+
+// writeFailed returns the batch to the buffer for retrying or drops it if the
+// retry policy is exhausted.
+func (r *RunningOutput) writeFailed(batch []telegraf.Metric, err error) error {
+	if r.retryFailed() {
+		r.buffer.Drop(batch, DropReasonRetries)
+		return fmt.Errorf("dropped %d metrics after %d failed attempts: %w", len(batch), r.Config.Retry.MaxAttempts, err)
+	}
+	r.buffer.Reject(batch)
+	return err
+}
+
+// writePartial accepts the written and drops the rejected metrics of a
+// partially written batch. The remaining metrics are returned to the buffer
+// for retrying or dropped if the retry policy is exhausted.
+func (r *RunningOutput) writePartial(batch []telegraf.Metric, partialErr *internal.PartialWriteError) error {
+	drop := make(map[int]string, len(batch)-len(partialErr.MetricsAccept))
+	for _, i := range partialErr.MetricsReject {
+		drop[i] = DropReasonRejected
+	}
+	if len(partialErr.MetricsReject) > 0 {
+		r.log.Errorf("Dropping %d metrics rejected by output: %v", len(partialErr.MetricsReject), partialErr.Err)
+	}
+
+	remaining := len(batch) - len(partialErr.MetricsAccept) - len(partialErr.MetricsReject)
+	if remaining <= 0 {
+		r.buffer.Partial(batch, partialErr.MetricsAccept, drop)
+		r.retrySucceeded()
+		return nil
+	}
+
+	if r.retryFailed() {
+		accepted := make(map[int]bool, len(partialErr.MetricsAccept))
+		for _, i := range partialErr.MetricsAccept {
+			accepted[i] = true
+		}
+		for i := range batch {
+			if _, found := drop[i]; !found && !accepted[i] {
+				drop[i] = DropReasonRetries
+			}
+		}
+		r.buffer.Partial(batch, partialErr.MetricsAccept, drop)
+		return fmt.Errorf("dropped %d metrics after %d failed attempts: %w", remaining, r.Config.Retry.MaxAttempts, partialErr.Err)
+	}
+	r.buffer.Partial(batch, partialErr.MetricsAccept, drop)
+	return partialErr.Err
+}
+
+// retryFailed records a failed write in the retry policy and returns true if
+// the failed metrics should be dropped.
+func (r *RunningOutput) retryFailed() bool {
+	if r.retry == nil {
+		return false
+	}
+
+	exhausted, opened := r.retry.failure(time.Now())
+	if opened {
+		r.CircuitBreakerOpen.Set(1)
+		r.log.Warnf("Circuit breaker opened after %d consecutive failed writes", r.retry.failures)
+	}
+	if exhausted {
+		r.retry.dropped()
+		return true
+	}
+	r.WriteRetries.Incr(1)
+	return false
+}
+
+// rejected drops the batch from the buffer if the output permanently refused
+// to write it and returns true in this case.
+func (r *RunningOutput) rejected(batch []telegraf.Metric, err error) bool {
+	var rejectErr *internal.RejectError
+	if !errors.As(err, &rejectErr) {
+		return false
+	}
+	r.log.Errorf("Dropping %d metrics rejected by output: %v", len(batch), err)
+	r.buffer.Drop(batch, DropReasonRejected)
+	return true
 }
 
 // Close closes the output
 func (r *RunningOutput) Close() {
-	err := r.Output.Close()
-	if err != nil {
-		r.log.Errorf("Error closing output: %v", err)
+	// Outputs that never connected successfully are not closed
+	if r.started || r.retries == 0 {
+		if err := r.Output.Close(); err != nil {
+			r.log.Errorf("Error closing output: %v", err)
+		}
+	}
+	r.started = false
+
+	if err := r.buffer.Close(); err != nil {
+		r.log.Errorf("Error closing output buffer: %v", err)
+	}
+
+	if r.deadLetter != nil {
+		if err := r.deadLetter.close(); err != nil {
+			r.log.Errorf("Error closing dead-letter target: %v", err)
+		}
 	}
 }
 
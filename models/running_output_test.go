@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -9,6 +11,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -318,6 +322,289 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+func TestRunningOutputDiskBufferRestart(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:          Filter{},
+		ID:              "test",
+		BufferStrategy:  "disk",
+		BufferDirectory: t.TempDir(),
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput(m, conf, 4, 12)
+	require.NoError(t, ro.Init())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	ro.Close()
+
+	// Metrics should survive the restart of the output
+	m.failWrite = false
+	ro = NewRunningOutput(m, conf, 4, 12)
+	require.NoError(t, ro.Init())
+	require.Equal(t, 5, ro.BufferLength())
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 5)
+	require.Equal(t, 0, ro.BufferLength())
+	ro.Close()
+}
+
+func TestRunningOutputDiskBufferInitError(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:         Filter{},
+		BufferStrategy: "disk",
+	}
+
+	ro := NewRunningOutput(&mockOutput{}, conf, 4, 12)
+	require.ErrorContains(t, ro.Init(), "no directory given")
+}
+
+func TestRunningOutputDeadLetterFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dead_letter.out")
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	conf := &OutputConfig{
+		Name:   "test",
+		Filter: Filter{},
+		DeadLetter: &DeadLetterConfig{
+			File:       filename,
+			Serializer: serializer,
+		},
+	}
+
+	m := &mockOutput{rejectWrite: true}
+	ro := NewRunningOutput(m, conf, 2, 3)
+	require.NoError(t, ro.Init())
+
+	// Overflow the buffer and reject the remaining metrics
+	for _, metric := range first5[:4] {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	require.Empty(t, m.Metrics())
+	ro.Close()
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	expected := "metric1,dead_letter_reason=buffer_overflow,dead_letter_source=outputs.test,tag1=value1 value=101i 1257894000000000000\n" +
+		"metric2,dead_letter_reason=rejected,dead_letter_source=outputs.test,tag1=value1 value=101i 1257894000000000000\n" +
+		"metric3,dead_letter_reason=rejected,dead_letter_source=outputs.test,tag1=value1 value=101i 1257894000000000000\n" +
+		"metric4,dead_letter_reason=rejected,dead_letter_source=outputs.test,tag1=value1 value=101i 1257894000000000000\n"
+	require.Equal(t, expected, string(buf))
+}
+
+func TestRunningOutputDeadLetterOutput(t *testing.T) {
+	conf := &OutputConfig{
+		Name:       "test",
+		Filter:     Filter{},
+		DeadLetter: &DeadLetterConfig{Output: "backup"},
+	}
+
+	var mu sync.Mutex
+	var received []telegraf.Metric
+	m := &mockOutput{rejectWrite: true}
+	ro := NewRunningOutput(m, conf, 10, 10)
+	ro.SetDeadLetterRouter(func(target string, metric telegraf.Metric) error {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, "backup", target)
+		received = append(received, metric)
+		return nil
+	})
+	require.NoError(t, ro.Init())
+
+	// Metrics already being dead-letters must not be forwarded again
+	dl := testutil.TestMetric(101, "metric2")
+	dl.AddTag(DeadLetterReasonTag, DropReasonRejected)
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	ro.AddMetric(dl)
+	require.NoError(t, ro.Write())
+	ro.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	require.Equal(t, "metric1", received[0].Name())
+	reason, _ := received[0].GetTag(DeadLetterReasonTag)
+	require.Equal(t, DropReasonRejected, reason)
+	source, _ := received[0].GetTag(DeadLetterSourceTag)
+	require.Equal(t, "outputs.test", source)
+}
+
+func TestRunningOutputRetryMaxAttempts(t *testing.T) {
+	conf := &OutputConfig{
+		Name:   "test",
+		Filter: Filter{},
+		Retry:  &RetryConfig{MaxAttempts: 2},
+	}
+
+	m := &mockOutput{failWrite: true}
+	ro := NewRunningOutput(m, conf, 4, 12)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// The first batch is kept on the first failure and dropped on the second
+	require.Error(t, ro.Write())
+	require.Equal(t, 5, ro.BufferLength())
+	require.Equal(t, int64(1), ro.WriteRetries.Get())
+	require.ErrorContains(t, ro.Write(), "dropped 4 metrics after 2 failed attempts")
+	require.Equal(t, 1, ro.BufferLength())
+
+	// Remaining metrics are written after recovery
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	require.Len(t, m.Metrics(), 1)
+}
+
+func TestRunningOutputRetryCircuitBreaker(t *testing.T) {
+	conf := &OutputConfig{
+		Name:   "test",
+		Filter: Filter{},
+		Retry: &RetryConfig{
+			CircuitBreakerThreshold: 2,
+			CircuitBreakerTimeout:   100 * time.Millisecond,
+		},
+	}
+
+	m := &mockOutput{failWrite: true}
+	ro := NewRunningOutput(m, conf, 10, 10)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	require.Equal(t, int64(1), ro.CircuitBreakerOpen.Get())
+
+	// The output is not written while the circuit is open
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.NoError(t, ro.WriteBatch())
+	require.Empty(t, m.Metrics())
+	require.Equal(t, 5, ro.BufferLength())
+
+	// The circuit closes on the first successful write after the timeout
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 5)
+	require.Equal(t, int64(0), ro.CircuitBreakerOpen.Get())
+}
+
+func TestRunningOutputRateLimitMetrics(t *testing.T) {
+	conf := &OutputConfig{
+		Name:      "test",
+		Filter:    Filter{},
+		RateLimit: &RateLimitConfig{MetricsPerSecond: 10, Burst: 200 * time.Millisecond},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 10, 10)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// Only the burst is written and the remaining metrics are kept
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 2)
+	require.Equal(t, 3, ro.BufferLength())
+	require.NoError(t, ro.WriteBatch())
+	require.Len(t, m.Metrics(), 2)
+
+	// The budget is refilled over time
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 4)
+	require.Equal(t, 1, ro.BufferLength())
+	require.GreaterOrEqual(t, ro.ThrottledTime.Get(), (200 * time.Millisecond).Nanoseconds())
+}
+
+func TestRunningOutputRateLimitBytes(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	buf, err := serializer.Serialize(first5[0])
+	require.NoError(t, err)
+
+	conf := &OutputConfig{
+		Name:      "test",
+		Filter:    Filter{},
+		RateLimit: &RateLimitConfig{BytesPerSecond: int64(3*len(buf) - 1)},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 10, 10)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// The batch is shrunk to the metrics fitting into the budget
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 2)
+	require.Equal(t, 3, ro.BufferLength())
+	testutil.RequireMetricsEqual(t, first5[:2], m.Metrics())
+}
+
+func TestRunningOutputRateLimitOversized(t *testing.T) {
+	conf := &OutputConfig{
+		Name:      "test",
+		Filter:    Filter{},
+		RateLimit: &RateLimitConfig{BytesPerSecond: 1},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 10, 10)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// A metric larger than the budget is written if the budget is unused
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+	require.Equal(t, 4, ro.BufferLength())
+}
+
+func TestRunningOutputWriteFinal(t *testing.T) {
+	conf := &OutputConfig{
+		Name:   "test",
+		Filter: Filter{},
+		Retry: &RetryConfig{
+			CircuitBreakerThreshold: 1,
+			CircuitBreakerTimeout:   time.Hour,
+		},
+		RateLimit: &RateLimitConfig{MetricsPerSecond: 1, Burst: time.Second},
+	}
+
+	m := &mockOutput{failWrite: true}
+	ro := NewRunningOutput(m, conf, 2, 10)
+	require.NoError(t, ro.Init())
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	require.Equal(t, int64(1), ro.CircuitBreakerOpen.Get())
+
+	// The final flush ignores the open circuit and the rate limit
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.Empty(t, m.Metrics())
+	require.NoError(t, ro.WriteFinal())
+	require.Len(t, m.Metrics(), 5)
+	require.Equal(t, 0, ro.BufferLength())
+}
+
 // Verify that the order of points is preserved during write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -486,6 +773,7 @@ func TestInternalMetrics(t *testing.T) {
 				"metrics_dropped":  0,
 				"metrics_filtered": 0,
 				"metrics_written":  0,
+				"startup_errors":   0,
 				"write_time_ns":    0,
 			},
 			time.Unix(0, 0),
@@ -503,6 +791,28 @@ func TestInternalMetrics(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 }
 
+func TestRunningOutputPartialWrite(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{partialWrite: true}
+	ro := NewRunningOutput(m, conf, 4, 10)
+	for _, metric := range first5[:4] {
+		ro.AddMetric(metric)
+	}
+
+	// Only the failed metrics must be retried and rejected ones dropped
+	require.Error(t, ro.Write())
+	require.Equal(t, 1, ro.BufferLength())
+	require.Len(t, m.Metrics(), 2)
+
+	m.partialWrite = false
+	require.NoError(t, ro.Write())
+	require.Zero(t, ro.BufferLength())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{first5[0], first5[2], first5[3]}, m.Metrics())
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -510,6 +820,13 @@ type mockOutput struct {
 
 	// if true, mock write failure
 	failWrite bool
+
+	// if true, mock a permanent write failure
+	rejectWrite bool
+
+	// if true, write the first and third metric, reject the second and fail
+	// the remaining ones
+	partialWrite bool
 }
 
 func (m *mockOutput) Connect() error {
@@ -534,6 +851,17 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	if m.failWrite {
 		return errors.New("failed write")
 	}
+	if m.rejectWrite {
+		return &internal.RejectError{Err: errors.New("rejected write")}
+	}
+	if m.partialWrite && len(metrics) > 2 {
+		m.metrics = append(m.metrics, metrics[0], metrics[2])
+		return &internal.PartialWriteError{
+			Err:           errors.New("partial write"),
+			MetricsAccept: []int{0, 2},
+			MetricsReject: []int{1},
+		}
+	}
 
 	if m.metrics == nil {
 		m.metrics = []telegraf.Metric{}
@@ -576,3 +904,89 @@ func (m *perfOutput) Write(_ []telegraf.Metric) error {
 	}
 	return nil
 }
+
+func TestRunningOutputStartupBehaviorRetry(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:               Filter{},
+		StartupErrorBehavior: "retry",
+	}
+
+	m := &mockConnectOutput{connectErr: errors.New("connection refused")}
+	ro := NewRunningOutput(m, conf, 4, 12)
+	require.NoError(t, ro.Connect())
+	require.Equal(t, 1, m.connectCalls)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// Writing should try to reconnect and keep the metrics buffered
+	require.ErrorIs(t, ro.Write(), internal.ErrNotConnected)
+	require.Equal(t, 2, m.connectCalls)
+	require.Equal(t, 5, ro.BufferLength())
+	require.Empty(t, m.Metrics())
+
+	m.connectErr = nil
+	require.NoError(t, ro.Write())
+	require.Equal(t, 3, m.connectCalls)
+	require.Len(t, m.Metrics(), 5)
+
+	require.NoError(t, ro.Write())
+	require.Equal(t, 3, m.connectCalls)
+}
+
+func TestRunningOutputStartupBehaviorIgnore(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:               Filter{},
+		StartupErrorBehavior: "ignore",
+	}
+
+	m := &mockConnectOutput{connectErr: errors.New("connection refused")}
+	ro := NewRunningOutput(m, conf, 4, 12)
+	err := ro.Connect()
+	var fatalErr *internal.FatalError
+	require.ErrorAs(t, err, &fatalErr)
+
+	// Outputs failing to connect must not be closed
+	ro.Close()
+	require.Zero(t, m.closeCalls)
+}
+
+func TestRunningOutputStartupBehaviorProbe(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:               Filter{},
+		StartupErrorBehavior: "probe",
+	}
+
+	m := &mockConnectOutput{probeErr: errors.New("no permission")}
+	ro := NewRunningOutput(m, conf, 4, 12)
+	err := ro.Connect()
+	var fatalErr *internal.FatalError
+	require.ErrorAs(t, err, &fatalErr)
+	require.ErrorContains(t, err, "no permission")
+	require.Equal(t, 1, m.closeCalls)
+}
+
+type mockConnectOutput struct {
+	mockOutput
+
+	connectErr error
+	probeErr   error
+
+	connectCalls int
+	closeCalls   int
+}
+
+func (m *mockConnectOutput) Connect() error {
+	m.connectCalls++
+	return m.connectErr
+}
+
+func (m *mockConnectOutput) Probe() error {
+	return m.probeErr
+}
+
+func (m *mockConnectOutput) Close() error {
+	m.closeCalls++
+	return nil
+}
@@ -27,6 +27,13 @@ type ProcessorConfig struct {
 	ID     string
 	Order  int64
 	Filter Filter
+
+	// Pipeline is the name of the pipeline the processor belongs to, empty
+	// for the default pipeline
+	Pipeline string
+
+	// LogLevel overrides the global log-level for the plugin if set
+	LogLevel string
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -37,6 +44,7 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 
 	processErrorsRegister := selfstat.Register("process", "errors", tags)
 	logger := NewLogger("processors", config.Name, config.Alias)
+	logger.SetLogLevel(config.LogLevel)
 	logger.OnErr(func() {
 		processErrorsRegister.Incr(1)
 	})
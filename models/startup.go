@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// StartupErrorBehaviors lists the valid settings for the
+// 'startup_error_behavior' option of inputs and outputs.
+var StartupErrorBehaviors = []string{"error", "retry", "ignore", "probe"}
+
+// handleStartupError processes an error that occurred when starting or
+// connecting a plugin according to the given startup-error behavior.
+func handleStartupError(log telegraf.Logger, behavior string, err error) error {
+	switch behavior {
+	case "", "error":
+		return err
+	case "retry":
+		log.Infof("Startup failed: %v; retrying...", err)
+		return nil
+	case "ignore", "probe":
+		return &internal.FatalError{Err: err}
+	}
+	return fmt.Errorf("invalid 'startup_error_behavior' setting %q: %w", behavior, err)
+}
@@ -1,14 +1,36 @@
 package persister
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 
 	"github.com/influxdata/telegraf"
 )
 
+// Version of the state file format written by the persister
+const fileVersion = 1
+
+// stateFile is the envelope of the state file containing the serialized
+// states of all plugins and a checksum to detect corrupted files.
+type stateFile struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	States   json.RawMessage `json:"states"`
+}
+
+// stateEntry is the serialized state of a single plugin together with the
+// version of the plugin's state layout.
+type stateEntry struct {
+	Version int             `json:"version,omitempty"`
+	State   json.RawMessage `json:"state"`
+}
+
 type Persister struct {
 	Filename string
 
@@ -30,6 +52,10 @@ func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
 	return nil
 }
 
+func (p *Persister) Unregister(id string) {
+	delete(p.register, id)
+}
+
 func (p *Persister) Load() error {
 	// Read the states from disk
 	in, err := os.ReadFile(p.Filename)
@@ -37,28 +63,22 @@ func (p *Persister) Load() error {
 		return fmt.Errorf("reading states file failed: %w", err)
 	}
 
-	// Unmarshal the id to serialized states map
-	var states map[string][]byte
-	if err := json.Unmarshal(in, &states); err != nil {
-		return fmt.Errorf("unmarshalling states failed: %w", err)
+	states, err := decode(in)
+	if err != nil {
+		return err
 	}
 
-	// Get the initialized state as blueprint for unmarshalling
-	for id, serialized := range states {
+	for id, entry := range states {
 		// Check if we have a plugin with that ID
 		plugin, found := p.register[id]
 		if !found {
 			continue
 		}
 
-		// Create a new empty state of the "state"-type. As we need a pointer
-		// of the state, we cannot dereference it here due to the unknown
-		// nature of the state-type.
-		nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
-		if err := json.Unmarshal(serialized, &nstate); err != nil {
-			return fmt.Errorf("unmarshalling state for %q failed: %w", id, err)
+		state, err := restore(plugin, entry)
+		if err != nil {
+			return fmt.Errorf("restoring state for %q failed: %w", id, err)
 		}
-		state := reflect.ValueOf(nstate).Elem().Interface()
 
 		// Set the state in the plugin
 		if err := plugin.SetState(state); err != nil {
@@ -70,7 +90,7 @@ func (p *Persister) Load() error {
 }
 
 func (p *Persister) Store() error {
-	states := make(map[string][]byte)
+	states := make(map[string]stateEntry, len(p.register))
 
 	// Collect the states and serialize the individual data chunks
 	// to later serialize all items in the id / serialized-states map
@@ -79,25 +99,128 @@ func (p *Persister) Store() error {
 		if err != nil {
 			return fmt.Errorf("marshalling state for id %q failed: %w", id, err)
 		}
-		states[id] = state
+		states[id] = stateEntry{Version: stateVersion(plugin), State: state}
 	}
 
-	// Serialize the states
-	serialized, err := json.Marshal(states)
+	// Serialize the states and wrap them into the versioned envelope
+	serializedStates, err := json.Marshal(states)
 	if err != nil {
 		return fmt.Errorf("marshalling states failed: %w", err)
 	}
+	checksum := sha256.Sum256(serializedStates)
+	serialized, err := json.Marshal(stateFile{
+		Version:  fileVersion,
+		Checksum: hex.EncodeToString(checksum[:]),
+		States:   serializedStates,
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling states file failed: %w", err)
+	}
 
 	// Write the states to disk
-	f, err := os.Create(p.Filename)
+	return writeFileAtomic(p.Filename, serialized)
+}
+
+// decode returns the plugin states contained in the given state file content.
+func decode(in []byte) (map[string]stateEntry, error) {
+	var envelope stateFile
+	if err := json.Unmarshal(in, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	// State files written by previous versions of Telegraf directly contain
+	// the id to serialized states map without an envelope
+	if envelope.Version == 0 {
+		var legacy map[string][]byte
+		if err := json.Unmarshal(in, &legacy); err != nil {
+			return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+		}
+		states := make(map[string]stateEntry, len(legacy))
+		for id, state := range legacy {
+			states[id] = stateEntry{State: state}
+		}
+		return states, nil
+	}
+
+	if envelope.Version > fileVersion {
+		return nil, fmt.Errorf("unsupported states file version %d", envelope.Version)
+	}
+
+	checksum := sha256.Sum256(envelope.States)
+	if hex.EncodeToString(checksum[:]) != envelope.Checksum {
+		return nil, errors.New("checksum mismatch, states file is corrupted")
+	}
+
+	var states map[string]stateEntry
+	if err := json.Unmarshal(envelope.States, &states); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	return states, nil
+}
+
+// restore deserializes the given state for the plugin, migrating states of
+// older layout versions if necessary.
+func restore(plugin telegraf.StatefulPlugin, entry stateEntry) (interface{}, error) {
+	if version := stateVersion(plugin); entry.Version != version {
+		migrator, ok := plugin.(telegraf.StateMigrator)
+		if !ok {
+			return nil, fmt.Errorf("state version %d does not match %d", entry.Version, version)
+		}
+		return migrator.MigrateState(entry.Version, entry.State)
+	}
+
+	// Create a new empty state of the "state"-type. As we need a pointer
+	// of the state, we cannot dereference it here due to the unknown
+	// nature of the state-type.
+	nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
+	if err := json.Unmarshal(entry.State, &nstate); err != nil {
+		return nil, fmt.Errorf("unmarshalling state failed: %w", err)
+	}
+	return reflect.ValueOf(nstate).Elem().Interface(), nil
+}
+
+// stateVersion returns the version of the plugin's state layout.
+func stateVersion(plugin telegraf.StatefulPlugin) int {
+	if migrator, ok := plugin.(telegraf.StateMigrator); ok {
+		return migrator.StateVersion()
+	}
+	return 0
+}
+
+// writeFileAtomic writes the data to a temporary file and renames it to the
+// given filename afterwards. This way the file is either completely written
+// or left untouched in case of a crash.
+func writeFileAtomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	f, err := os.CreateTemp(dir, filepath.Base(filename)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
+		return fmt.Errorf("creating temporary states file failed: %w", err)
 	}
-	defer f.Close()
+	defer os.Remove(f.Name())
 
-	if _, err := f.Write(serialized); err != nil {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
 		return fmt.Errorf("writing states failed: %w", err)
 	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing states failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing temporary states file failed: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), filename); err != nil {
+		return fmt.Errorf("replacing states file %q failed: %w", filename, err)
+	}
+
+	// Persist the rename by syncing the directory. This is not supported on
+	// all platforms, so ignore any errors.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync() //nolint:errcheck // Not supported on all platforms
+		d.Close()
+	}
 
 	return nil
 }
@@ -0,0 +1,167 @@
+package persister
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", &mockPlugin{state: map[string]int64{"foo": 42}}))
+	require.NoError(t, p.Register("b", &mockPlugin{state: map[string]int64{"bar": 23}}))
+	require.NoError(t, p.Store())
+
+	// No temporary files must be left behind
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	a := &mockPlugin{state: map[string]int64{}}
+	b := &mockPlugin{state: map[string]int64{}}
+	p = &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", a))
+	require.NoError(t, p.Register("b", b))
+	require.NoError(t, p.Load())
+	require.Equal(t, map[string]int64{"foo": 42}, a.state)
+	require.Equal(t, map[string]int64{"bar": 23}, b.state)
+}
+
+func TestLoadLegacy(t *testing.T) {
+	// State written by older versions without envelope, i.e. the id to
+	// base64-encoded JSON state map
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"a":"eyJmb28iOjQyfQ=="}`), 0o600))
+
+	a := &mockPlugin{state: map[string]int64{}}
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", a))
+	require.NoError(t, p.Load())
+	require.Equal(t, map[string]int64{"foo": 42}, a.state)
+}
+
+func TestLoadCorrupted(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", &mockPlugin{state: map[string]int64{"foo": 42}}))
+	require.NoError(t, p.Store())
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	buf = []byte(strings.Replace(string(buf), "42", "43", 1))
+	require.NoError(t, os.WriteFile(filename, buf, 0o600))
+
+	require.ErrorContains(t, p.Load(), "checksum mismatch")
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"version":99,"checksum":"","states":{}}`), 0o600))
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.ErrorContains(t, p.Load(), "unsupported states file version 99")
+}
+
+func TestLoadMigration(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	// Store a state using the old, unversioned layout
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", &mockPlugin{state: map[string]int64{"foo": 42}}))
+	require.NoError(t, p.Store())
+
+	// Restore the state for a plugin with a newer layout
+	plugin := &mockVersionedPlugin{}
+	p = &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", plugin))
+	require.NoError(t, p.Load())
+	require.Equal(t, []int{0}, plugin.migrated)
+	require.Equal(t, []string{"foo=42"}, plugin.state)
+
+	// The state must be stored with the new version and restored without
+	// migration
+	require.NoError(t, p.Store())
+	plugin = &mockVersionedPlugin{}
+	p = &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", plugin))
+	require.NoError(t, p.Load())
+	require.Empty(t, plugin.migrated)
+	require.Equal(t, []string{"foo=42"}, plugin.state)
+
+	// Plugins without migration support cannot restore a state of a
+	// different version
+	p = &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", &mockPlugin{}))
+	require.ErrorContains(t, p.Load(), "state version 1 does not match 0")
+}
+
+type mockPlugin struct {
+	state map[string]int64
+}
+
+func (m *mockPlugin) GetState() interface{} {
+	return m.state
+}
+
+func (m *mockPlugin) SetState(state interface{}) error {
+	s, ok := state.(map[string]int64)
+	if !ok {
+		return errors.New("invalid state type")
+	}
+	m.state = s
+	return nil
+}
+
+type mockVersionedPlugin struct {
+	state    []string
+	migrated []int
+}
+
+func (m *mockVersionedPlugin) GetState() interface{} {
+	return m.state
+}
+
+func (m *mockVersionedPlugin) SetState(state interface{}) error {
+	s, ok := state.([]string)
+	if !ok {
+		return errors.New("invalid state type")
+	}
+	m.state = s
+	return nil
+}
+
+func (*mockVersionedPlugin) StateVersion() int {
+	return 1
+}
+
+func (m *mockVersionedPlugin) MigrateState(version int, state []byte) (interface{}, error) {
+	m.migrated = append(m.migrated, version)
+
+	var old map[string]int64
+	if err := json.Unmarshal(state, &old); err != nil {
+		return nil, err
+	}
+	converted := make([]string, 0, len(old))
+	for k, v := range old {
+		converted = append(converted, fmt.Sprintf("%s=%d", k, v))
+	}
+	return converted, nil
+}
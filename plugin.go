@@ -61,6 +61,15 @@ type PluginWithID interface {
 	ID() string
 }
 
+// ProbePlugin is an interface that inputs and outputs can optionally
+// implement to check whether the plugin is operational after it has been
+// started or connected. It is used for the "probe" startup-error behavior.
+type ProbePlugin interface {
+	// Probe checks the plugin's availability and returns an error if the
+	// plugin is not operational, e.g. a required resource is unreachable.
+	Probe() error
+}
+
 // StatefulPlugin contains the functions that plugins must implement to
 // persist an internal state across Telegraf runs.
 // Note that plugins may define a persister that is not part of the
@@ -73,7 +82,8 @@ type StatefulPlugin interface {
 	// serialized to JSON. The best choice is a structure defined in
 	// your plugin.
 	// Note: This function has to be callable directly after the
-	// plugin's Init() function if there is any!
+	// plugin's Init() function if there is any! If periodic checkpointing
+	// is enabled, the function is also called while the plugin is running.
 	GetState() interface{}
 
 	// SetState is called by the Persister once after loading and
@@ -81,6 +91,19 @@ type StatefulPlugin interface {
 	SetState(state interface{}) error
 }
 
+// StateMigrator is an interface that stateful plugins can optionally
+// implement to version the layout of their state. States persisted with an
+// older layout are migrated instead of failing to restore them.
+type StateMigrator interface {
+	// StateVersion returns the version of the state layout returned by
+	// GetState. Plugins not implementing this interface use version zero.
+	StateVersion() int
+
+	// MigrateState converts the serialized state of the given layout
+	// version into a state accepted by SetState.
+	MigrateState(version int, state []byte) (interface{}, error)
+}
+
 // Logger defines an plugin-related interface for logging.
 type Logger interface {
 	// Errorf logs an error message, patterned after log.Printf.
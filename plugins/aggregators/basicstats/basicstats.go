@@ -3,10 +3,13 @@ package basicstats
 
 import (
 	_ "embed"
+	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -19,6 +22,10 @@ type BasicStats struct {
 
 	cache       map[uint64]aggregate
 	statsConfig *configuredStats
+
+	// Guards the running statistics in cache, Add updates them, Reset
+	// replaces the map and GetState converts them to the persisted form
+	sync.Mutex
 }
 
 type configuredStats struct {
@@ -68,6 +75,9 @@ func (*BasicStats) SampleConfig() string {
 }
 
 func (b *BasicStats) Add(in telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
 	id := in.HashID()
 	if _, ok := b.cache[id]; !ok {
 		// hit an uncached metric, create caches for first time:
@@ -154,6 +164,9 @@ func (b *BasicStats) Add(in telegraf.Metric) {
 }
 
 func (b *BasicStats) Push(acc telegraf.Accumulator) {
+	b.Lock()
+	defer b.Unlock()
+
 	for _, aggregate := range b.cache {
 		fields := map[string]interface{}{}
 		for k, v := range aggregate.fields {
@@ -272,9 +285,93 @@ func (b *BasicStats) getConfiguredStats() {
 }
 
 func (b *BasicStats) Reset() {
+	b.Lock()
+	defer b.Unlock()
+
 	b.cache = make(map[uint64]aggregate)
 }
 
+// aggregateState is the persisted state of an aggregated series
+type aggregateState struct {
+	Name   string                `json:"name"`
+	Tags   map[string]string     `json:"tags,omitempty"`
+	Fields map[string]fieldState `json:"fields"`
+}
+
+// fieldState is the persisted state of the statistics of a single field
+type fieldState struct {
+	Count    float64       `json:"count"`
+	Min      float64       `json:"min"`
+	Max      float64       `json:"max"`
+	Sum      float64       `json:"sum"`
+	Mean     float64       `json:"mean"`
+	Diff     float64       `json:"diff"`
+	Rate     float64       `json:"rate"`
+	Interval time.Duration `json:"interval"`
+	M2       float64       `json:"m2"`
+	Last     float64       `json:"last"`
+	Time     time.Time     `json:"time"`
+}
+
+func (b *BasicStats) GetState() interface{} {
+	b.Lock()
+	defer b.Unlock()
+
+	state := make([]aggregateState, 0, len(b.cache))
+	for _, a := range b.cache {
+		fields := make(map[string]fieldState, len(a.fields))
+		for k, v := range a.fields {
+			fields[k] = fieldState{
+				Count:    v.count,
+				Min:      v.min,
+				Max:      v.max,
+				Sum:      v.sum,
+				Mean:     v.mean,
+				Diff:     v.diff,
+				Rate:     v.rate,
+				Interval: v.interval,
+				M2:       v.M2,
+				Last:     v.LAST,
+				Time:     v.TIME,
+			}
+		}
+		state = append(state, aggregateState{Name: a.name, Tags: a.tags, Fields: fields})
+	}
+	return state
+}
+
+func (b *BasicStats) SetState(state interface{}) error {
+	aggregates, ok := state.([]aggregateState)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	for _, a := range aggregates {
+		fields := make(map[string]basicstats, len(a.Fields))
+		for k, v := range a.Fields {
+			fields[k] = basicstats{
+				count:    v.Count,
+				min:      v.Min,
+				max:      v.Max,
+				sum:      v.Sum,
+				mean:     v.Mean,
+				diff:     v.Diff,
+				rate:     v.Rate,
+				interval: v.Interval,
+				M2:       v.M2,
+				LAST:     v.Last,
+				TIME:     v.Time,
+			}
+		}
+		id := metric.New(a.Name, a.Tags, nil, time.Time{}).HashID()
+		b.cache[id] = aggregate{name: a.Name, tags: a.Tags, fields: fields}
+	}
+	return nil
+}
+
 func convert(in interface{}) (float64, bool) {
 	switch v := in.(type) {
 	case float64:
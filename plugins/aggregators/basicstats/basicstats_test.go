@@ -2,12 +2,14 @@ package basicstats
 
 import (
 	"math"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -731,3 +733,36 @@ func TestBasicStatsWithDefaultStats(t *testing.T) {
 	require.True(t, acc.HasField("m1", "a_s2"))
 	require.False(t, acc.HasField("m1", "a_sum"))
 }
+
+func TestBasicStatsWithStatePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	aggregator := NewBasicStats()
+	aggregator.Stats = []string{"count", "mean", "diff", "interval"}
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+	aggregator.Add(m1)
+	aggregator.Add(m2)
+
+	// Store the state of the aggregator
+	store := &persister.Persister{Filename: filename}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("basicstats", aggregator))
+	require.NoError(t, store.Store())
+
+	// Restore the state into a new instance
+	restored := NewBasicStats()
+	restored.Stats = []string{"count", "mean", "diff", "interval"}
+	restored.Log = testutil.Logger{}
+	require.NoError(t, restored.Init())
+	load := &persister.Persister{Filename: filename}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("basicstats", restored))
+	require.NoError(t, load.Load())
+
+	var expected, actual testutil.Accumulator
+	aggregator.Push(&expected)
+	restored.Push(&actual)
+	require.NotEmpty(t, actual.GetTelegrafMetrics())
+	testutil.RequireMetricsEqual(t, expected.GetTelegrafMetrics(), actual.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
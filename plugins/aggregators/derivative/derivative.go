@@ -3,10 +3,14 @@ package derivative
 
 import (
 	_ "embed"
+	"fmt"
+	"maps"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -19,6 +23,10 @@ type Derivative struct {
 	MaxRollOver uint            `toml:"max_roll_over"`
 	Log         telegraf.Logger `toml:"-"`
 	cache       map[uint64]*aggregate
+
+	// Guards the first and last events in cache, Add and Push run in the
+	// aggregator loop while Reset rolls them over and GetState exports them
+	sync.Mutex
 }
 
 type aggregate struct {
@@ -48,6 +56,9 @@ func (*Derivative) SampleConfig() string {
 }
 
 func (d *Derivative) Add(in telegraf.Metric) {
+	d.Lock()
+	defer d.Unlock()
+
 	id := in.HashID()
 	current, ok := d.cache[id]
 	if !ok {
@@ -116,6 +127,9 @@ func convert(in interface{}) (float64, bool) {
 }
 
 func (d *Derivative) Push(acc telegraf.Accumulator) {
+	d.Lock()
+	defer d.Unlock()
+
 	for _, aggregate := range d.cache {
 		if aggregate.first == aggregate.last {
 			d.Log.Debugf("Same first and last event for %q, skipping.", aggregate.name)
@@ -157,6 +171,9 @@ func (d *Derivative) Push(acc telegraf.Accumulator) {
 }
 
 func (d *Derivative) Reset() {
+	d.Lock()
+	defer d.Unlock()
+
 	for id, aggregate := range d.cache {
 		if aggregate.rollOver < d.MaxRollOver {
 			aggregate.first = aggregate.last
@@ -170,6 +187,68 @@ func (d *Derivative) Reset() {
 	}
 }
 
+// aggregateState is the persisted state of a series
+type aggregateState struct {
+	Name     string            `json:"name"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	First    eventState        `json:"first"`
+	Last     *eventState       `json:"last,omitempty"`
+	RollOver uint              `json:"roll_over"`
+}
+
+// eventState is the persisted state of an event, the last event is omitted
+// if it is identical to the first one
+type eventState struct {
+	Fields map[string]float64 `json:"fields"`
+	Time   time.Time          `json:"time"`
+}
+
+func (d *Derivative) GetState() interface{} {
+	d.Lock()
+	defer d.Unlock()
+
+	state := make([]aggregateState, 0, len(d.cache))
+	for _, a := range d.cache {
+		s := aggregateState{
+			Name:     a.name,
+			Tags:     a.tags,
+			First:    eventState{Fields: maps.Clone(a.first.fields), Time: a.first.time},
+			RollOver: a.rollOver,
+		}
+		if a.last != a.first {
+			s.Last = &eventState{Fields: maps.Clone(a.last.fields), Time: a.last.time}
+		}
+		state = append(state, s)
+	}
+	return state
+}
+
+func (d *Derivative) SetState(state interface{}) error {
+	aggregates, ok := state.([]aggregateState)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	for _, s := range aggregates {
+		a := &aggregate{
+			name:     s.Name,
+			tags:     s.Tags,
+			first:    &event{fields: s.First.Fields, time: s.First.Time},
+			rollOver: s.RollOver,
+		}
+		a.last = a.first
+		if s.Last != nil {
+			a.last = &event{fields: s.Last.Fields, time: s.Last.Time}
+		}
+		id := metric.New(s.Name, s.Tags, nil, time.Time{}).HashID()
+		d.cache[id] = a
+	}
+	return nil
+}
+
 func (d *Derivative) Init() error {
 	d.Suffix = strings.TrimSpace(d.Suffix)
 	d.Variable = strings.TrimSpace(d.Variable)
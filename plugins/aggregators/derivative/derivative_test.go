@@ -1,12 +1,14 @@
 package derivative
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -414,3 +416,42 @@ func TestCalculatesCorrectDerivativeOnTwoConsecutivePeriods(t *testing.T) {
 		"value_rate": 2.0,
 	})
 }
+
+func TestStatePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	derivative := NewDerivative()
+	derivative.Log = testutil.Logger{}
+	require.NoError(t, derivative.Init())
+	derivative.Add(start)
+	derivative.Push(&testutil.Accumulator{})
+	derivative.Reset()
+
+	// Store the state of the aggregator after the first period
+	store := &persister.Persister{Filename: filename}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("derivative", derivative))
+	require.NoError(t, store.Store())
+
+	// Restore the state into a new instance and continue with the next period
+	restored := NewDerivative()
+	restored.Log = testutil.Logger{}
+	require.NoError(t, restored.Init())
+	load := &persister.Persister{Filename: filename}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("derivative", restored))
+	require.NoError(t, load.Load())
+
+	var acc testutil.Accumulator
+	restored.Add(finish)
+	restored.Push(&acc)
+
+	// The rates are not exact as the test metrics use the current time
+	require.Len(t, acc.Metrics, 1)
+	fields := acc.Metrics[0].Fields
+	require.Len(t, fields, 4)
+	require.InDelta(t, 1000.0, fields["increasing_rate"], 0.1)
+	require.InDelta(t, -100.0, fields["decreasing_rate"], 0.1)
+	require.InDelta(t, 0.0, fields["unchanged_rate"], 0.1)
+	require.InDelta(t, 10.0, fields["parameter_rate"], 0.1)
+}
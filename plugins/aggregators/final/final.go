@@ -4,11 +4,14 @@ package final
 import (
 	_ "embed"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
 //go:embed sample.conf
@@ -17,9 +20,14 @@ var sampleConfig string
 type Final struct {
 	OutputStrategy string          `toml:"output_strategy"`
 	SeriesTimeout  config.Duration `toml:"series_timeout"`
+	Log            telegraf.Logger `toml:"-"`
 
 	// The last metric for all series which are active
 	metricCache map[uint64]telegraf.Metric
+
+	// Guards metricCache, Push removes the emitted series while Add updates
+	// them and GetState copies the cached metrics
+	sync.Mutex
 }
 
 func NewFinal() *Final {
@@ -50,11 +58,17 @@ func (m *Final) Init() error {
 }
 
 func (m *Final) Add(in telegraf.Metric) {
+	m.Lock()
+	defer m.Unlock()
+
 	id := in.HashID()
 	m.metricCache[id] = in
 }
 
 func (m *Final) Push(acc telegraf.Accumulator) {
+	m.Lock()
+	defer m.Unlock()
+
 	// Preserve timestamp of original metric
 	acc.SetPrecision(time.Nanosecond)
 
@@ -76,6 +90,46 @@ func (m *Final) Push(acc telegraf.Accumulator) {
 func (m *Final) Reset() {
 }
 
+func (m *Final) GetState() interface{} {
+	m.Lock()
+	defer m.Unlock()
+
+	metrics := make([]telegraf.Metric, 0, len(m.metricCache))
+	for _, metric := range m.metricCache {
+		metrics = append(metrics, metric)
+	}
+
+	s := &serializer.Serializer{}
+	state, err := s.SerializeBatch(metrics)
+	if err != nil {
+		m.Log.Errorf("Serializing state failed: %v", err)
+	}
+	return state
+}
+
+func (m *Final) SetState(state interface{}) error {
+	data, ok := state.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	p := &influx.Parser{}
+	if err := p.Init(); err != nil {
+		return err
+	}
+	metrics, err := p.Parse(data)
+	if err != nil {
+		return fmt.Errorf("parsing state failed: %w", err)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	for _, metric := range metrics {
+		m.metricCache[metric.HashID()] = metric
+	}
+	return nil
+}
+
 func init() {
 	aggregators.Add("final", func() telegraf.Aggregator {
 		return NewFinal()
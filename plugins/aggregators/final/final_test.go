@@ -1,12 +1,14 @@
 package final
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -272,3 +274,42 @@ func TestOutputStrategyPeriodic(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
 }
+
+func TestStatePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	final := NewFinal()
+	require.NoError(t, final.Init())
+	final.Add(metric.New("m1",
+		map[string]string{"foo": "bar"},
+		map[string]interface{}{"a": int64(1), "b": "x"},
+		time.Unix(1530939936, 0),
+	))
+
+	// Store the state of the aggregator
+	store := &persister.Persister{Filename: filename}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("final", final))
+	require.NoError(t, store.Store())
+
+	// Restore the state into a new instance
+	restored := NewFinal()
+	require.NoError(t, restored.Init())
+	load := &persister.Persister{Filename: filename}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("final", restored))
+	require.NoError(t, load.Load())
+
+	var acc testutil.Accumulator
+	restored.Push(&acc)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"m1",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"a_final": int64(1), "b_final": "x"},
+			time.Unix(1530939936, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"go.starlark.net/lib/json"
 	"go.starlark.net/lib/math"
@@ -26,6 +27,11 @@ type Common struct {
 	globals    starlark.StringDict
 	functions  map[string]*starlark.Function
 	parameters map[string]starlark.Tuple
+
+	// Shared state defined by the script and the lock protecting it while
+	// the state is persisted
+	state *starlark.Dict
+	sync.Mutex
 }
 
 func (s *Common) Init() error {
@@ -62,6 +68,11 @@ func (s *Common) Init() error {
 	if err != nil {
 		return err
 	}
+	// Keep the shared state defined by the script for persisting it
+	if state, ok := globals["state"].(*starlark.Dict); ok {
+		s.state = state
+	}
+
 	// Make available a shared state to the apply function
 	globals["state"] = starlark.NewDict(0)
 
@@ -145,6 +156,9 @@ func (s *Common) Call(name string) (starlark.Value, error) {
 	if !ok {
 		return nil, fmt.Errorf("params for function %q do not exist", name)
 	}
+
+	s.Lock()
+	defer s.Unlock()
 	return starlark.Call(s.thread, fn, args, nil)
 }
 
@@ -0,0 +1,219 @@
+package starlark
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// stateValue is the serializable representation of a value stored in the
+// shared state of the script.
+type stateValue struct {
+	Kind   string       `json:"kind"`
+	Bool   bool         `json:"bool,omitempty"`
+	Int    int64        `json:"int,omitempty"`
+	Float  float64      `json:"float,omitempty"`
+	String string       `json:"string,omitempty"`
+	Keys   []stateValue `json:"keys,omitempty"`
+	Items  []stateValue `json:"items,omitempty"`
+}
+
+// GetState returns the shared state of the script. Entries containing values
+// which cannot be persisted, e.g. functions, are skipped.
+func (s *Common) GetState() interface{} {
+	s.Lock()
+	defer s.Unlock()
+
+	state := stateValue{Kind: "dict"}
+	if s.state == nil {
+		return state
+	}
+
+	for _, item := range s.state.Items() {
+		key, err := encodeStateValue(item[0])
+		if err != nil {
+			s.Log.Warnf("Cannot persist state key %s: %v", item[0], err)
+			continue
+		}
+		value, err := encodeStateValue(item[1])
+		if err != nil {
+			s.Log.Warnf("Cannot persist state for key %s: %v", item[0], err)
+			continue
+		}
+		state.Keys = append(state.Keys, key)
+		state.Items = append(state.Items, value)
+	}
+
+	return state
+}
+
+// SetState restores the shared state of the script.
+func (s *Common) SetState(state interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+
+	persisted, ok := state.(stateValue)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+	if s.state == nil {
+		return nil
+	}
+
+	value, err := decodeStateValue(persisted)
+	if err != nil {
+		return err
+	}
+	dict, ok := value.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("invalid type %s for state", value.Type())
+	}
+
+	// The functions of the script reference the state, so we need to update
+	// the existing dictionary instead of replacing it
+	if err := s.state.Clear(); err != nil {
+		return err
+	}
+	for _, item := range dict.Items() {
+		if err := s.state.SetKey(item[0], item[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeStateValue(value starlark.Value) (stateValue, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return stateValue{Kind: "none"}, nil
+	case starlark.Bool:
+		return stateValue{Kind: "bool", Bool: bool(v)}, nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return stateValue{}, fmt.Errorf("integer %s out of range", v)
+		}
+		return stateValue{Kind: "int", Int: i}, nil
+	case starlark.Float:
+		return stateValue{Kind: "float", Float: float64(v)}, nil
+	case starlark.String:
+		return stateValue{Kind: "string", String: string(v)}, nil
+	case *starlark.List:
+		items, err := encodeStateValues(v)
+		return stateValue{Kind: "list", Items: items}, err
+	case starlark.Tuple:
+		items, err := encodeStateValues(v)
+		return stateValue{Kind: "tuple", Items: items}, err
+	case *starlark.Dict:
+		encoded := stateValue{Kind: "dict"}
+		for _, item := range v.Items() {
+			key, err := encodeStateValue(item[0])
+			if err != nil {
+				return stateValue{}, err
+			}
+			value, err := encodeStateValue(item[1])
+			if err != nil {
+				return stateValue{}, err
+			}
+			encoded.Keys = append(encoded.Keys, key)
+			encoded.Items = append(encoded.Items, value)
+		}
+		return encoded, nil
+	case *Metric:
+		// Use line-protocol to keep the type of the fields
+		s := &serializer.Serializer{}
+		buf, err := s.Serialize(v.metric)
+		if err != nil {
+			return stateValue{}, err
+		}
+		return stateValue{Kind: "metric", String: string(buf)}, nil
+	}
+
+	return stateValue{}, fmt.Errorf("unsupported type %s", value.Type())
+}
+
+func encodeStateValues(values starlark.Indexable) ([]stateValue, error) {
+	encoded := make([]stateValue, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		value, err := encodeStateValue(values.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, value)
+	}
+	return encoded, nil
+}
+
+func decodeStateValue(value stateValue) (starlark.Value, error) {
+	switch value.Kind {
+	case "none":
+		return starlark.None, nil
+	case "bool":
+		return starlark.Bool(value.Bool), nil
+	case "int":
+		return starlark.MakeInt64(value.Int), nil
+	case "float":
+		return starlark.Float(value.Float), nil
+	case "string":
+		return starlark.String(value.String), nil
+	case "list":
+		items, err := decodeStateValues(value.Items)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.NewList(items), nil
+	case "tuple":
+		items, err := decodeStateValues(value.Items)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Tuple(items), nil
+	case "dict":
+		if len(value.Keys) != len(value.Items) {
+			return nil, fmt.Errorf("mismatching number of keys (%d) and items (%d)", len(value.Keys), len(value.Items))
+		}
+		dict := starlark.NewDict(len(value.Keys))
+		for i := range value.Keys {
+			key, err := decodeStateValue(value.Keys[i])
+			if err != nil {
+				return nil, err
+			}
+			item, err := decodeStateValue(value.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(key, item); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case "metric":
+		p := &influx.Parser{}
+		if err := p.Init(); err != nil {
+			return nil, err
+		}
+		m, err := p.ParseLine(value.String)
+		if err != nil {
+			return nil, fmt.Errorf("parsing metric failed: %w", err)
+		}
+		return &Metric{metric: m}, nil
+	}
+
+	return nil, fmt.Errorf("unknown kind %q", value.Kind)
+}
+
+func decodeStateValues(values []stateValue) ([]starlark.Value, error) {
+	decoded := make([]starlark.Value, 0, len(values))
+	for _, value := range values {
+		v, err := decodeStateValue(value)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, v)
+	}
+	return decoded, nil
+}
@@ -23,12 +23,9 @@ var sampleConfig string
 const measurement = "amd_rocm_smi"
 
 type ROCmSMI struct {
-	BinPath              string          `toml:"bin_path"`
-	Timeout              config.Duration `toml:"timeout"`
-	StartupErrorBehavior string          `toml:"startup_error_behavior"`
-	Log                  telegraf.Logger `toml:"-"`
-
-	ignorePlugin bool
+	BinPath string          `toml:"bin_path"`
+	Timeout config.Duration `toml:"timeout"`
+	Log     telegraf.Logger `toml:"-"`
 }
 
 func (*ROCmSMI) SampleConfig() string {
@@ -37,10 +34,6 @@ func (*ROCmSMI) SampleConfig() string {
 
 // Gather implements the telegraf interface
 func (rsmi *ROCmSMI) Gather(acc telegraf.Accumulator) error {
-	if rsmi.ignorePlugin {
-		return nil
-	}
-
 	data := rsmi.pollROCmSMI()
 	err := gatherROCmSMI(data, acc)
 	if err != nil {
@@ -50,20 +43,13 @@ func (rsmi *ROCmSMI) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (rsmi *ROCmSMI) Init() error {
+// Start locates the rocm-smi binary. A missing binary is a startup error
+// handled according to the startup_error_behavior setting of the input.
+func (rsmi *ROCmSMI) Start(telegraf.Accumulator) error {
 	if _, err := os.Stat(rsmi.BinPath); os.IsNotExist(err) {
 		binPath, err := exec.LookPath("rocm-smi")
 		if err != nil {
-			switch rsmi.StartupErrorBehavior {
-			case "ignore":
-				rsmi.ignorePlugin = true
-				rsmi.Log.Warnf("rocm-smi not found on the system, ignoring: %s", err)
-				return nil
-			case "", "error":
-				return fmt.Errorf("rocm-smi binary not found in path %s, cannot query GPUs statistics", rsmi.BinPath)
-			default:
-				return fmt.Errorf("unknown startup behavior setting: %s", rsmi.StartupErrorBehavior)
-			}
+			return fmt.Errorf("rocm-smi binary not found in path %s, cannot query GPUs statistics", rsmi.BinPath)
 		}
 		rsmi.BinPath = binPath
 	}
@@ -71,6 +57,8 @@ func (rsmi *ROCmSMI) Init() error {
 	return nil
 }
 
+func (*ROCmSMI) Stop() {}
+
 func init() {
 	inputs.Add("amd_rocm_smi", func() telegraf.Input {
 		return &ROCmSMI{
@@ -69,12 +69,41 @@ type DirectoryMonitor struct {
 	fileRegexesToMatch  []*regexp.Regexp
 	fileRegexesToIgnore []*regexp.Regexp
 	filesToProcess      chan string
+
+	// Number of lines already processed for files interrupted while reading
+	offsets     map[string]int64
+	offsetsLock sync.Mutex
 }
 
 func (*DirectoryMonitor) SampleConfig() string {
 	return sampleConfig
 }
 
+func (monitor *DirectoryMonitor) GetState() interface{} {
+	monitor.offsetsLock.Lock()
+	defer monitor.offsetsLock.Unlock()
+
+	offsets := make(map[string]int64, len(monitor.offsets))
+	for k, v := range monitor.offsets {
+		offsets[k] = v
+	}
+	return offsets
+}
+
+func (monitor *DirectoryMonitor) SetState(state interface{}) error {
+	offsets, ok := state.(map[string]int64)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	monitor.offsetsLock.Lock()
+	defer monitor.offsetsLock.Unlock()
+	for k, v := range offsets {
+		monitor.offsets[k] = v
+	}
+	return nil
+}
+
 func (monitor *DirectoryMonitor) Gather(_ telegraf.Accumulator) error {
 	processFile := func(path string) error {
 		// We've been cancelled via Stop().
@@ -205,6 +234,17 @@ func (monitor *DirectoryMonitor) processFile(path string) {
 func (monitor *DirectoryMonitor) read(filePath string) {
 	// Open, read, and parse the contents of the file.
 	err := monitor.ingestFile(filePath)
+
+	// Reading was interrupted by stopping the plugin, so keep the file and
+	// the offset to continue where we left off.
+	if err != nil && monitor.context.Err() != nil {
+		return
+	}
+
+	monitor.offsetsLock.Lock()
+	delete(monitor.offsets, filePath)
+	monitor.offsetsLock.Unlock()
+
 	var pathErr *os.PathError
 	if errors.As(err, &pathErr) {
 		return
@@ -269,7 +309,18 @@ func (monitor *DirectoryMonitor) parseFile(parser telegraf.Parser, reader io.Rea
 	scanner := bufio.NewScanner(reader)
 	scanner.Split(splitter)
 
+	// Skip the lines already processed before the plugin was stopped
+	monitor.offsetsLock.Lock()
+	offset := monitor.offsets[fileName]
+	monitor.offsetsLock.Unlock()
+
+	var lines int64
 	for scanner.Scan() {
+		lines++
+		if lines <= offset {
+			continue
+		}
+
 		metrics, err := monitor.parseMetrics(parser, scanner.Bytes(), fileName)
 		if err != nil {
 			return err
@@ -278,6 +329,10 @@ func (monitor *DirectoryMonitor) parseFile(parser telegraf.Parser, reader io.Rea
 		if err := monitor.sendMetrics(metrics); err != nil {
 			return err
 		}
+
+		monitor.offsetsLock.Lock()
+		monitor.offsets[fileName] = lines
+		monitor.offsetsLock.Unlock()
 	}
 
 	return scanner.Err()
@@ -435,6 +490,7 @@ func (monitor *DirectoryMonitor) Init() error {
 	monitor.sem = semaphore.NewWeighted(int64(monitor.MaxBufferedMetrics))
 	monitor.context, monitor.cancel = context.WithCancel(context.Background())
 	monitor.filesToProcess = make(chan string, monitor.FileQueueSize)
+	monitor.offsets = make(map[string]int64)
 
 	// Establish file matching / exclusion regexes.
 	for _, matcher := range monitor.FilesToMonitor {
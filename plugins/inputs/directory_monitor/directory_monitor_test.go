@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
@@ -680,3 +681,70 @@ func TestParseSubdirectoriesFilesIgnore(t *testing.T) {
 	_, err = os.Stat(filepath.Join(finishedDirectory, testJSONFile))
 	require.NoError(t, err)
 }
+
+func TestStatePersistence(t *testing.T) {
+	acc := testutil.Accumulator{}
+	testJSONFile := "test.json"
+
+	// Establish process directory and finished directory.
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	newPlugin := func() *DirectoryMonitor {
+		r := &DirectoryMonitor{
+			Directory:          processDirectory,
+			FinishedDirectory:  finishedDirectory,
+			MaxBufferedMetrics: defaultMaxBufferedMetrics,
+			FileQueueSize:      defaultFileQueueSize,
+			ParseMethod:        defaultParseMethod,
+			Log:                testutil.Logger{},
+		}
+		require.NoError(t, r.Init())
+		r.SetParserFunc(func() (telegraf.Parser, error) {
+			p := &json.Parser{NameKey: "Name"}
+			err := p.Init()
+			return p, err
+		})
+		return r
+	}
+
+	// Simulate a previous run interrupted after processing three lines
+	path := filepath.Join(processDirectory, testJSONFile)
+	r := newPlugin()
+	require.NoError(t, r.SetState(map[string]int64{path: 3}))
+	p := &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("directory_monitor", r))
+	require.NoError(t, p.Store())
+
+	// Let's drop a 5-line LINE-DELIMITED json.
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = f.WriteString(
+		"{\"Name\": \"event1\",\"Speed\": 100.1,\"Length\": 20.1}\n{\"Name\": \"event2\",\"Speed\": 500,\"Length\": 1.4}\n" +
+			"{\"Name\": " + "\"event3\",\"Speed\": 200,\"Length\": 10.23}\n{\"Name\": \"event4\",\"Speed\": 80,\"Length\": 250}\n" +
+			"{\"Name\": \"event5\",\"Speed\": 120.77,\"Length\": 25.97}",
+	)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Restore the state and continue reading the file
+	r = newPlugin()
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("directory_monitor", r))
+	require.NoError(t, p.Load())
+
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(2)
+	r.Stop()
+
+	// Only the remaining lines must be read and the offset must be removed
+	// after the file is finished
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, "event4", acc.Metrics[0].Measurement)
+	require.Equal(t, "event5", acc.Metrics[1].Measurement)
+	require.Empty(t, r.GetState())
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -32,6 +33,14 @@ var sampleConfig string
 // 500 MB
 const defaultMaxBodySize = 500 * 1024 * 1024
 
+// defaultDeliveryTimeout is the default time to wait for the outputs to
+// accept the metrics of a request when waiting for delivery.
+const defaultDeliveryTimeout = 5 * time.Second
+
+// defaultMaxUndeliveredMessages is the default number of requests whose
+// metrics are tracked until delivery when waiting for delivery.
+const defaultMaxUndeliveredMessages = 1000
+
 const (
 	body    = "body"
 	query   = "query"
@@ -58,19 +67,32 @@ type HTTPListenerV2 struct {
 	BasicPassword  string            `toml:"basic_password"`
 	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
 
+	WaitForDelivery        bool            `toml:"wait_for_delivery"`
+	DeliveryTimeout        config.Duration `toml:"delivery_timeout"`
+	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
+	MaxInFlightRequests    int             `toml:"max_in_flight_requests"`
+
 	tlsint.ServerConfig
 	tlsConf *tls.Config
 
 	TimeFunc
 	Log telegraf.Logger
 
-	wg    sync.WaitGroup
-	close chan struct{}
+	wg       sync.WaitGroup
+	close    chan struct{}
+	inflight chan struct{}
 
 	listener net.Listener
 
 	telegraf.Parser
 	acc telegraf.Accumulator
+
+	// State of waiting for delivery, sem is only released once the metrics
+	// of a request are delivered even if the request timed out before
+	trackingAcc telegraf.TrackingAccumulator
+	undelivered map[telegraf.TrackingID]chan bool
+	sem         chan struct{}
+	mu          sync.Mutex
 }
 
 func (*HTTPListenerV2) SampleConfig() string {
@@ -98,12 +120,41 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 		h.WriteTimeout = config.Duration(time.Second * 10)
 	}
 
+	if h.WaitForDelivery {
+		if h.DeliveryTimeout <= 0 {
+			h.DeliveryTimeout = config.Duration(defaultDeliveryTimeout)
+		}
+		// The response has to be written before the write timeout expires
+		if h.DeliveryTimeout >= h.WriteTimeout {
+			return fmt.Errorf("delivery_timeout (%s) must be less than write_timeout (%s)",
+				time.Duration(h.DeliveryTimeout), time.Duration(h.WriteTimeout))
+		}
+		if h.MaxUndeliveredMessages <= 0 {
+			h.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+		}
+	}
+
+	if h.MaxInFlightRequests > 0 {
+		h.inflight = make(chan struct{}, h.MaxInFlightRequests)
+	}
+
 	// Append h.Path to h.Paths
 	if h.Path != "" && !choice.Contains(h.Path, h.Paths) {
 		h.Paths = append(h.Paths, h.Path)
 	}
 
 	h.acc = acc
+	if h.WaitForDelivery {
+		h.trackingAcc = acc.WithTracking(h.MaxUndeliveredMessages)
+		h.sem = make(chan struct{}, h.MaxUndeliveredMessages)
+		h.undelivered = make(map[telegraf.TrackingID]chan bool)
+
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.receiveDelivered()
+		}()
+	}
 
 	server := h.createHTTPServer()
 
@@ -185,6 +236,19 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 	default:
 	}
 
+	// Limit the number of requests processed concurrently
+	if h.inflight != nil {
+		select {
+		case h.inflight <- struct{}{}:
+			defer func() { <-h.inflight }()
+		default:
+			if err := tooManyRequests(res); err != nil {
+				h.Log.Debugf("error in too-many-requests: %v", err)
+			}
+			return
+		}
+	}
+
 	// Check that the content length is not too large for us to handle.
 	if req.ContentLength > int64(h.MaxBodySize) {
 		if err := tooLarge(res); err != nil {
@@ -242,13 +306,84 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		if h.PathTag {
 			m.AddTag(pathTag, req.URL.Path)
 		}
+	}
 
+	if h.WaitForDelivery && len(metrics) > 0 {
+		h.writeTracked(res, req, metrics)
+		return
+	}
+
+	for _, m := range metrics {
 		h.acc.AddMetric(m)
 	}
 
 	res.WriteHeader(http.StatusNoContent)
 }
 
+// writeTracked adds the metrics as a tracked group and holds the response
+// until the outputs accepted or rejected the metrics or the delivery timeout
+// expired. Requests are refused while the maximum number of undelivered
+// groups is reached, as groups of timed out requests are still in flight and
+// clients are likely to retry them.
+func (h *HTTPListenerV2) writeTracked(res http.ResponseWriter, req *http.Request, metrics []telegraf.Metric) {
+	select {
+	case h.sem <- struct{}{}:
+	default:
+		if err := tooManyRequests(res); err != nil {
+			h.Log.Debugf("error in too-many-requests: %v", err)
+		}
+		return
+	}
+
+	delivered := make(chan bool, 1)
+	h.mu.Lock()
+	h.undelivered[h.trackingAcc.AddTrackingMetricGroup(metrics)] = delivered
+	h.mu.Unlock()
+
+	timer := time.NewTimer(time.Duration(h.DeliveryTimeout))
+	defer timer.Stop()
+
+	select {
+	case ok := <-delivered:
+		if !ok {
+			if err := rejected(res); err != nil {
+				h.Log.Debugf("error in rejected: %v", err)
+			}
+			return
+		}
+		res.WriteHeader(http.StatusNoContent)
+	case <-timer.C:
+		h.Log.Debugf("Delivery of %d metric(s) timed out", len(metrics))
+		if err := serviceUnavailable(res); err != nil {
+			h.Log.Debugf("error in service-unavailable: %v", err)
+		}
+	case <-req.Context().Done():
+		h.Log.Debugf("Client disconnected before delivery of %d metric(s)", len(metrics))
+	}
+}
+
+// receiveDelivered releases the slot of each delivered group and notifies the
+// request waiting for it, if any.
+func (h *HTTPListenerV2) receiveDelivered() {
+	for {
+		select {
+		case <-h.close:
+			return
+		case info := <-h.trackingAcc.Delivered():
+			<-h.sem
+
+			h.mu.Lock()
+			ch, ok := h.undelivered[info.ID()]
+			delete(h.undelivered, info.ID())
+			h.mu.Unlock()
+
+			if ok {
+				ch <- info.Delivered()
+			}
+		}
+	}
+}
+
 func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
 	encoding := req.Header.Get("Content-Encoding")
 
@@ -342,6 +477,27 @@ func badRequest(res http.ResponseWriter) error {
 	return err
 }
 
+func tooManyRequests(res http.ResponseWriter) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusTooManyRequests)
+	_, err := res.Write([]byte(`{"error":"http: too many requests"}`))
+	return err
+}
+
+func serviceUnavailable(res http.ResponseWriter) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusServiceUnavailable)
+	_, err := res.Write([]byte(`{"error":"http: delivery timed out"}`))
+	return err
+}
+
+func rejected(res http.ResponseWriter) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusInternalServerError)
+	_, err := res.Write([]byte(`{"error":"http: metrics rejected by outputs"}`))
+	return err
+}
+
 func (h *HTTPListenerV2) authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
 	if h.BasicUsername != "" && h.BasicPassword != "" {
 		reqUsername, reqPassword, ok := req.BasicAuth()
@@ -17,6 +17,7 @@ import (
 	"github.com/golang/snappy"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -719,3 +720,189 @@ func mustReadHugeMetric() []byte {
 
 // The term 'master_repl' used here is archaic language from redis
 var hugeMetric = mustReadHugeMetric()
+
+func TestWriteHTTPWaitForDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		deliver  func(m telegraf.Metric)
+		expected int
+	}{
+		{
+			name:     "accepted",
+			deliver:  func(m telegraf.Metric) { m.Accept() },
+			expected: http.StatusNoContent,
+		},
+		{
+			name:     "rejected",
+			deliver:  func(m telegraf.Metric) { m.Reject() },
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := newTestHTTPListenerV2()
+			require.NoError(t, err)
+			listener.WaitForDelivery = true
+
+			acc := &testutil.Accumulator{}
+			require.NoError(t, listener.Init())
+			require.NoError(t, listener.Start(acc))
+			defer listener.Stop()
+
+			done := make(chan int, 1)
+			go func() {
+				resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsgs)))
+				if err != nil {
+					done <- 0
+					return
+				}
+				resp.Body.Close()
+				done <- resp.StatusCode
+			}()
+
+			acc.Wait(5)
+
+			// The response is held until all metrics are delivered
+			metrics := acc.GetTelegrafMetrics()
+			for _, m := range metrics[:4] {
+				m.Accept()
+			}
+			select {
+			case <-done:
+				require.FailNow(t, "response before delivery")
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			tt.deliver(metrics[4])
+			require.Equal(t, tt.expected, <-done)
+		})
+	}
+}
+
+func TestWriteHTTPWaitForDeliveryTimeout(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.WaitForDelivery = true
+	listener.DeliveryTimeout = config.Duration(100 * time.Millisecond)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// Late deliveries must not block
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+}
+
+func TestWriteHTTPWaitForDeliveryInvalidTimeout(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.WaitForDelivery = true
+	listener.DeliveryTimeout = config.Duration(10 * time.Second)
+	listener.WriteTimeout = config.Duration(5 * time.Second)
+
+	require.NoError(t, listener.Init())
+	defer listener.Stop()
+	require.ErrorContains(t, listener.Start(&testutil.Accumulator{}), "must be less than write_timeout")
+}
+
+func TestWriteHTTPMaxInFlightRequests(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.WaitForDelivery = true
+	listener.MaxInFlightRequests = 1
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	done := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	acc.Wait(1)
+
+	// The first request is waiting for delivery so further requests are refused
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	acc.GetTelegrafMetrics()[0].Accept()
+	require.Equal(t, http.StatusNoContent, <-done)
+
+	// The slot is released after the response
+	go func() {
+		resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	acc.Wait(2)
+	acc.GetTelegrafMetrics()[1].Accept()
+	require.Equal(t, http.StatusNoContent, <-done)
+}
+
+func TestWriteHTTPMaxUndeliveredMessages(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.WaitForDelivery = true
+	listener.DeliveryTimeout = config.Duration(100 * time.Millisecond)
+	listener.MaxUndeliveredMessages = 1
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// The metrics of the timed out request are still undelivered so retries
+	// are refused instead of adding the metrics again
+	resp, err = http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// The slot is released once the metrics are delivered
+	acc.GetTelegrafMetrics()[0].Accept()
+	require.Eventually(t, func() bool {
+		return len(listener.sem) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	done := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBuffer([]byte(testMsg)))
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	acc.Wait(2)
+	acc.GetTelegrafMetrics()[1].Accept()
+	require.Equal(t, http.StatusNoContent, <-done)
+}
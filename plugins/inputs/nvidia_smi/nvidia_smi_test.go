@@ -7,53 +7,54 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
 
-func TestErrorBehaviorError(t *testing.T) {
+func TestStartupErrorBehaviorError(t *testing.T) {
 	// make sure we can't find nvidia-smi in $PATH somewhere
 	os.Unsetenv("PATH")
 	plugin := &NvidiaSMI{
-		BinPath:              "/random/non-existent/path",
-		Log:                  &testutil.Logger{},
-		StartupErrorBehavior: "error",
+		BinPath: "/random/non-existent/path",
+		Log:     &testutil.Logger{},
 	}
-	require.Error(t, plugin.Init())
+	ri := models.NewRunningInput(plugin, &models.InputConfig{Name: "nvidia_smi"})
+	require.ErrorContains(t, ri.Start(&testutil.Accumulator{}), "not found")
 }
 
-func TestErrorBehaviorDefault(t *testing.T) {
+func TestStartupErrorBehaviorRetry(t *testing.T) {
 	// make sure we can't find nvidia-smi in $PATH somewhere
 	os.Unsetenv("PATH")
 	plugin := &NvidiaSMI{
 		BinPath: "/random/non-existent/path",
 		Log:     &testutil.Logger{},
 	}
-	require.Error(t, plugin.Init())
-}
+	ri := models.NewRunningInput(plugin, &models.InputConfig{
+		Name:                 "nvidia_smi",
+		StartupErrorBehavior: "retry",
+	})
 
-func TestErorBehaviorIgnore(t *testing.T) {
-	// make sure we can't find nvidia-smi in $PATH somewhere
-	os.Unsetenv("PATH")
-	plugin := &NvidiaSMI{
-		BinPath:              "/random/non-existent/path",
-		Log:                  &testutil.Logger{},
-		StartupErrorBehavior: "ignore",
-	}
-	require.NoError(t, plugin.Init())
-	acc := testutil.Accumulator{}
-	require.NoError(t, plugin.Gather(&acc))
+	var acc testutil.Accumulator
+	require.NoError(t, ri.Start(&acc))
+	require.ErrorIs(t, ri.Gather(&acc), internal.ErrNotConnected)
 }
 
-func TestErrorBehaviorInvalidOption(t *testing.T) {
+func TestStartupErrorBehaviorIgnore(t *testing.T) {
 	// make sure we can't find nvidia-smi in $PATH somewhere
 	os.Unsetenv("PATH")
 	plugin := &NvidiaSMI{
-		BinPath:              "/random/non-existent/path",
-		Log:                  &testutil.Logger{},
-		StartupErrorBehavior: "giveup",
+		BinPath: "/random/non-existent/path",
+		Log:     &testutil.Logger{},
 	}
-	require.Error(t, plugin.Init())
+	ri := models.NewRunningInput(plugin, &models.InputConfig{
+		Name:                 "nvidia_smi",
+		StartupErrorBehavior: "ignore",
+	})
+
+	var fatalErr *internal.FatalError
+	require.ErrorAs(t, ri.Start(&testutil.Accumulator{}), &fatalErr)
 }
 
 func TestGatherValidXML(t *testing.T) {
@@ -0,0 +1,168 @@
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// otlpRequest is implemented by the export requests of all OTLP signals
+type otlpRequest interface {
+	UnmarshalProto(data []byte) error
+	UnmarshalJSON(data []byte) error
+}
+
+// otlpResponse is implemented by the export responses of all OTLP signals
+type otlpResponse interface {
+	MarshalProto() ([]byte, error)
+	MarshalJSON() ([]byte, error)
+}
+
+// newHTTPHandler returns a handler serving the OTLP/HTTP endpoints of the
+// given services as described in
+// https://opentelemetry.io/docs/specs/otlp/#otlphttp
+func newHTTPHandler(traces *traceService, metrics *metricsService, logs *logsService, maxBodySize int64, log telegraf.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/traces", &httpService[ptraceotlp.ExportRequest, ptraceotlp.ExportResponse]{
+		newRequest:  ptraceotlp.NewExportRequest,
+		export:      traces.Export,
+		maxBodySize: maxBodySize,
+		log:         log,
+	})
+	mux.Handle("/v1/metrics", &httpService[pmetricotlp.ExportRequest, pmetricotlp.ExportResponse]{
+		newRequest:  pmetricotlp.NewExportRequest,
+		export:      metrics.Export,
+		maxBodySize: maxBodySize,
+		log:         log,
+	})
+	mux.Handle("/v1/logs", &httpService[plogotlp.ExportRequest, plogotlp.ExportResponse]{
+		newRequest:  plogotlp.NewExportRequest,
+		export:      logs.Export,
+		maxBodySize: maxBodySize,
+		log:         log,
+	})
+	return mux
+}
+
+// httpService decodes the OTLP/HTTP requests of a single signal in either
+// binary protobuf or JSON encoding and passes them to the export function.
+type httpService[Req otlpRequest, Resp otlpResponse] struct {
+	newRequest  func() Req
+	export      func(context.Context, Req) (Resp, error)
+	maxBodySize int64
+	log         telegraf.Logger
+}
+
+func (s *httpService[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := s.readBody(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.writeStatus(w, contentType, http.StatusRequestEntityTooLarge, codes.InvalidArgument, err)
+			return
+		}
+		s.writeStatus(w, contentType, http.StatusBadRequest, codes.InvalidArgument, err)
+		return
+	}
+
+	req := s.newRequest()
+	if contentType == contentTypeJSON {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		s.writeStatus(w, contentType, http.StatusBadRequest, codes.InvalidArgument, fmt.Errorf("decoding request failed: %w", err))
+		return
+	}
+
+	resp, err := s.export(r.Context(), req)
+	if err != nil {
+		s.log.Errorf("Exporting %s failed: %v", r.URL.Path, err)
+		s.writeStatus(w, contentType, http.StatusInternalServerError, codes.Internal, err)
+		return
+	}
+
+	var buf []byte
+	if contentType == contentTypeJSON {
+		buf, err = resp.MarshalJSON()
+	} else {
+		buf, err = resp.MarshalProto()
+	}
+	if err != nil {
+		s.log.Errorf("Encoding response for %s failed: %v", r.URL.Path, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf); err != nil {
+		s.log.Debugf("Writing response for %s failed: %v", r.URL.Path, err)
+	}
+}
+
+// readBody reads the optionally gzip-compressed body limited to the maximum
+// body size after decompression.
+func (s *httpService[Req, Resp]) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+
+	reader, err := internal.NewStreamContentDecoder(r.Header.Get("Content-Encoding"), r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(http.MaxBytesReader(w, io.NopCloser(reader), s.maxBodySize))
+}
+
+// writeStatus writes the error as a google.rpc.Status message in the encoding
+// of the request.
+func (s *httpService[Req, Resp]) writeStatus(w http.ResponseWriter, contentType string, httpCode int, code codes.Code, err error) {
+	msg := status.New(code, err.Error()).Proto()
+
+	var buf []byte
+	if contentType == contentTypeJSON {
+		buf, err = protojson.Marshal(msg)
+	} else {
+		buf, err = proto.Marshal(msg)
+	}
+	if err != nil {
+		s.log.Errorf("Encoding status failed: %v", err)
+		w.WriteHeader(httpCode)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(httpCode)
+	if _, err := w.Write(buf); err != nil {
+		s.log.Debugf("Writing status failed: %v", err)
+	}
+}
@@ -2,9 +2,12 @@
 package opentelemetry
 
 import (
+	gotls "crypto/tls"
 	_ "embed"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"sync"
 	"time"
 
@@ -26,17 +29,21 @@ var sampleConfig string
 
 type OpenTelemetry struct {
 	ServiceAddress      string   `toml:"service_address"`
+	HTTPServiceAddress  string   `toml:"http_service_address"`
 	SpanDimensions      []string `toml:"span_dimensions"`
 	LogRecordDimensions []string `toml:"log_record_dimensions"`
 	MetricsSchema       string   `toml:"metrics_schema"`
 
 	tls.ServerConfig
-	Timeout config.Duration `toml:"timeout"`
+	Timeout         config.Duration `toml:"timeout"`
+	HTTPMaxBodySize config.Size     `toml:"http_max_body_size"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	listener   net.Listener // overridden in tests
-	grpcServer *grpc.Server
+	listener     net.Listener // overridden in tests
+	grpcServer   *grpc.Server
+	httpListener net.Listener // overridden in tests
+	httpServer   *http.Server
 
 	wg sync.WaitGroup
 }
@@ -50,10 +57,13 @@ func (o *OpenTelemetry) Gather(_ telegraf.Accumulator) error {
 }
 
 func (o *OpenTelemetry) Start(accumulator telegraf.Accumulator) error {
-	var grpcOptions []grpc.ServerOption
-	if tlsConfig, err := o.ServerConfig.TLSConfig(); err != nil {
+	tlsConfig, err := o.ServerConfig.TLSConfig()
+	if err != nil {
 		return err
-	} else if tlsConfig != nil {
+	}
+
+	var grpcOptions []grpc.ServerOption
+	if tlsConfig != nil {
 		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 	if o.Timeout > 0 {
@@ -80,16 +90,29 @@ func (o *OpenTelemetry) Start(accumulator telegraf.Accumulator) error {
 	}
 	plogotlp.RegisterGRPCServer(o.grpcServer, logsSvc)
 
-	if o.listener == nil {
-		o.listener, err = net.Listen("tcp", o.ServiceAddress)
+	// Listen for gRPC before starting the HTTP service so a failure does not
+	// leave the HTTP port bound when the start is retried
+	listener := o.listener
+	if listener == nil {
+		listener, err = net.Listen("tcp", o.ServiceAddress)
 		if err != nil {
 			return err
 		}
 	}
 
+	if o.HTTPServiceAddress != "" {
+		handler := newHTTPHandler(traceSvc, metricsSvc, logsSvc, int64(o.HTTPMaxBodySize), o.Log)
+		if err := o.startHTTP(accumulator, handler, tlsConfig); err != nil {
+			if o.listener == nil {
+				listener.Close()
+			}
+			return err
+		}
+	}
+
 	o.wg.Add(1)
 	go func() {
-		if err := o.grpcServer.Serve(o.listener); err != nil {
+		if err := o.grpcServer.Serve(listener); err != nil {
 			accumulator.AddError(fmt.Errorf("failed to stop OpenTelemetry gRPC service: %w", err))
 		}
 		o.wg.Done()
@@ -98,10 +121,44 @@ func (o *OpenTelemetry) Start(accumulator telegraf.Accumulator) error {
 	return nil
 }
 
+// startHTTP starts serving the OTLP/HTTP endpoints in addition to gRPC
+func (o *OpenTelemetry) startHTTP(accumulator telegraf.Accumulator, handler http.Handler, tlsConfig *gotls.Config) error {
+	listener := o.httpListener
+	if listener == nil {
+		var err error
+		listener, err = net.Listen("tcp", o.HTTPServiceAddress)
+		if err != nil {
+			return err
+		}
+	}
+	if tlsConfig != nil {
+		listener = gotls.NewListener(listener, tlsConfig)
+	}
+
+	o.httpServer = &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(o.Timeout),
+	}
+
+	o.wg.Add(1)
+	go func() {
+		err := o.httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			accumulator.AddError(fmt.Errorf("failed to serve OpenTelemetry HTTP service: %w", err))
+		}
+		o.wg.Done()
+	}()
+
+	return nil
+}
+
 func (o *OpenTelemetry) Stop() {
 	if o.grpcServer != nil {
 		o.grpcServer.Stop()
 	}
+	if o.httpServer != nil {
+		o.httpServer.Close()
+	}
 
 	o.wg.Wait()
 }
@@ -114,6 +171,7 @@ func init() {
 			LogRecordDimensions: otel2influx.DefaultOtelLogsToLineProtocolConfig().LogRecordDimensions,
 			MetricsSchema:       "prometheus-v1",
 			Timeout:             config.Duration(5 * time.Second),
+			HTTPMaxBodySize:     config.Size(4 * 1024 * 1024),
 		}
 	})
 }
@@ -1,20 +1,28 @@
 package opentelemetry
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"net"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -77,3 +85,208 @@ func TestOpenTelemetry(t *testing.T) {
 	require.Equal(t, telegraf.Counter, got.Type)
 	require.Equal(t, "library-name", got.Tags["otel.library.name"])
 }
+
+func TestOpenTelemetryHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		gzip        bool
+	}{
+		{
+			name:        "protobuf",
+			contentType: "application/x-protobuf",
+		},
+		{
+			name:        "json",
+			contentType: "application/json",
+		},
+		{
+			name:        "gzip protobuf",
+			contentType: "application/x-protobuf",
+			gzip:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, addr := startHTTPPlugin(t)
+			acc := &testutil.Accumulator{}
+			require.NoError(t, plugin.Start(acc))
+			t.Cleanup(plugin.Stop)
+
+			req := pmetricotlp.NewExportRequestFromMetrics(createMetrics())
+			var body []byte
+			var err error
+			if tt.contentType == "application/json" {
+				body, err = req.MarshalJSON()
+			} else {
+				body, err = req.MarshalProto()
+			}
+			require.NoError(t, err)
+
+			encoding := ""
+			if tt.gzip {
+				var buf bytes.Buffer
+				w := gzip.NewWriter(&buf)
+				_, err := w.Write(body)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				body = buf.Bytes()
+				encoding = "gzip"
+			}
+
+			httpReq, err := http.NewRequest(http.MethodPost, "http://"+addr+"/v1/metrics", bytes.NewReader(body))
+			require.NoError(t, err)
+			httpReq.Header.Set("Content-Type", tt.contentType)
+			if encoding != "" {
+				httpReq.Header.Set("Content-Encoding", encoding)
+			}
+			resp, err := http.DefaultClient.Do(httpReq)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+
+			require.Empty(t, acc.Errors)
+			require.Len(t, acc.Metrics, 1)
+			got := acc.Metrics[0]
+			require.Equal(t, "measurement-counter", got.Measurement)
+			require.Equal(t, telegraf.Counter, got.Type)
+			require.Equal(t, "library-name", got.Tags["otel.library.name"])
+		})
+	}
+}
+
+func TestOpenTelemetryHTTPInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{
+			name:        "wrong method",
+			method:      http.MethodGet,
+			path:        "/v1/metrics",
+			contentType: "application/x-protobuf",
+			expected:    http.StatusMethodNotAllowed,
+		},
+		{
+			name:        "unsupported content type",
+			method:      http.MethodPost,
+			path:        "/v1/metrics",
+			contentType: "text/plain",
+			expected:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "invalid body",
+			method:      http.MethodPost,
+			path:        "/v1/traces",
+			contentType: "application/json",
+			body:        "{",
+			expected:    http.StatusBadRequest,
+		},
+		{
+			name:        "body too large",
+			method:      http.MethodPost,
+			path:        "/v1/logs",
+			contentType: "application/x-protobuf",
+			body:        strings.Repeat("x", 2048),
+			expected:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "unknown path",
+			method:      http.MethodPost,
+			path:        "/v1/foo",
+			contentType: "application/x-protobuf",
+			expected:    http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, addr := startHTTPPlugin(t)
+			plugin.HTTPMaxBodySize = config.Size(1024)
+			acc := &testutil.Accumulator{}
+			require.NoError(t, plugin.Start(acc))
+			t.Cleanup(plugin.Stop)
+
+			req, err := http.NewRequest(tt.method, "http://"+addr+tt.path, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.expected, resp.StatusCode)
+			require.Empty(t, acc.Metrics)
+		})
+	}
+}
+
+func TestOpenTelemetryStartRetry(t *testing.T) {
+	// Occupy the gRPC port to fail the first start
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	httpAddr := httpListener.Addr().String()
+	require.NoError(t, httpListener.Close())
+
+	plugin := inputs.Inputs["opentelemetry"]().(*OpenTelemetry)
+	plugin.Log = testutil.Logger{}
+	plugin.ServiceAddress = grpcListener.Addr().String()
+	plugin.HTTPServiceAddress = httpAddr
+
+	// The HTTP service must not be started if gRPC fails
+	acc := &testutil.Accumulator{}
+	require.Error(t, plugin.Start(acc))
+	_, err = net.Dial("tcp", httpAddr)
+	require.Error(t, err)
+
+	// Retrying succeeds once the gRPC port is available
+	require.NoError(t, grpcListener.Close())
+	require.NoError(t, plugin.Start(acc))
+	t.Cleanup(plugin.Stop)
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+httpAddr+"/v1/metrics", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, acc.Errors)
+}
+
+// startHTTPPlugin returns a plugin with OTLP/HTTP enabled listening on a
+// random local port and gRPC listening on an in-memory connection
+func startHTTPPlugin(t *testing.T) (*OpenTelemetry, string) {
+	grpcListener := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() { _ = grpcListener.Close() })
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	plugin := inputs.Inputs["opentelemetry"]().(*OpenTelemetry)
+	plugin.Log = testutil.Logger{}
+	plugin.HTTPServiceAddress = httpListener.Addr().String()
+	plugin.listener = grpcListener
+	plugin.httpListener = httpListener
+	return plugin, httpListener.Addr().String()
+}
+
+func createMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("library-name")
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("measurement-counter")
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetIntValue(7)
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	return md
+}
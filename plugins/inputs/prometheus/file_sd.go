@@ -0,0 +1,100 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/globpath"
+)
+
+type FileSDConfig struct {
+	// Files containing target groups in the Prometheus file_sd format,
+	// glob patterns are supported
+	Files           []string        `toml:"files"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+
+	globs []*globpath.GlobPath
+}
+
+func (c *FileSDConfig) init() error {
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = config.Duration(5 * time.Minute)
+	}
+	for _, pattern := range c.Files {
+		g, err := globpath.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid file_sd pattern %q: %w", pattern, err)
+		}
+		c.globs = append(c.globs, g)
+	}
+	return nil
+}
+
+func (p *Prometheus) startFileSD(ctx context.Context) {
+	p.startDiscovery(ctx, "file_sd", time.Duration(p.FileSDConfig.RefreshInterval), func(context.Context) error {
+		return p.refreshFileSD()
+	})
+}
+
+// refreshFileSD reads the targets of all files matching the configured
+// patterns. The previous targets of a file are kept if it cannot be read.
+func (p *Prometheus) refreshFileSD() error {
+	p.lock.Lock()
+	previous := p.fileSDTargets
+	p.lock.Unlock()
+
+	targets := make(map[string]map[string]URLAndAddress)
+	var errs []error
+	for _, g := range p.FileSDConfig.globs {
+		for _, fn := range g.Match() {
+			if _, found := targets[fn]; found {
+				continue
+			}
+			urls, err := readFileSD(fn)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reading %q failed: %w", fn, err))
+				if prev, found := previous[fn]; found {
+					targets[fn] = prev
+				}
+				continue
+			}
+			targets[fn] = urls
+		}
+	}
+
+	p.lock.Lock()
+	p.fileSDTargets = targets
+	p.lock.Unlock()
+
+	return errors.Join(errs...)
+}
+
+func readFileSD(fn string) (map[string]URLAndAddress, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []targetGroup
+	switch ext := strings.ToLower(filepath.Ext(fn)); ext {
+	case ".json":
+		err = json.Unmarshal(buf, &groups)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(buf, &groups)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return targetGroupURLs(groups)
+}
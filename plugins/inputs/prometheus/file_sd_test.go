@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFileSD(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[
+		{
+			"targets": ["10.0.0.1:9100", "10.0.0.2:9100"],
+			"labels": {"env": "prod", "__meta_foo": "bar"}
+		}
+	]`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte(`
+- targets: ["10.0.0.3:8443"]
+  labels:
+    __scheme__: https
+    __metrics_path__: /probe
+    __param_module: http_2xx
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte(`[]`), 0600))
+
+	p := &Prometheus{
+		Log:          testutil.Logger{},
+		FileSDConfig: FileSDConfig{Files: []string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.yml")}},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.refreshFileSD())
+
+	urls, err := p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 3)
+	require.Equal(t, map[string]string{"env": "prod"}, urls["http://10.0.0.1:9100/metrics"].Tags)
+	require.Equal(t, map[string]string{"env": "prod"}, urls["http://10.0.0.2:9100/metrics"].Tags)
+	require.Contains(t, urls, "https://10.0.0.3:8443/probe?module=http_2xx")
+	require.Empty(t, urls["https://10.0.0.3:8443/probe?module=http_2xx"].Tags)
+
+	// Removed targets and files are no longer scraped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"targets": ["10.0.0.1:9100"]}]`), 0600))
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.yml")))
+	require.NoError(t, p.refreshFileSD())
+
+	urls, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Contains(t, urls, "http://10.0.0.1:9100/metrics")
+
+	// Targets of invalid files are kept
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{`), 0600))
+	require.ErrorContains(t, p.refreshFileSD(), "a.json")
+
+	urls, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Contains(t, urls, "http://10.0.0.1:9100/metrics")
+}
+
+func TestFileSDInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unsupported extension",
+			filename: "targets.txt",
+			content:  `[]`,
+			expected: `unsupported file extension ".txt"`,
+		},
+		{
+			name:     "invalid scheme",
+			filename: "targets.json",
+			content:  `[{"targets": ["localhost:9100"], "labels": {"__scheme__": "ftp"}}]`,
+			expected: `invalid scheme "ftp"`,
+		},
+		{
+			name:     "invalid target",
+			filename: "targets.json",
+			content:  `[{"targets": ["http://localhost:9100/metrics"]}]`,
+			expected: `invalid target "http://localhost:9100/metrics"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), tt.filename)
+			require.NoError(t, os.WriteFile(fn, []byte(tt.content), 0600))
+
+			p := &Prometheus{
+				Log:          testutil.Logger{},
+				FileSDConfig: FileSDConfig{Files: []string{fn}},
+			}
+			require.NoError(t, p.Init())
+			require.ErrorContains(t, p.refreshFileSD(), tt.expected)
+		})
+	}
+}
+
+func TestFileSDGather(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := fmt.Fprintln(w, sampleTextFormat)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	fn := filepath.Join(t.TempDir(), "targets.json")
+	content := fmt.Sprintf(`[{"targets": [%q], "labels": {"env": "prod"}}]`, ts.Listener.Addr().String())
+	require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+
+	p := &Prometheus{
+		Log:    testutil.Logger{},
+		URLTag: "url",
+		FileSDConfig: FileSDConfig{
+			Files:           []string{fn},
+			RefreshInterval: config.Duration(10 * time.Millisecond),
+		},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	defer p.Stop()
+
+	require.Eventually(t, func() bool {
+		urls, err := p.GetAllURLs()
+		return err == nil && len(urls) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, acc.GatherError(p.Gather))
+	require.True(t, acc.HasFloatField("go_goroutines", "gauge"))
+	require.Equal(t, "prod", acc.TagValue("go_goroutines", "env"))
+	require.Equal(t, ts.URL+"/metrics", acc.TagValue("go_goroutines", "url"))
+
+	// Targets are removed without restarting the plugin
+	require.NoError(t, os.WriteFile(fn, []byte(`[]`), 0600))
+	require.Eventually(t, func() bool {
+		urls, err := p.GetAllURLs()
+		return err == nil && len(urls) == 0
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+type HTTPSDConfig struct {
+	// Endpoints returning target groups in the Prometheus http_sd format
+	URLs            []string        `toml:"urls"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+}
+
+func (c *HTTPSDConfig) init() error {
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = config.Duration(time.Minute)
+	}
+	for _, u := range c.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return fmt.Errorf("invalid http_sd URL %q: %w", u, err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("invalid http_sd URL %q: scheme must be http or https", u)
+		}
+	}
+	return nil
+}
+
+func (p *Prometheus) startHTTPSD(ctx context.Context) {
+	p.startDiscovery(ctx, "http_sd", time.Duration(p.HTTPSDConfig.RefreshInterval), p.refreshHTTPSD)
+}
+
+// refreshHTTPSD queries the targets of all configured endpoints. The previous
+// targets of an endpoint are kept if the query fails.
+func (p *Prometheus) refreshHTTPSD(ctx context.Context) error {
+	p.lock.Lock()
+	previous := p.httpSDTargets
+	p.lock.Unlock()
+
+	targets := make(map[string]map[string]URLAndAddress, len(p.HTTPSDConfig.URLs))
+	var errs []error
+	for _, u := range p.HTTPSDConfig.URLs {
+		urls, err := p.queryHTTPSD(ctx, u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("querying %q failed: %w", u, err))
+			if prev, found := previous[u]; found {
+				targets[u] = prev
+			}
+			continue
+		}
+		targets[u] = urls
+	}
+
+	p.lock.Lock()
+	p.httpSDTargets = targets
+	p.lock.Unlock()
+
+	return errors.Join(errs...)
+}
+
+func (p *Prometheus) queryHTTPSD(ctx context.Context, u string) (map[string]URLAndAddress, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	interval := time.Duration(p.HTTPSDConfig.RefreshInterval).Seconds()
+	req.Header.Set("X-Prometheus-Refresh-Interval-Seconds", strconv.FormatFloat(interval, 'f', -1, 64))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+	if contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || contentType != "application/json" {
+		return nil, fmt.Errorf("unsupported content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var groups []targetGroup
+	if err := json.Unmarshal(body, &groups); err != nil {
+		return nil, err
+	}
+	return targetGroupURLs(groups)
+}
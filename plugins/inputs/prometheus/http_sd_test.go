@@ -0,0 +1,94 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestHTTPSD(t *testing.T) {
+	var response atomic.Value
+	response.Store(`[
+		{"targets": ["10.0.0.1:9100", "10.0.0.2:9100"], "labels": {"env": "prod", "__meta_foo": "bar"}},
+		{"targets": ["10.0.0.3:9100"], "labels": {"env": "dev"}}
+	]`)
+	var failing atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/targets" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("X-Prometheus-Refresh-Interval-Seconds") != "60" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response.Load().(string)))
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		Log:          testutil.Logger{},
+		HTTPSDConfig: HTTPSDConfig{URLs: []string{ts.URL + "/targets"}},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.refreshHTTPSD(context.Background()))
+
+	urls, err := p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 3)
+	require.Equal(t, map[string]string{"env": "prod"}, urls["http://10.0.0.1:9100/metrics"].Tags)
+	require.Equal(t, map[string]string{"env": "prod"}, urls["http://10.0.0.2:9100/metrics"].Tags)
+	require.Equal(t, map[string]string{"env": "dev"}, urls["http://10.0.0.3:9100/metrics"].Tags)
+
+	// Removed targets are no longer scraped
+	response.Store(`[{"targets": ["10.0.0.1:9100"], "labels": {"env": "prod"}}]`)
+	require.NoError(t, p.refreshHTTPSD(context.Background()))
+
+	urls, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Contains(t, urls, "http://10.0.0.1:9100/metrics")
+
+	// Targets are kept if the endpoint fails
+	failing.Store(true)
+	require.ErrorContains(t, p.refreshHTTPSD(context.Background()), "500 Internal Server Error")
+
+	urls, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Contains(t, urls, "http://10.0.0.1:9100/metrics")
+}
+
+func TestHTTPSDInvalidContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		Log:          testutil.Logger{},
+		HTTPSDConfig: HTTPSDConfig{URLs: []string{ts.URL}},
+	}
+	require.NoError(t, p.Init())
+	require.ErrorContains(t, p.refreshHTTPSD(context.Background()), `unsupported content type "text/plain"`)
+}
+
+func TestHTTPSDInitFail(t *testing.T) {
+	p := &Prometheus{
+		Log:          testutil.Logger{},
+		HTTPSDConfig: HTTPSDConfig{URLs: []string{"ftp://localhost/targets"}},
+	}
+	require.ErrorContains(t, p.Init(), "scheme must be http or https")
+}
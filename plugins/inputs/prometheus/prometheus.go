@@ -64,6 +64,10 @@ type Prometheus struct {
 	// Consul SD configuration
 	ConsulConfig ConsulConfig `toml:"consul"`
 
+	// File and HTTP SD configuration
+	FileSDConfig FileSDConfig `toml:"file_sd"`
+	HTTPSDConfig HTTPSDConfig `toml:"http_sd"`
+
 	// Bearer Token authorization file path
 	BearerToken       string `toml:"bearer_token"`
 	BearerTokenString string `toml:"bearer_token_string"`
@@ -137,6 +141,10 @@ type Prometheus struct {
 
 	// List of consul services to scrape
 	consulServices map[string]URLAndAddress
+
+	// Targets discovered per file or HTTP endpoint
+	fileSDTargets map[string]map[string]URLAndAddress
+	httpSDTargets map[string]map[string]URLAndAddress
 }
 
 func (*Prometheus) SampleConfig() string {
@@ -208,6 +216,13 @@ func (p *Prometheus) Init() error {
 		return err
 	}
 
+	if err := p.FileSDConfig.init(); err != nil {
+		return err
+	}
+	if err := p.HTTPSDConfig.init(); err != nil {
+		return err
+	}
+
 	if p.MetricVersion == 0 {
 		p.MetricVersion = 1
 	}
@@ -308,6 +323,17 @@ func (p *Prometheus) GetAllURLs() (map[string]URLAndAddress, error) {
 	for k, v := range p.consulServices {
 		allURLs[k] = v
 	}
+	// add all targets discovered via files or HTTP endpoints
+	for _, targets := range p.fileSDTargets {
+		for k, v := range targets {
+			allURLs[k] = v
+		}
+	}
+	for _, targets := range p.httpSDTargets {
+		for k, v := range targets {
+			allURLs[k] = v
+		}
+	}
 	// loop through all pods scraped via the prometheus annotation on the pods
 	for _, v := range p.kubernetesPods {
 		if namespaceAnnotationMatch(v.Namespace, p) {
@@ -559,7 +585,8 @@ func fieldSelectorIsSupported(fieldSelector fields.Selector) (bool, string) {
 	return true, ""
 }
 
-// Start will start the Kubernetes and/or Consul scraping if enabled in the configuration
+// Start will start the Kubernetes, Consul, file and/or HTTP service discovery
+// if enabled in the configuration
 func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 	var ctx context.Context
 	p.wg = sync.WaitGroup{}
@@ -575,6 +602,12 @@ func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 			return err
 		}
 	}
+	if len(p.FileSDConfig.Files) > 0 {
+		p.startFileSD(ctx)
+	}
+	if len(p.HTTPSDConfig.URLs) > 0 {
+		p.startHTTPSD(ctx)
+	}
 	return nil
 }
 
@@ -0,0 +1,106 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Labels of a target group with special meaning as described in
+// https://prometheus.io/docs/prometheus/latest/configuration/configuration/#relabel_config
+const (
+	labelScheme      = "__scheme__"
+	labelMetricsPath = "__metrics_path__"
+	labelParamPrefix = "__param_"
+	labelReserved    = "__"
+)
+
+// targetGroup is a set of targets sharing the same labels as used by the file
+// and HTTP based service discovery of Prometheus
+type targetGroup struct {
+	Targets []string          `json:"targets" yaml:"targets"`
+	Labels  map[string]string `json:"labels" yaml:"labels"`
+}
+
+// targetGroupURLs converts the target groups to the URLs to scrape. Labels
+// starting with a double underscore are not added as tags.
+func targetGroupURLs(groups []targetGroup) (map[string]URLAndAddress, error) {
+	urls := make(map[string]URLAndAddress)
+	for _, group := range groups {
+		scheme := "http"
+		path := "/metrics"
+		params := url.Values{}
+		tags := make(map[string]string, len(group.Labels))
+		for k, v := range group.Labels {
+			switch {
+			case k == labelScheme:
+				scheme = v
+			case k == labelMetricsPath:
+				path = v
+			case strings.HasPrefix(k, labelParamPrefix):
+				params.Set(strings.TrimPrefix(k, labelParamPrefix), v)
+			case strings.HasPrefix(k, labelReserved):
+			default:
+				tags[k] = v
+			}
+		}
+		if scheme != "http" && scheme != "https" {
+			return nil, fmt.Errorf("invalid scheme %q", scheme)
+		}
+
+		for _, target := range group.Targets {
+			if target == "" || strings.Contains(target, "/") {
+				return nil, fmt.Errorf("invalid target %q", target)
+			}
+			u := &url.URL{
+				Scheme:   scheme,
+				Host:     target,
+				Path:     path,
+				RawQuery: params.Encode(),
+			}
+			urls[u.String()] = URLAndAddress{
+				URL:         u,
+				OriginalURL: u,
+				Tags:        tags,
+			}
+		}
+	}
+	return urls, nil
+}
+
+// startDiscovery periodically refreshes the targets of the given service
+// discovery mechanism until the context is cancelled
+func (p *Prometheus) startDiscovery(ctx context.Context, name string, interval time.Duration, refresh func(context.Context) error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		// Store last error status and change log level depending on repeated occurrence
+		refreshFailed := false
+		if err := refresh(ctx); err != nil {
+			refreshFailed = true
+			p.Log.Errorf("Unable to refresh %s targets: %v", name, err)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+				if err := refresh(ctx); err != nil {
+					message := fmt.Sprintf("Unable to refresh %s targets: %v", name, err)
+					if refreshFailed {
+						p.Log.Debug(message)
+					} else {
+						p.Log.Warn(message)
+					}
+					refreshFailed = true
+				} else if refreshFailed {
+					refreshFailed = false
+					p.Log.Infof("Successfully refreshed %s targets after previous errors", name)
+				}
+			}
+		}
+	}()
+}
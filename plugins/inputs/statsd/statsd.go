@@ -312,6 +312,117 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// state is the persisted state of the counters and sets
+type state struct {
+	Counters map[string]counterState `json:"counters,omitempty"`
+	Sets     map[string]setState     `json:"sets,omitempty"`
+}
+
+type counterState struct {
+	Name      string            `json:"name"`
+	Fields    map[string]int64  `json:"fields"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type setState struct {
+	Name      string              `json:"name"`
+	Fields    map[string][]string `json:"fields"`
+	Tags      map[string]string   `json:"tags,omitempty"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+func (s *Statsd) GetState() interface{} {
+	s.Lock()
+	defer s.Unlock()
+
+	st := state{
+		Counters: make(map[string]counterState, len(s.counters)),
+		Sets:     make(map[string]setState, len(s.sets)),
+	}
+	for hash, cached := range s.counters {
+		fields := make(map[string]int64, len(cached.fields))
+		for k, v := range cached.fields {
+			// Skip fields not being counters such as the start time
+			if value, ok := v.(int64); ok {
+				fields[k] = value
+			}
+		}
+		st.Counters[hash] = counterState{
+			Name:      cached.name,
+			Fields:    fields,
+			Tags:      cached.tags,
+			ExpiresAt: cached.expiresAt,
+		}
+	}
+	for hash, cached := range s.sets {
+		fields := make(map[string][]string, len(cached.fields))
+		for k, set := range cached.fields {
+			values := make([]string, 0, len(set))
+			for v := range set {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			fields[k] = values
+		}
+		st.Sets[hash] = setState{
+			Name:      cached.name,
+			Fields:    fields,
+			Tags:      cached.tags,
+			ExpiresAt: cached.expiresAt,
+		}
+	}
+	return st
+}
+
+func (s *Statsd) SetState(st interface{}) error {
+	restored, ok := st.(state)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", st)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.counters == nil {
+		s.counters = make(map[string]cachedcounter, len(restored.Counters))
+	}
+	for hash, c := range restored.Counters {
+		fields := make(map[string]interface{}, len(c.Fields))
+		for k, v := range c.Fields {
+			fields[k] = v
+		}
+		s.counters[hash] = cachedcounter{
+			name:      c.Name,
+			fields:    fields,
+			tags:      c.Tags,
+			expiresAt: c.ExpiresAt,
+		}
+	}
+
+	if s.sets == nil {
+		s.sets = make(map[string]cachedset, len(restored.Sets))
+	}
+	for hash, c := range restored.Sets {
+		fields := make(map[string]map[string]bool, len(c.Fields))
+		for k, values := range c.Fields {
+			set := make(map[string]bool, len(values))
+			for _, v := range values {
+				set[v] = true
+			}
+			fields[k] = set
+		}
+		s.sets[hash] = cachedset{
+			name:      c.Name,
+			fields:    fields,
+			tags:      c.Tags,
+			expiresAt: c.ExpiresAt,
+		}
+	}
+
+	return nil
+}
+
 func (s *Statsd) Start(ac telegraf.Accumulator) error {
 	if s.ParseDataDogTags {
 		s.DataDogExtensions = true
@@ -319,11 +430,16 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 
 	s.acc = ac
 
-	// Make data structures
+	// Make data structures, counters and sets might already be restored
+	// from a persisted state
 	s.lastGatherTime = time.Now()
 	s.gauges = make(map[string]cachedgauge)
-	s.counters = make(map[string]cachedcounter)
-	s.sets = make(map[string]cachedset)
+	if s.counters == nil {
+		s.counters = make(map[string]cachedcounter)
+	}
+	if s.sets == nil {
+		s.sets = make(map[string]cachedset)
+	}
 	s.timings = make(map[string]cachedtimings)
 	s.distributions = make([]cacheddistributions, 0)
 
@@ -3,6 +3,7 @@ package statsd
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -532,6 +534,47 @@ func TestParse_Counters(t *testing.T) {
 	}
 }
 
+// Tests that counters and sets survive a restart
+func TestStatePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	s := NewTestStatsd()
+	for _, line := range []string{
+		"small.inc:1|c",
+		"big.inc:100|c",
+		"unique.user.ids:100|s",
+		"unique.user.ids:101|s",
+		"string.sets:foobar|s",
+	} {
+		require.NoError(t, s.parseStatsdLine(line))
+	}
+
+	p := &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("statsd", s))
+	require.NoError(t, p.Store())
+
+	// Restore the state into a new instance and continue counting
+	s = NewTestStatsd()
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("statsd", s))
+	require.NoError(t, p.Load())
+
+	for _, line := range []string{
+		"small.inc:1|c",
+		"unique.user.ids:100|s",
+		"unique.user.ids:102|s",
+	} {
+		require.NoError(t, s.parseStatsdLine(line))
+	}
+
+	require.NoError(t, testValidateCounter("small_inc", 2, s.counters))
+	require.NoError(t, testValidateCounter("big_inc", 100, s.counters))
+	require.NoError(t, testValidateSet("unique_user_ids", 3, s.sets))
+	require.NoError(t, testValidateSet("string_sets", 1, s.sets))
+}
+
 // Tests low-level functionality of timings
 func TestParse_Timings(t *testing.T) {
 	s := NewTestStatsd()
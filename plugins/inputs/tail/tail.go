@@ -9,6 +9,7 @@ import (
 	_ "embed"
 	"errors"
 	"io"
+	"maps"
 	"strings"
 	"sync"
 	"time"
@@ -52,11 +53,15 @@ type Tail struct {
 	filterColors bool
 
 	Log        telegraf.Logger `toml:"-"`
-	tailers    map[string]*tail.Tail
-	offsets    map[string]int64
 	parserFunc telegraf.ParserFunc
 	wg         sync.WaitGroup
 
+	// The state might be requested while the plugin is running, so access to
+	// the tailers and offsets is guarded by tailersMutex.
+	tailers      map[string]*tail.Tail
+	offsets      map[string]int64
+	tailersMutex sync.Mutex
+
 	acc telegraf.TrackingAccumulator
 
 	MultilineConfig MultilineConfig `toml:"multiline"`
@@ -108,7 +113,13 @@ func (t *Tail) Init() error {
 }
 
 func (t *Tail) GetState() interface{} {
-	return t.offsets
+	t.tailersMutex.Lock()
+	defer t.tailersMutex.Unlock()
+
+	// Include the current position of the tailers as the state is also
+	// persisted periodically while the plugin is running.
+	t.recordOffsets()
+	return maps.Clone(t.offsets)
 }
 
 func (t *Tail) SetState(state interface{}) error {
@@ -164,6 +175,9 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 }
 
 func (t *Tail) tailNewFiles(fromBeginning bool) error {
+	t.tailersMutex.Lock()
+	defer t.tailersMutex.Unlock()
+
 	var poll bool
 	if t.WatchMethod == "poll" {
 		poll = true
@@ -237,7 +251,9 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 				if err := tailer.Err(); err != nil {
 					if strings.HasSuffix(err.Error(), "permission denied") {
 						t.Log.Errorf("Deleting tailer for %q due to: %v", tailer.Filename, err)
+						t.tailersMutex.Lock()
 						delete(t.tailers, tailer.Filename)
+						t.tailersMutex.Unlock()
 					} else {
 						t.Log.Errorf("Tailing %q: %s", tailer.Filename, err.Error())
 					}
@@ -369,33 +385,46 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 	}
 }
 
-func (t *Tail) Stop() {
+// recordOffsets stores the current offsets of all tailers for resuming.
+func (t *Tail) recordOffsets() {
+	if t.Pipe || t.FromBeginning {
+		return
+	}
+
 	for _, tailer := range t.tailers {
-		if !t.Pipe && !t.FromBeginning {
-			// store offset for resume
-			offset, err := tailer.Tell()
-			if err == nil {
-				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
-				t.offsets[tailer.Filename] = offset
-			} else {
-				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
-			}
+		offset, err := tailer.Tell()
+		if err != nil {
+			t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
+			continue
 		}
+		t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
+		t.offsets[tailer.Filename] = offset
+	}
+}
+
+func (t *Tail) Stop() {
+	t.tailersMutex.Lock()
+	// store offset for resume
+	t.recordOffsets()
+	for _, tailer := range t.tailers {
 		err := tailer.Stop()
 		if err != nil {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
 		}
 	}
+	t.tailersMutex.Unlock()
 
 	t.cancel()
 	t.wg.Wait()
 
 	// persist offsets
+	t.tailersMutex.Lock()
 	offsetsMutex.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
 	offsetsMutex.Unlock()
+	t.tailersMutex.Unlock()
 }
 
 func (t *Tail) SetParserFunc(fn telegraf.ParserFunc) {
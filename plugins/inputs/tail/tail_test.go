@@ -685,6 +685,32 @@ func TestTailEOF(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestStateWhileRunning(t *testing.T) {
+	content := "cpu usage_idle=100\ncpu usage_idle=200\n"
+	filename := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0640))
+
+	tt := NewTestTail()
+	tt.Log = testutil.Logger{}
+	tt.Files = []string{filename}
+	tt.SetParserFunc(NewInfluxParser)
+	require.NoError(t, tt.Init())
+
+	// Start reading at the beginning of the file
+	require.NoError(t, tt.SetState(map[string]int64{filename: 0}))
+
+	var acc testutil.Accumulator
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+	acc.Wait(2)
+
+	// The state must reflect the lines read without stopping the plugin
+	require.Eventually(t, func() bool {
+		state, ok := tt.GetState().(map[string]int64)
+		return ok && state[filename] == int64(len(content))
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestCSVBehavior(t *testing.T) {
 	// Prepare the input file
 	input, err := os.CreateTemp("", "")
@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	_ "embed"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -115,15 +116,34 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 		return h.writeMetric(reqBody)
 	}
 
-	for _, metric := range metrics {
+	// Each metric is sent in a separate request, so only the metrics of
+	// failed requests are retried or rejected
+	var accept, reject []int
+	var rejectErr error
+	for i, metric := range metrics {
 		reqBody, err := h.serializer.Serialize(metric)
-		if err != nil {
-			return err
+		if err == nil {
+			err = h.writeMetric(reqBody)
+		}
+		if err == nil {
+			accept = append(accept, i)
+			continue
 		}
 
-		if err := h.writeMetric(reqBody); err != nil {
+		var rerr *internal.RejectError
+		if errors.As(err, &rerr) {
+			reject = append(reject, i)
+			rejectErr = err
+			continue
+		}
+		if len(accept) == 0 && len(reject) == 0 {
 			return err
 		}
+		return &internal.PartialWriteError{Err: err, MetricsAccept: accept, MetricsReject: reject}
+	}
+
+	if len(reject) > 0 {
+		return &internal.PartialWriteError{Err: rejectErr, MetricsAccept: accept, MetricsReject: reject}
 	}
 	return nil
 }
@@ -229,8 +249,7 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		for _, nonRetryableStatusCode := range h.NonRetryableStatusCodes {
 			if resp.StatusCode == nonRetryableStatusCode {
-				h.Log.Errorf("Received non-retryable status %v. Metrics are lost.", resp.StatusCode)
-				return nil
+				return &internal.RejectError{Err: fmt.Errorf("received non-retryable status %v", resp.StatusCode)}
 			}
 		}
 
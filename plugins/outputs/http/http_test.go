@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -258,7 +259,8 @@ func TestStatusCode(t *testing.T) {
 			},
 			statusCode: http.StatusConflict,
 			errFunc: func(t *testing.T, err error) {
-				require.NoError(t, err)
+				var rejectErr *internal.RejectError
+				require.ErrorAs(t, err, &rejectErr)
 			},
 		},
 	}
@@ -283,6 +285,60 @@ func TestStatusCode(t *testing.T) {
 	}
 }
 
+func TestNonBatchPartialWrite(t *testing.T) {
+	// Reject the second and fail the fourth metric
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, string(body))
+		switch {
+		case strings.HasPrefix(string(body), "rejected"):
+			w.WriteHeader(http.StatusConflict)
+		case strings.HasPrefix(string(body), "failing"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin := &HTTP{
+		URL:                     ts.URL,
+		Method:                  defaultMethod,
+		NonRetryableStatusCodes: []int{http.StatusConflict},
+		Log:                     testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("ok", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("rejected", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		testutil.MustMetric("ok", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		testutil.MustMetric("failing", map[string]string{}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+		testutil.MustMetric("ok", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+	}
+
+	// Writing stops at the first retryable failure
+	err := plugin.Write(metrics)
+	var partialErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, []int{0, 2}, partialErr.MetricsAccept)
+	require.Equal(t, []int{1}, partialErr.MetricsReject)
+	require.Len(t, requests, 4)
+
+	// Rejected metrics alone are reported as partial write as well
+	requests = nil
+	err = plugin.Write([]telegraf.Metric{metrics[0], metrics[1]})
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, []int{0}, partialErr.MetricsAccept)
+	require.Equal(t, []int{1}, partialErr.MetricsReject)
+	require.Len(t, requests, 2)
+}
+
 func TestContentType(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
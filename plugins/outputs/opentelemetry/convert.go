@@ -0,0 +1,410 @@
+package opentelemetry
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Attributes written by the opentelemetry input not covered by the common
+// package
+const (
+	attributeStatusCode        = "otel.status_code"
+	attributeStatusDescription = "otel.status_description"
+)
+
+// resourceGroups collects the converted records per resource where the
+// resource is identified by the tags of the metric except the trace and span
+// IDs.
+type resourceGroups struct {
+	index map[string]int
+}
+
+func (g *resourceGroups) key(m telegraf.Metric) string {
+	var sb strings.Builder
+	for _, tag := range m.TagList() {
+		if isIDTag(tag.Key) {
+			continue
+		}
+		sb.WriteString(tag.Key)
+		sb.WriteByte(0)
+		sb.WriteString(tag.Value)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// lookup returns the index of the resource of the metric and true if the
+// resource did not exist before.
+func (g *resourceGroups) lookup(m telegraf.Metric, next int) (int, bool) {
+	if g.index == nil {
+		g.index = make(map[string]int)
+	}
+	key := g.key(m)
+	if idx, found := g.index[key]; found {
+		return idx, false
+	}
+	g.index[key] = next
+	return next, true
+}
+
+func isIDTag(key string) bool {
+	switch key {
+	case common.AttributeTraceID, common.AttributeSpanID, common.AttributeLinkedTraceID, common.AttributeLinkedSpanID:
+		return true
+	}
+	return false
+}
+
+// setResource sets the non-ID tags of the metric and the additional
+// attributes as attributes of the resource
+func setResource(resource pcommon.Resource, m telegraf.Metric, attributes map[string]string) {
+	for _, tag := range m.TagList() {
+		if !isIDTag(tag.Key) {
+			resource.Attributes().PutStr(tag.Key, tag.Value)
+		}
+	}
+	for k, v := range attributes {
+		resource.Attributes().PutStr(k, v)
+	}
+}
+
+// convertTraces converts the spans and span links written by the
+// opentelemetry input back to OTLP traces. Links are attached to the spans
+// of the same batch and dropped with a warning otherwise.
+func (o *OpenTelemetry) convertTraces(spans, links []telegraf.Metric) ptrace.Traces {
+	td := ptrace.NewTraces()
+
+	var groups resourceGroups
+	converted := make(map[string]ptrace.Span, len(spans))
+	for _, m := range spans {
+		span := ptrace.NewSpan()
+		if err := convertSpan(m, span); err != nil {
+			o.Log.Warnf("Dropping span: %v", err)
+			continue
+		}
+
+		idx, created := groups.lookup(m, td.ResourceSpans().Len())
+		if created {
+			rs := td.ResourceSpans().AppendEmpty()
+			setResource(rs.Resource(), m, o.Attributes)
+			rs.ScopeSpans().AppendEmpty()
+		}
+		target := td.ResourceSpans().At(idx).ScopeSpans().At(0).Spans().AppendEmpty()
+		span.MoveTo(target)
+
+		traceID, spanID := target.TraceID(), target.SpanID()
+		converted[hex.EncodeToString(traceID[:])+hex.EncodeToString(spanID[:])] = target
+	}
+
+	var dropped int
+	for _, m := range links {
+		traceID, _ := m.GetTag(common.AttributeTraceID)
+		spanID, _ := m.GetTag(common.AttributeSpanID)
+		span, found := converted[traceID+spanID]
+		if !found {
+			o.Log.Debugf("Dropping link of span %s in trace %s not contained in batch", spanID, traceID)
+			dropped++
+			continue
+		}
+		link := ptrace.NewSpanLink()
+		if err := convertSpanLink(m, link); err != nil {
+			o.Log.Warnf("Dropping span link: %v", err)
+			continue
+		}
+		link.MoveTo(span.Links().AppendEmpty())
+	}
+	if dropped > 0 {
+		o.Log.Warnf("Dropping %d span links of spans not contained in the batch", dropped)
+	}
+
+	return td
+}
+
+func convertSpan(m telegraf.Metric, span ptrace.Span) error {
+	traceID, err := parseTraceID(m)
+	if err != nil {
+		return err
+	}
+	spanID, err := parseSpanID(m, common.AttributeSpanID)
+	if err != nil {
+		return err
+	}
+
+	span.SetTraceID(traceID)
+	span.SetSpanID(spanID)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+	for _, field := range m.FieldList() {
+		switch field.Key {
+		case common.AttributeSpanName:
+			span.SetName(fmt.Sprint(field.Value))
+		case common.AttributeSpanKind:
+			span.SetKind(parseSpanKind(fmt.Sprint(field.Value)))
+		case common.AttributeParentSpanID:
+			id, err := decodeSpanID(fmt.Sprint(field.Value))
+			if err != nil {
+				return fmt.Errorf("invalid parent span ID: %w", err)
+			}
+			span.SetParentSpanID(id)
+		case common.AttributeTraceState:
+			span.TraceState().FromRaw(fmt.Sprint(field.Value))
+		case common.AttributeEndTimeUnixNano:
+			if v, ok := toInt64(field.Value); ok {
+				span.SetEndTimestamp(pcommon.Timestamp(v))
+			}
+		case common.AttributeAttributes:
+			if err := setAttributes(span.Attributes(), field.Value); err != nil {
+				return err
+			}
+		case common.AttributeDroppedAttributesCount:
+			if v, ok := toInt64(field.Value); ok {
+				span.SetDroppedAttributesCount(uint32(v))
+			}
+		case common.AttributeDroppedEventsCount:
+			if v, ok := toInt64(field.Value); ok {
+				span.SetDroppedEventsCount(uint32(v))
+			}
+		case common.AttributeDroppedLinksCount:
+			if v, ok := toInt64(field.Value); ok {
+				span.SetDroppedLinksCount(uint32(v))
+			}
+		case attributeStatusCode:
+			span.Status().SetCode(parseStatusCode(fmt.Sprint(field.Value)))
+		case attributeStatusDescription:
+			span.Status().SetMessage(fmt.Sprint(field.Value))
+		}
+	}
+
+	return nil
+}
+
+func convertSpanLink(m telegraf.Metric, link ptrace.SpanLink) error {
+	linkedTraceID, found := m.GetTag(common.AttributeLinkedTraceID)
+	if !found {
+		return errors.New("missing linked trace ID")
+	}
+	traceID, err := decodeTraceID(linkedTraceID)
+	if err != nil {
+		return fmt.Errorf("invalid linked trace ID: %w", err)
+	}
+	spanID, err := parseSpanID(m, common.AttributeLinkedSpanID)
+	if err != nil {
+		return err
+	}
+
+	link.SetTraceID(traceID)
+	link.SetSpanID(spanID)
+	for _, field := range m.FieldList() {
+		switch field.Key {
+		case common.AttributeTraceState:
+			link.TraceState().FromRaw(fmt.Sprint(field.Value))
+		case common.AttributeAttributes:
+			if err := setAttributes(link.Attributes(), field.Value); err != nil {
+				return err
+			}
+		case common.AttributeDroppedAttributesCount:
+			if v, ok := toInt64(field.Value); ok {
+				link.SetDroppedAttributesCount(uint32(v))
+			}
+		}
+	}
+	return nil
+}
+
+// convertLogs converts the log records written by the opentelemetry input
+// back to OTLP logs. Span events are converted to log records as they are
+// stored in the same measurement.
+func (o *OpenTelemetry) convertLogs(records []telegraf.Metric) plog.Logs {
+	ld := plog.NewLogs()
+
+	var groups resourceGroups
+	for _, m := range records {
+		record := plog.NewLogRecord()
+		if err := convertLogRecord(m, record); err != nil {
+			o.Log.Warnf("Dropping log record: %v", err)
+			continue
+		}
+
+		idx, created := groups.lookup(m, ld.ResourceLogs().Len())
+		if created {
+			rl := ld.ResourceLogs().AppendEmpty()
+			setResource(rl.Resource(), m, o.Attributes)
+			rl.ScopeLogs().AppendEmpty()
+		}
+		record.MoveTo(ld.ResourceLogs().At(idx).ScopeLogs().At(0).LogRecords().AppendEmpty())
+	}
+	return ld
+}
+
+func convertLogRecord(m telegraf.Metric, record plog.LogRecord) error {
+	record.SetTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+
+	if m.HasTag(common.AttributeTraceID) {
+		traceID, err := parseTraceID(m)
+		if err != nil {
+			return err
+		}
+		record.SetTraceID(traceID)
+		if m.HasTag(common.AttributeSpanID) {
+			spanID, err := parseSpanID(m, common.AttributeSpanID)
+			if err != nil {
+				return err
+			}
+			record.SetSpanID(spanID)
+		}
+	}
+
+	for _, field := range m.FieldList() {
+		switch field.Key {
+		case common.AttributeSeverityNumber:
+			if v, ok := toInt64(field.Value); ok {
+				record.SetSeverityNumber(plog.SeverityNumber(v))
+			}
+		case common.AttributeSeverityText:
+			record.SetSeverityText(fmt.Sprint(field.Value))
+		case common.AttributeBody:
+			if err := record.Body().FromRaw(field.Value); err != nil {
+				return fmt.Errorf("invalid body: %w", err)
+			}
+		case common.AttributeAttributes:
+			if err := setAttributes(record.Attributes(), field.Value); err != nil {
+				return err
+			}
+		case common.AttributeDroppedAttributesCount:
+			if v, ok := toInt64(field.Value); ok {
+				record.SetDroppedAttributesCount(uint32(v))
+			}
+		default:
+			// Keep other fields such as the name of span events
+			if err := record.Attributes().PutEmpty(field.Key).FromRaw(field.Value); err != nil {
+				return fmt.Errorf("invalid field %q: %w", field.Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setAttributes decodes the JSON encoded attributes into the given map
+func setAttributes(attrs pcommon.Map, value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid attributes type %T", value)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return fmt.Errorf("decoding attributes failed: %w", err)
+	}
+
+	// Keep the order stable for reproducible requests
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := attrs.PutEmpty(k).FromRaw(raw[k]); err != nil {
+			return fmt.Errorf("invalid attribute %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func parseTraceID(m telegraf.Metric) (pcommon.TraceID, error) {
+	value, found := m.GetTag(common.AttributeTraceID)
+	if !found {
+		return pcommon.NewTraceIDEmpty(), errors.New("missing trace ID")
+	}
+	id, err := decodeTraceID(value)
+	if err != nil {
+		return id, fmt.Errorf("invalid trace ID: %w", err)
+	}
+	return id, nil
+}
+
+func parseSpanID(m telegraf.Metric, key string) (pcommon.SpanID, error) {
+	value, found := m.GetTag(key)
+	if !found {
+		return pcommon.NewSpanIDEmpty(), fmt.Errorf("missing %q tag", key)
+	}
+	id, err := decodeSpanID(value)
+	if err != nil {
+		return id, fmt.Errorf("invalid %q tag: %w", key, err)
+	}
+	return id, nil
+}
+
+func decodeTraceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(buf) != len(id) || pcommon.TraceID(buf).IsEmpty() {
+		return id, fmt.Errorf("%q is not a valid trace ID", s)
+	}
+	return pcommon.TraceID(buf), nil
+}
+
+func decodeSpanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(buf) != len(id) || pcommon.SpanID(buf).IsEmpty() {
+		return id, fmt.Errorf("%q is not a valid span ID", s)
+	}
+	return pcommon.SpanID(buf), nil
+}
+
+// parseSpanKind accepts the current and the legacy string representation of
+// the span kind, e.g. "Server" and "SPAN_KIND_SERVER"
+func parseSpanKind(s string) ptrace.SpanKind {
+	for _, kind := range []ptrace.SpanKind{
+		ptrace.SpanKindInternal,
+		ptrace.SpanKindServer,
+		ptrace.SpanKindClient,
+		ptrace.SpanKindProducer,
+		ptrace.SpanKindConsumer,
+	} {
+		name := kind.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, "SPAN_KIND_"+name) {
+			return kind
+		}
+	}
+	return ptrace.SpanKindUnspecified
+}
+
+// parseStatusCode accepts the current and the legacy string representation
+// of the status code, e.g. "Error" and "STATUS_CODE_ERROR"
+func parseStatusCode(s string) ptrace.StatusCode {
+	for _, code := range []ptrace.StatusCode{ptrace.StatusCodeOk, ptrace.StatusCodeError} {
+		name := code.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, "STATUS_CODE_"+name) {
+			return code
+		}
+	}
+	return ptrace.StatusCodeUnset
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
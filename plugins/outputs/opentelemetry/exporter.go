@@ -0,0 +1,147 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// exporter sends OTLP requests to the service using a specific transport
+type exporter interface {
+	exportMetrics(ctx context.Context, req pmetricotlp.ExportRequest) error
+	exportTraces(ctx context.Context, req ptraceotlp.ExportRequest) error
+	exportLogs(ctx context.Context, req plogotlp.ExportRequest) error
+	close() error
+}
+
+// grpcExporter sends the requests using OTLP/gRPC
+type grpcExporter struct {
+	conn        *grpc.ClientConn
+	metrics     pmetricotlp.GRPCClient
+	traces      ptraceotlp.GRPCClient
+	logs        plogotlp.GRPCClient
+	headers     map[string]string
+	callOptions []grpc.CallOption
+}
+
+func newGRPCExporter(conn *grpc.ClientConn, headers map[string]string, callOptions ...grpc.CallOption) *grpcExporter {
+	return &grpcExporter{
+		conn:        conn,
+		metrics:     pmetricotlp.NewGRPCClient(conn),
+		traces:      ptraceotlp.NewGRPCClient(conn),
+		logs:        plogotlp.NewGRPCClient(conn),
+		headers:     headers,
+		callOptions: callOptions,
+	}
+}
+
+func (e *grpcExporter) context(ctx context.Context) context.Context {
+	if len(e.headers) > 0 {
+		return metadata.NewOutgoingContext(ctx, metadata.New(e.headers))
+	}
+	return ctx
+}
+
+func (e *grpcExporter) exportMetrics(ctx context.Context, req pmetricotlp.ExportRequest) error {
+	_, err := e.metrics.Export(e.context(ctx), req, e.callOptions...)
+	return err
+}
+
+func (e *grpcExporter) exportTraces(ctx context.Context, req ptraceotlp.ExportRequest) error {
+	_, err := e.traces.Export(e.context(ctx), req, e.callOptions...)
+	return err
+}
+
+func (e *grpcExporter) exportLogs(ctx context.Context, req plogotlp.ExportRequest) error {
+	_, err := e.logs.Export(e.context(ctx), req, e.callOptions...)
+	return err
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// httpExporter sends the requests using OTLP/HTTP with binary protobuf
+// encoding as described in https://opentelemetry.io/docs/specs/otlp/#otlphttp
+type httpExporter struct {
+	client   *http.Client
+	url      string
+	headers  map[string]string
+	encoder  internal.ContentEncoder
+	encoding string
+}
+
+func (e *httpExporter) exportMetrics(ctx context.Context, req pmetricotlp.ExportRequest) error {
+	body, err := req.MarshalProto()
+	if err != nil {
+		return err
+	}
+	return e.post(ctx, "/v1/metrics", body)
+}
+
+func (e *httpExporter) exportTraces(ctx context.Context, req ptraceotlp.ExportRequest) error {
+	body, err := req.MarshalProto()
+	if err != nil {
+		return err
+	}
+	return e.post(ctx, "/v1/traces", body)
+}
+
+func (e *httpExporter) exportLogs(ctx context.Context, req plogotlp.ExportRequest) error {
+	body, err := req.MarshalProto()
+	if err != nil {
+		return err
+	}
+	return e.post(ctx, "/v1/logs", body)
+}
+
+func (e *httpExporter) post(ctx context.Context, path string, body []byte) error {
+	body, err := e.encoder.Encode(body)
+	if err != nil {
+		return fmt.Errorf("compressing request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", userAgent)
+	if e.encoding != "identity" {
+		req.Header.Set("Content-Encoding", e.encoding)
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		// Sending the same data again will fail in the same way
+		return &internal.RejectError{Err: fmt.Errorf("exporting to %q failed: %s", path, resp.Status)}
+	}
+	return fmt.Errorf("exporting to %q failed: %s", path, resp.Status)
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
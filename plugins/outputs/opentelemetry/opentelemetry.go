@@ -5,17 +5,22 @@ import (
 	"context"
 	ntls "crypto/tls"
 	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	_ "google.golang.org/grpc/encoding/gzip" // Blank import to allow gzip encoding
-	"google.golang.org/grpc/metadata"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -30,7 +35,9 @@ var userAgent = internal.ProductToken()
 var sampleConfig string
 
 type OpenTelemetry struct {
-	ServiceAddress string `toml:"service_address"`
+	ServiceAddress      string `toml:"service_address"`
+	Protocol            string `toml:"protocol"`
+	ExportTracesAndLogs bool   `toml:"export_traces_and_logs"`
 
 	tls.ClientConfig
 	Timeout     config.Duration   `toml:"timeout"`
@@ -41,10 +48,8 @@ type OpenTelemetry struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	metricsConverter     *influx2otel.LineProtocolToOtelMetrics
-	grpcClientConn       *grpc.ClientConn
-	metricsServiceClient pmetricotlp.GRPCClient
-	callOptions          []grpc.CallOption
+	metricsConverter *influx2otel.LineProtocolToOtelMetrics
+	exporter         exporter
 }
 
 type CoralogixConfig struct {
@@ -57,11 +62,34 @@ func (*OpenTelemetry) SampleConfig() string {
 	return sampleConfig
 }
 
+func (o *OpenTelemetry) Init() error {
+	switch o.Protocol {
+	case "":
+		o.Protocol = "grpc"
+	case "grpc", "http":
+	default:
+		return fmt.Errorf("invalid protocol %q", o.Protocol)
+	}
+
+	// OTLP/HTTP only defines gzip compression
+	if o.Protocol == "http" {
+		switch o.Compression {
+		case "", "gzip", "none":
+		default:
+			return fmt.Errorf("invalid compression %q for protocol %q", o.Compression, o.Protocol)
+		}
+	}
+	return nil
+}
+
 func (o *OpenTelemetry) Connect() error {
 	logger := &otelLogger{o.Log}
 
 	if o.ServiceAddress == "" {
 		o.ServiceAddress = defaultServiceAddress
+		if o.Protocol == "http" {
+			o.ServiceAddress = defaultHTTPServiceAddress
+		}
 	}
 	if o.Timeout <= 0 {
 		o.Timeout = defaultTimeout
@@ -83,10 +111,30 @@ func (o *OpenTelemetry) Connect() error {
 		return err
 	}
 
-	var grpcTLSDialOption grpc.DialOption
-	if tlsConfig, err := o.ClientConfig.TLSConfig(); err != nil {
+	tlsConfig, err := o.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	var exp exporter
+	if o.Protocol == "http" {
+		exp, err = o.newHTTPExporter(tlsConfig)
+	} else {
+		exp, err = o.newGRPCExporter(tlsConfig)
+	}
+	if err != nil {
 		return err
-	} else if tlsConfig != nil {
+	}
+
+	o.metricsConverter = metricsConverter
+	o.exporter = exp
+
+	return nil
+}
+
+func (o *OpenTelemetry) newGRPCExporter(tlsConfig *ntls.Config) (*grpcExporter, error) {
+	var grpcTLSDialOption grpc.DialOption
+	if tlsConfig != nil {
 		grpcTLSDialOption = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
 	} else if o.Coralogix != nil {
 		// For coralogix, we enforce GRPC connection with TLS
@@ -97,41 +145,116 @@ func (o *OpenTelemetry) Connect() error {
 
 	grpcClientConn, err := grpc.Dial(o.ServiceAddress, grpcTLSDialOption, grpc.WithUserAgent(userAgent))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	metricsServiceClient := pmetricotlp.NewGRPCClient(grpcClientConn)
+	var callOptions []grpc.CallOption
+	if o.Compression != "" && o.Compression != "none" {
+		callOptions = append(callOptions, grpc.UseCompressor(o.Compression))
+	}
 
-	o.metricsConverter = metricsConverter
-	o.grpcClientConn = grpcClientConn
-	o.metricsServiceClient = metricsServiceClient
+	return newGRPCExporter(grpcClientConn, o.Headers, callOptions...), nil
+}
 
+func (o *OpenTelemetry) newHTTPExporter(tlsConfig *ntls.Config) (*httpExporter, error) {
+	if tlsConfig == nil && o.Coralogix != nil {
+		// For coralogix, we enforce a connection with TLS
+		tlsConfig = &ntls.Config{}
+	}
+
+	// Use the scheme matching the TLS settings if none is given
+	address := strings.TrimSuffix(o.ServiceAddress, "/")
+	if !strings.Contains(address, "://") {
+		if tlsConfig != nil {
+			address = "https://" + address
+		} else {
+			address = "http://" + address
+		}
+	}
+
+	encoding := "identity"
 	if o.Compression != "" && o.Compression != "none" {
-		o.callOptions = append(o.callOptions, grpc.UseCompressor(o.Compression))
+		encoding = o.Compression
+	}
+	encoder, err := internal.NewContentEncoder(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("creating encoder for %q failed: %w", o.Compression, err)
 	}
 
-	return nil
+	return &httpExporter{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: time.Duration(o.Timeout),
+		},
+		url:      address,
+		headers:  o.Headers,
+		encoder:  encoder,
+		encoding: encoding,
+	}, nil
 }
 
 func (o *OpenTelemetry) Close() error {
-	if o.grpcClientConn != nil {
-		err := o.grpcClientConn.Close()
-		o.grpcClientConn = nil
+	if o.exporter != nil {
+		err := o.exporter.close()
+		o.exporter = nil
 		return err
 	}
 	return nil
 }
 
-// Split metrics up by timestamp and send to Google Cloud Stackdriver
+// signalGroup is a part of a batch exported in a single request
+type signalGroup struct {
+	indices []int
+	send    func() error
+}
+
+// Write exports the metrics split up by signal and timestamp. If some of the
+// requests fail, the metrics of the successful requests are reported as
+// written so only the failed ones are retried.
 func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
-	metricBatch := make(map[int64][]telegraf.Metric)
+	var spans, links, logs, remaining []int
+	for i, metric := range metrics {
+		if o.ExportTracesAndLogs {
+			switch metric.Name() {
+			case common.MeasurementSpans:
+				spans = append(spans, i)
+				continue
+			case common.MeasurementSpanLinks:
+				links = append(links, i)
+				continue
+			case common.MeasurementLogs:
+				logs = append(logs, i)
+				continue
+			}
+		}
+		remaining = append(remaining, i)
+	}
+
+	var groups []signalGroup
+	if len(spans) > 0 || len(links) > 0 {
+		groups = append(groups, signalGroup{
+			indices: append(spans, links...),
+			send:    func() error { return o.sendTraces(selectMetrics(metrics, spans), selectMetrics(metrics, links)) },
+		})
+	}
+	if len(logs) > 0 {
+		groups = append(groups, signalGroup{
+			indices: logs,
+			send:    func() error { return o.sendLogs(selectMetrics(metrics, logs)) },
+		})
+	}
+
+	metricBatch := make(map[int64][]int)
 	timestamps := []int64{}
-	for _, metric := range metrics {
-		timestamp := metric.Time().UnixNano()
+	for _, idx := range remaining {
+		timestamp := metrics[idx].Time().UnixNano()
 		if existingSlice, ok := metricBatch[timestamp]; ok {
-			metricBatch[timestamp] = append(existingSlice, metric)
+			metricBatch[timestamp] = append(existingSlice, idx)
 		} else {
-			metricBatch[timestamp] = []telegraf.Metric{metric}
+			metricBatch[timestamp] = []int{idx}
 			timestamps = append(timestamps, timestamp)
 		}
 	}
@@ -139,14 +262,50 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 	// sort the timestamps we collected
 	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
 
-	o.Log.Debugf("Received %d metrics and split into %d groups by timestamp", len(metrics), len(metricBatch))
+	o.Log.Debugf("Received %d metrics and split into %d groups by timestamp", len(remaining), len(metricBatch))
 	for _, timestamp := range timestamps {
-		if err := o.sendBatch(metricBatch[timestamp]); err != nil {
-			return err
+		indices := metricBatch[timestamp]
+		groups = append(groups, signalGroup{
+			indices: indices,
+			send:    func() error { return o.sendBatch(selectMetrics(metrics, indices)) },
+		})
+	}
+
+	var errs []error
+	var accept, reject []int
+	for _, group := range groups {
+		err := group.send()
+		if err == nil {
+			accept = append(accept, group.indices...)
+			continue
+		}
+		errs = append(errs, err)
+
+		var rejectErr *internal.RejectError
+		if errors.As(err, &rejectErr) {
+			reject = append(reject, group.indices...)
 		}
 	}
 
-	return nil
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(accept) == 0 && len(reject) == 0:
+		return errors.Join(errs...)
+	}
+	return &internal.PartialWriteError{
+		Err:           errors.Join(errs...),
+		MetricsAccept: accept,
+		MetricsReject: reject,
+	}
+}
+
+func selectMetrics(metrics []telegraf.Metric, indices []int) []telegraf.Metric {
+	selected := make([]telegraf.Metric, 0, len(indices))
+	for _, idx := range indices {
+		selected = append(selected, metrics[idx])
+	}
+	return selected
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
@@ -189,27 +348,55 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+	return o.exporter.exportMetrics(ctx, md)
+}
+
+func (o *OpenTelemetry) sendTraces(spans, links []telegraf.Metric) error {
+	if len(spans) == 0 {
+		if len(links) > 0 {
+			o.Log.Warnf("Dropping %d span links of spans not contained in the batch", len(links))
+		}
+		return nil
+	}
+
+	td := ptraceotlp.NewExportRequestFromTraces(o.convertTraces(spans, links))
+	if td.Traces().SpanCount() == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+	return o.exporter.exportTraces(ctx, td)
+}
+
+func (o *OpenTelemetry) sendLogs(logs []telegraf.Metric) error {
+	if len(logs) == 0 {
+		return nil
+	}
 
-	if len(o.Headers) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+	ld := plogotlp.NewExportRequestFromLogs(o.convertLogs(logs))
+	if ld.Logs().LogRecordCount() == 0 {
+		return nil
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
 	defer cancel()
-	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
-	return err
+	return o.exporter.exportLogs(ctx, ld)
 }
 
 const (
-	defaultServiceAddress = "localhost:4317"
-	defaultTimeout        = config.Duration(5 * time.Second)
-	defaultCompression    = "gzip"
+	defaultServiceAddress     = "localhost:4317"
+	defaultHTTPServiceAddress = "http://localhost:4318"
+	defaultTimeout            = config.Duration(5 * time.Second)
+	defaultCompression        = "gzip"
 )
 
 func init() {
 	outputs.Add("opentelemetry", func() telegraf.Output {
 		return &OpenTelemetry{
-			ServiceAddress: defaultServiceAddress,
-			Timeout:        defaultTimeout,
-			Compression:    defaultCompression,
+			Timeout:     defaultTimeout,
+			Compression: defaultCompression,
 		}
 	})
 }
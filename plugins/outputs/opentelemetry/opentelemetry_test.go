@@ -1,23 +1,34 @@
 package opentelemetry
 
 import (
+	"compress/gzip"
 	"context"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -43,14 +54,13 @@ func TestOpenTelemetry(t *testing.T) {
 	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
 	require.NoError(t, err)
 	plugin := &OpenTelemetry{
-		ServiceAddress:       m.Address(),
-		Timeout:              config.Duration(time.Second),
-		Headers:              map[string]string{"test": "header1"},
-		Attributes:           map[string]string{"attr-key": "attr-val"},
-		metricsConverter:     metricsConverter,
-		grpcClientConn:       m.GrpcClient(),
-		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
-		Log:                  testutil.Logger{},
+		ServiceAddress:   m.Address(),
+		Timeout:          config.Duration(time.Second),
+		Headers:          map[string]string{"test": "header1"},
+		Attributes:       map[string]string{"attr-key": "attr-val"},
+		metricsConverter: metricsConverter,
+		exporter:         newGRPCExporter(m.GrpcClient(), map[string]string{"test": "header1"}),
+		Log:              testutil.Logger{},
 	}
 
 	input := testutil.MustMetric(
@@ -80,6 +90,293 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func TestInitFail(t *testing.T) {
+	plugin := &OpenTelemetry{Protocol: "foo"}
+	require.ErrorContains(t, plugin.Init(), `invalid protocol "foo"`)
+
+	plugin = &OpenTelemetry{Protocol: "http", Compression: "zstd"}
+	require.ErrorContains(t, plugin.Init(), `invalid compression "zstd" for protocol "http"`)
+}
+
+func TestOpenTelemetryHTTP(t *testing.T) {
+	expect := pmetric.NewMetrics()
+	{
+		rm := expect.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", "potato")
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		ilm.Scope().SetName("My Library Name")
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("cpu_temp")
+		m.SetEmptyGauge()
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("foo", "bar")
+		dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+		dp.SetDoubleValue(87.332)
+	}
+
+	server := newMockHTTPService(t, http.StatusOK)
+	defer server.Close()
+
+	plugin := &OpenTelemetry{
+		ServiceAddress: server.URL(),
+		Protocol:       "http",
+		Headers:        map[string]string{"test": "header1"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := testutil.MustMetric(
+		"cpu_temp",
+		map[string]string{
+			"foo":               "bar",
+			"otel.library.name": "My Library Name",
+			"host.name":         "potato",
+		},
+		map[string]interface{}{
+			"gauge": 87.332,
+		},
+		time.Unix(0, 1622848686000000000))
+	require.NoError(t, plugin.Write([]telegraf.Metric{input}))
+
+	bodies := server.Requests("/v1/metrics")
+	require.Len(t, bodies, 1)
+	req := pmetricotlp.NewExportRequest()
+	require.NoError(t, req.UnmarshalProto(bodies[0]))
+
+	marshaller := pmetric.JSONMarshaler{}
+	expectJSON, err := marshaller.MarshalMetrics(expect)
+	require.NoError(t, err)
+	gotJSON, err := marshaller.MarshalMetrics(req.Metrics())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+}
+
+func TestOpenTelemetryHTTPReject(t *testing.T) {
+	server := newMockHTTPService(t, http.StatusBadRequest)
+	defer server.Close()
+
+	plugin := &OpenTelemetry{
+		ServiceAddress: server.URL(),
+		Protocol:       "http",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := testutil.MustMetric(
+		"cpu_temp",
+		map[string]string{},
+		map[string]interface{}{"gauge": 87.332},
+		time.Unix(0, 1622848686000000000))
+	err := plugin.Write([]telegraf.Metric{input})
+
+	var rejectErr *internal.RejectError
+	require.ErrorAs(t, err, &rejectErr)
+}
+
+func TestOpenTelemetryHTTPPartial(t *testing.T) {
+	server := newMockHTTPService(t, http.StatusOK)
+	server.pathStatus = map[string]int{"/v1/logs": http.StatusServiceUnavailable}
+	defer server.Close()
+
+	plugin := &OpenTelemetry{
+		ServiceAddress:      server.URL(),
+		Protocol:            "http",
+		ExportTracesAndLogs: true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	start := time.Unix(0, 1622848686000000000)
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"logs",
+			map[string]string{"service.name": "checkout"},
+			map[string]interface{}{"body": "cart loaded"},
+			start,
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{},
+			map[string]interface{}{"gauge": 87.332},
+			start,
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{},
+			map[string]interface{}{"gauge": 42.0},
+			start.Add(time.Second),
+		),
+	}
+
+	// Only the failed logs must be retried
+	var partialErr *internal.PartialWriteError
+	require.ErrorAs(t, plugin.Write(input), &partialErr)
+	require.ElementsMatch(t, []int{1, 2}, partialErr.MetricsAccept)
+	require.Empty(t, partialErr.MetricsReject)
+	require.Len(t, server.Requests("/v1/metrics"), 2)
+}
+
+func TestExportTracesAndLogs(t *testing.T) {
+	server := newMockHTTPService(t, http.StatusOK)
+	defer server.Close()
+
+	plugin := &OpenTelemetry{
+		ServiceAddress:      server.URL(),
+		Protocol:            "http",
+		ExportTracesAndLogs: true,
+		Headers:             map[string]string{"test": "header1"},
+		Attributes:          map[string]string{"attr-key": "attr-val"},
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	start := time.Unix(0, 1622848686000000000)
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"spans",
+			map[string]string{
+				"service.name": "checkout",
+				"trace_id":     "0102030405060708090a0b0c0d0e0f10",
+				"span_id":      "0102030405060708",
+			},
+			map[string]interface{}{
+				"span.name":          "GET /cart",
+				"span.kind":          "SPAN_KIND_SERVER",
+				"parent_span_id":     "1112131415161718",
+				"end_time_unix_nano": int64(1622848686000001000),
+				"duration_nano":      int64(1000),
+				"attributes":         `{"http.method":"GET","http.status_code":200}`,
+				"otel.status_code":   "STATUS_CODE_ERROR",
+			},
+			start,
+		),
+		testutil.MustMetric(
+			"span-links",
+			map[string]string{
+				"service.name":    "checkout",
+				"trace_id":        "0102030405060708090a0b0c0d0e0f10",
+				"span_id":         "0102030405060708",
+				"linked_trace_id": "1102030405060708090a0b0c0d0e0f10",
+				"linked_span_id":  "2102030405060708",
+			},
+			map[string]interface{}{
+				"attributes": `{"reason":"retry"}`,
+			},
+			start,
+		),
+		testutil.MustMetric(
+			"span-links",
+			map[string]string{
+				"service.name":    "checkout",
+				"trace_id":        "0102030405060708090a0b0c0d0e0f10",
+				"span_id":         "ffffffffffffffff",
+				"linked_trace_id": "1102030405060708090a0b0c0d0e0f10",
+				"linked_span_id":  "2102030405060708",
+			},
+			map[string]interface{}{},
+			start,
+		),
+		testutil.MustMetric(
+			"logs",
+			map[string]string{
+				"service.name": "checkout",
+				"trace_id":     "0102030405060708090a0b0c0d0e0f10",
+				"span_id":      "0102030405060708",
+			},
+			map[string]interface{}{
+				"severity_number": int64(9),
+				"severity_text":   "INFO",
+				"body":            "cart loaded",
+				"attributes":      `{"items":3}`,
+			},
+			start,
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{},
+			map[string]interface{}{"gauge": 87.332},
+			start,
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	// Traces
+	bodies := server.Requests("/v1/traces")
+	require.Len(t, bodies, 1)
+	traceReq := ptraceotlp.NewExportRequest()
+	require.NoError(t, traceReq.UnmarshalProto(bodies[0]))
+
+	expectedTraces := ptrace.NewTraces()
+	{
+		rs := expectedTraces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", "checkout")
+		rs.Resource().Attributes().PutStr("attr-key", "attr-val")
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+		span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		span.SetParentSpanID([8]byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18})
+		span.SetName("GET /cart")
+		span.SetKind(ptrace.SpanKindServer)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		span.SetEndTimestamp(pcommon.Timestamp(1622848686000001000))
+		span.Attributes().PutStr("http.method", "GET")
+		span.Attributes().PutDouble("http.status_code", 200)
+		span.Status().SetCode(ptrace.StatusCodeError)
+		link := span.Links().AppendEmpty()
+		link.SetTraceID([16]byte{0x11, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+		link.SetSpanID([8]byte{0x21, 2, 3, 4, 5, 6, 7, 8})
+		link.Attributes().PutStr("reason", "retry")
+	}
+	traceMarshaller := ptrace.JSONMarshaler{}
+	expectJSON, err := traceMarshaller.MarshalTraces(expectedTraces)
+	require.NoError(t, err)
+	gotJSON, err := traceMarshaller.MarshalTraces(traceReq.Traces())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+
+	// Logs
+	bodies = server.Requests("/v1/logs")
+	require.Len(t, bodies, 1)
+	logReq := plogotlp.NewExportRequest()
+	require.NoError(t, logReq.UnmarshalProto(bodies[0]))
+
+	expectedLogs := plog.NewLogs()
+	{
+		rl := expectedLogs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", "checkout")
+		rl.Resource().Attributes().PutStr("attr-key", "attr-val")
+		record := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		record.SetTimestamp(pcommon.NewTimestampFromTime(start))
+		record.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+		record.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		record.SetSeverityNumber(plog.SeverityNumberInfo)
+		record.SetSeverityText("INFO")
+		record.Body().SetStr("cart loaded")
+		record.Attributes().PutDouble("items", 3)
+	}
+	logMarshaller := plog.JSONMarshaler{}
+	expectJSON, err = logMarshaller.MarshalLogs(expectedLogs)
+	require.NoError(t, err)
+	gotJSON, err = logMarshaller.MarshalLogs(logReq.Logs())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+
+	// Remaining metrics
+	bodies = server.Requests("/v1/metrics")
+	require.Len(t, bodies, 1)
+	metricReq := pmetricotlp.NewExportRequest()
+	require.NoError(t, metricReq.UnmarshalProto(bodies[0]))
+	require.Equal(t, 1, metricReq.Metrics().MetricCount())
+}
+
 var _ pmetricotlp.GRPCServer = (*mockOtelService)(nil)
 
 type mockOtelService struct {
@@ -138,3 +435,73 @@ func (m *mockOtelService) Export(ctx context.Context, request pmetricotlp.Export
 	require.True(m.t, ok)
 	return pmetricotlp.NewExportResponse(), nil
 }
+
+type mockHTTPService struct {
+	t      *testing.T
+	server *httptest.Server
+	status int
+
+	// Status returned for specific paths instead of the default status
+	pathStatus map[string]int
+
+	requests map[string][][]byte
+	sync.Mutex
+}
+
+func newMockHTTPService(t *testing.T, status int) *mockHTTPService {
+	m := &mockHTTPService{
+		t:        t,
+		status:   status,
+		requests: make(map[string][][]byte),
+	}
+	m.server = httptest.NewServer(m)
+	return m
+}
+
+func (m *mockHTTPService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !assert.Equal(m.t, http.MethodPost, r.Method) ||
+		!assert.Equal(m.t, "application/x-protobuf", r.Header.Get("Content-Type")) ||
+		!assert.Equal(m.t, "gzip", r.Header.Get("Content-Encoding")) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if h := r.Header.Get("test"); h != "" && !assert.Equal(m.t, "header1", h) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	reader, err := gzip.NewReader(r.Body)
+	if !assert.NoError(m.t, err) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	body, err := io.ReadAll(reader)
+	if !assert.NoError(m.t, err) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	m.Lock()
+	m.requests[r.URL.Path] = append(m.requests[r.URL.Path], body)
+	m.Unlock()
+
+	if status, found := m.pathStatus[r.URL.Path]; found {
+		w.WriteHeader(status)
+		return
+	}
+	w.WriteHeader(m.status)
+}
+
+func (m *mockHTTPService) URL() string {
+	return m.server.URL
+}
+
+func (m *mockHTTPService) Requests(path string) [][]byte {
+	m.Lock()
+	defer m.Unlock()
+	return m.requests[path]
+}
+
+func (m *mockHTTPService) Close() {
+	m.server.Close()
+}
@@ -50,7 +50,8 @@ type Postgresql struct {
 	Uint64Type                 string                  `toml:"uint64_type"`
 	RetryMaxBackoff            config.Duration         `toml:"retry_max_backoff"`
 	TagCacheSize               int                     `toml:"tag_cache_size"`
-	LogLevel                   string                  `toml:"log_level"`
+	PgxLogLevel                string                  `toml:"pgx_log_level"`
+	LogLevel                   string                  `toml:"log_level" deprecated:"1.30.0;1.35.0;use 'pgx_log_level' instead"`
 	Logger                     telegraf.Logger         `toml:"-"`
 
 	dbContext       context.Context
@@ -88,7 +89,7 @@ func newPostgresql() *Postgresql {
 		TagTableAddColumnTemplates: []*sqltemplate.Template{{}},
 		RetryMaxBackoff:            config.Duration(time.Second * 15),
 		Logger:                     models.NewLogger("outputs", "postgresql", ""),
-		LogLevel:                   "warn",
+		PgxLogLevel:                "warn",
 	}
 
 	_ = p.CreateTemplates[0].UnmarshalText([]byte(`CREATE TABLE {{ .table }} ({{ .columns }})`))
@@ -144,9 +145,14 @@ func (p *Postgresql) Init() error {
 		p.dbConfig.ConnConfig.RuntimeParams["application_name"] = "telegraf"
 	}
 
+	// Keep the deprecated option working as it shadows the general log_level
+	// option of the plugin
 	if p.LogLevel != "" {
+		p.PgxLogLevel = p.LogLevel
+	}
+	if p.PgxLogLevel != "" {
 		p.dbConfig.ConnConfig.Logger = utils.PGXLogger{Logger: p.Logger}
-		p.dbConfig.ConnConfig.LogLevel, err = pgx.LogLevelFromString(p.LogLevel)
+		p.dbConfig.ConnConfig.LogLevel, err = pgx.LogLevelFromString(p.PgxLogLevel)
 		if err != nil {
 			return errors.New("invalid log level")
 		}
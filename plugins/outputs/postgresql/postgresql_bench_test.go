@@ -25,7 +25,7 @@ func benchmarkPostgresql(b *testing.B, gen <-chan []telegraf.Metric, concurrency
 	p := newPostgresqlTest(b)
 	p.Connection += fmt.Sprintf(" pool_max_conns=%d", concurrency)
 	p.TagsAsForeignKeys = foreignTags
-	p.LogLevel = ""
+	p.PgxLogLevel = ""
 	_ = p.Init()
 	if err := p.Connect(); err != nil {
 		b.Fatalf("Error: %s", err)
@@ -241,7 +241,7 @@ func newPostgresqlTest(tb testing.TB) *PostgresqlTest {
 	)
 	logger := NewLogAccumulator(tb)
 	p.Logger = logger
-	p.LogLevel = "debug"
+	p.PgxLogLevel = "debug"
 	require.NoError(tb, p.Init())
 
 	pt := &PostgresqlTest{Postgresql: p}
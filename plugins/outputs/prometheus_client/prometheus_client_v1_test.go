@@ -11,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -522,3 +524,96 @@ func TestLandingPage(t *testing.T) {
 
 	require.Equal(t, expected, strings.TrimSpace(string(actual)))
 }
+
+func TestNativeHistogram(t *testing.T) {
+	logger := testutil.Logger{Name: "outputs.prometheus_client"}
+	tests := []struct {
+		name          string
+		metricVersion int
+		prefix        string
+	}{
+		{
+			name:          "metric version 1",
+			metricVersion: 1,
+		},
+		{
+			name:          "metric version 2",
+			metricVersion: 2,
+			prefix:        "latency_",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]interface{}{
+				"schema":                 int64(3),
+				"zero_threshold":         0.001,
+				"zero_count":             1.0,
+				"count":                  13.0,
+				"sum":                    42.5,
+				"positive_span_0_offset": int64(-2),
+				"positive_span_0_length": int64(2),
+				"positive_span_1_offset": int64(3),
+				"positive_span_1_length": int64(1),
+				"positive_bucket_0":      2.0,
+				"positive_bucket_1":      5.0,
+				"positive_bucket_2":      4.0,
+			}
+			name := "latency"
+			if tt.prefix != "" {
+				name = "prometheus"
+				prefixed := make(map[string]interface{}, len(fields))
+				for k, v := range fields {
+					prefixed[tt.prefix+k] = v
+				}
+				fields = prefixed
+			}
+			m := testutil.MustMetric(name, map[string]string{"method": "GET"}, fields, time.Unix(0, 0), telegraf.Histogram)
+
+			output := &PrometheusClient{
+				Listen:            "127.0.0.1:0",
+				Path:              defaultPath,
+				MetricVersion:     tt.metricVersion,
+				Log:               logger,
+				CollectorsExclude: []string{"gocollector", "process"},
+			}
+			require.NoError(t, output.Init())
+			require.NoError(t, output.Connect())
+			defer func() {
+				require.NoError(t, output.Close())
+			}()
+			require.NoError(t, output.Write([]telegraf.Metric{m}))
+
+			req, err := http.NewRequest(http.MethodGet, output.URL(), nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var found *dto.Histogram
+			decoder := expfmt.NewDecoder(resp.Body, expfmt.ResponseFormat(resp.Header))
+			for {
+				var mf dto.MetricFamily
+				if err := decoder.Decode(&mf); err != nil {
+					require.ErrorIs(t, err, io.EOF)
+					break
+				}
+				if mf.GetName() == "latency" {
+					require.Len(t, mf.Metric, 1)
+					found = mf.Metric[0].GetHistogram()
+				}
+			}
+			require.NotNil(t, found)
+			require.Equal(t, uint64(13), found.GetSampleCount())
+			require.InDelta(t, 42.5, found.GetSampleSum(), 1e-9)
+			require.Equal(t, int32(3), found.GetSchema())
+			require.Equal(t, uint64(1), found.GetZeroCount())
+			require.Len(t, found.PositiveSpan, 2)
+			require.Equal(t, int32(-2), found.PositiveSpan[0].GetOffset())
+			require.Equal(t, uint32(1), found.PositiveSpan[1].GetLength())
+			require.Equal(t, []int64{2, 3, -1}, found.PositiveDelta)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/telegraf"
 	serializer "github.com/influxdata/telegraf/plugins/serializers/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var (
@@ -33,6 +34,8 @@ type Sample struct {
 	// Histograms and Summaries need a count and a sum
 	Count uint64
 	Sum   float64
+	// Native histogram buckets, only set for native histograms
+	Native *serializer.NativeHistogram
 	// Metric timestamp
 	Timestamp time.Time
 	// Expiration is the deadline that this Sample is valid until.
@@ -131,6 +134,9 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 				metric, err = prometheus.NewConstSummary(desc, sample.Count, sample.Sum, sample.SummaryValue, labels...)
 			case telegraf.Histogram:
 				metric, err = prometheus.NewConstHistogram(desc, sample.Count, sample.Sum, sample.HistogramValue, labels...)
+				if err == nil && sample.Native != nil {
+					metric = &nativeHistogram{Metric: metric, native: sample.Native}
+				}
 			default:
 				metric, err = prometheus.NewConstMetric(desc, getPromValueType(family.TelegrafValueType), sample.Value, labels...)
 			}
@@ -149,6 +155,20 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// nativeHistogram adds the native buckets to a classic histogram
+type nativeHistogram struct {
+	prometheus.Metric
+	native *serializer.NativeHistogram
+}
+
+func (m *nativeHistogram) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+	m.native.Fill(out.Histogram)
+	return nil
+}
+
 func sanitize(value string) string {
 	return invalidNameCharRE.ReplaceAllString(value, "_")
 }
@@ -341,11 +361,16 @@ func (c *Collector) addMetrics(metrics []telegraf.Metric) {
 					}
 				}
 			}
+			native, err := serializer.ParseNativeHistogram(point, "")
+			if err != nil {
+				c.Log.Errorf("Error parsing native histogram %q: %v", point.Name(), err)
+			}
 			sample := &Sample{
 				Labels:         labels,
 				HistogramValue: histogramvalue,
 				Count:          count,
 				Sum:            sum,
+				Native:         native,
 				Timestamp:      point.Time(),
 				Expiration:     now.Add(c.ExpirationInterval),
 			}
@@ -1,6 +1,8 @@
 package prometheus
 
 import (
+	"strconv"
+
 	"github.com/influxdata/telegraf"
 	dto "github.com/prometheus/client_model/go"
 )
@@ -35,3 +37,59 @@ func getTagsFromLabels(m *dto.Metric, defaultTags map[string]string) map[string]
 
 	return result
 }
+
+// isNativeHistogram returns true if the histogram contains a native (sparse)
+// histogram using the same criteria as Prometheus
+func isNativeHistogram(h *dto.Histogram) bool {
+	return h.GetZeroThreshold() > 0 ||
+		h.GetZeroCount() > 0 ||
+		h.GetZeroCountFloat() > 0 ||
+		len(h.GetNegativeSpan()) > 0 ||
+		len(h.GetPositiveSpan()) > 0
+}
+
+// histogramCount returns the number of observations of both integer and
+// float histograms
+func histogramCount(h *dto.Histogram) float64 {
+	if h.GetSampleCountFloat() > 0 {
+		return h.GetSampleCountFloat()
+	}
+	return float64(h.GetSampleCount())
+}
+
+// addNativeHistogramFields adds the schema, the zero bucket and the spans
+// and absolute counts of the positive and negative buckets of the native
+// histogram as fields with the given prefix
+func addNativeHistogramFields(fields map[string]interface{}, prefix string, h *dto.Histogram) {
+	fields[prefix+"schema"] = int64(h.GetSchema())
+	fields[prefix+"zero_threshold"] = h.GetZeroThreshold()
+	if h.GetZeroCountFloat() > 0 {
+		fields[prefix+"zero_count"] = h.GetZeroCountFloat()
+	} else {
+		fields[prefix+"zero_count"] = float64(h.GetZeroCount())
+	}
+	addNativeBucketFields(fields, prefix+"positive_", h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+	addNativeBucketFields(fields, prefix+"negative_", h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+}
+
+func addNativeBucketFields(fields map[string]interface{}, prefix string, spans []*dto.BucketSpan, deltas []int64, counts []float64) {
+	for i, span := range spans {
+		idx := strconv.Itoa(i)
+		fields[prefix+"span_"+idx+"_offset"] = int64(span.GetOffset())
+		fields[prefix+"span_"+idx+"_length"] = int64(span.GetLength())
+	}
+
+	// Integer histograms encode the bucket counts as deltas to the previous
+	// bucket while float histograms contain the absolute counts
+	if len(counts) > 0 {
+		for i, count := range counts {
+			fields[prefix+"bucket_"+strconv.Itoa(i)] = count
+		}
+		return
+	}
+	var count int64
+	for i, delta := range deltas {
+		count += delta
+		fields[prefix+"bucket_"+strconv.Itoa(i)] = float64(count)
+	}
+}
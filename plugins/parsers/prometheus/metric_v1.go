@@ -53,12 +53,15 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 			// Collect the fields
 			fields := make(map[string]interface{}, len(histogram.Bucket)+2)
-			fields["count"] = float64(pm.GetHistogram().GetSampleCount())
-			fields["sum"] = pm.GetHistogram().GetSampleSum()
+			fields["count"] = histogramCount(histogram)
+			fields["sum"] = histogram.GetSampleSum()
 			for _, b := range histogram.Bucket {
 				fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
 				fields[fname] = float64(b.GetCumulativeCount())
 			}
+			if isNativeHistogram(histogram) {
+				addNativeHistogramFields(fields, "", histogram)
+			}
 			metrics = append(metrics, metric.New(metricName, tags, fields, t, telegraf.Histogram))
 		default:
 			var fname string
@@ -56,11 +56,22 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 			histogram := pm.GetHistogram()
 
 			// Add an overall metric containing the number of samples and and its sum
+			// as well as the buckets of a native histogram
 			histFields := make(map[string]interface{})
-			histFields[metricName+"_count"] = float64(histogram.GetSampleCount())
+			histFields[metricName+"_count"] = histogramCount(histogram)
 			histFields[metricName+"_sum"] = histogram.GetSampleSum()
+			native := isNativeHistogram(histogram)
+			if native {
+				addNativeHistogramFields(histFields, metricName+"_", histogram)
+			}
 			metrics = append(metrics, metric.New("prometheus", tags, histFields, t, telegraf.Histogram))
 
+			// Native histograms without classic buckets don't need the
+			// infinity bucket
+			if native && len(histogram.Bucket) == 0 {
+				continue
+			}
+
 			// Add one metric per histogram bucket
 			var infSeen bool
 			for _, b := range histogram.Bucket {
@@ -81,7 +92,7 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 				infTags := tags
 				infTags["le"] = "+Inf"
 				infFields := map[string]interface{}{
-					metricName + "_bucket": float64(histogram.GetSampleCount()),
+					metricName + "_bucket": histogramCount(histogram),
 				}
 				m := metric.New("prometheus", infTags, infFields, t, telegraf.Histogram)
 				metrics = append(metrics, m)
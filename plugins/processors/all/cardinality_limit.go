@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.cardinality_limit
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/cardinality_limit" // register plugin
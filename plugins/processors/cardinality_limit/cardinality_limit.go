@@ -0,0 +1,194 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cardinality_limit
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CardinalityLimit struct {
+	Limit       int             `toml:"limit"`
+	Window      config.Duration `toml:"window"`
+	Action      string          `toml:"action"`
+	KeepTags    []string        `toml:"keep_tags"`
+	OverflowTag string          `toml:"overflow_tag"`
+	StripTags   []string        `toml:"strip_tags"`
+	Alias       string          `toml:"alias"`
+	Log         telegraf.Logger `toml:"-"`
+
+	keepTags  filter.Filter
+	stripTags filter.Filter
+
+	// series contains the time each series was last seen per measurement
+	series      map[string]map[uint64]time.Time
+	lastCleanup time.Time
+
+	seriesTracked  selfstat.Stat
+	seriesLimited  selfstat.Stat
+	metricsDropped selfstat.Stat
+}
+
+func (*CardinalityLimit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *CardinalityLimit) Init() error {
+	if p.Limit <= 0 {
+		return errors.New("'limit' must be positive")
+	}
+	if p.Window < 0 {
+		return errors.New("'window' cannot be negative")
+	}
+	if p.Window == 0 {
+		p.Window = config.Duration(time.Hour)
+	}
+
+	switch p.Action {
+	case "":
+		p.Action = "drop"
+	case "drop", "collapse":
+	case "strip_tags":
+		if len(p.StripTags) == 0 {
+			return errors.New("'strip_tags' required for action \"strip_tags\"")
+		}
+	default:
+		return fmt.Errorf("invalid action %q", p.Action)
+	}
+
+	if p.OverflowTag == "" {
+		p.OverflowTag = "cardinality_overflow"
+	}
+
+	var err error
+	p.keepTags, err = filter.Compile(p.KeepTags)
+	if err != nil {
+		return fmt.Errorf("creating keep-tags filter failed: %w", err)
+	}
+	p.stripTags, err = filter.Compile(p.StripTags)
+	if err != nil {
+		return fmt.Errorf("creating strip-tags filter failed: %w", err)
+	}
+
+	p.series = make(map[string]map[uint64]time.Time)
+	p.lastCleanup = time.Now()
+
+	// The statistics are only changed incrementally to allow multiple
+	// instances of the plugin sharing the same statistics
+	tags := make(map[string]string)
+	if p.Alias != "" {
+		tags["alias"] = p.Alias
+	}
+	p.seriesTracked = selfstat.Register("cardinality_limit", "series_tracked", tags)
+	p.seriesLimited = selfstat.Register("cardinality_limit", "series_limited", tags)
+	p.metricsDropped = selfstat.Register("cardinality_limit", "metrics_dropped", tags)
+
+	return nil
+}
+
+func (p *CardinalityLimit) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	p.cleanup(now)
+
+	out := in[:0]
+	for _, m := range in {
+		if p.accept(m, now) {
+			out = append(out, m)
+			continue
+		}
+		m.Drop()
+		p.metricsDropped.Incr(1)
+	}
+	return out
+}
+
+// accept tracks the series of the metric and applies the action if the
+// series exceeds the limit. It returns false if the metric must be dropped.
+func (p *CardinalityLimit) accept(m telegraf.Metric, now time.Time) bool {
+	if p.track(m, now) {
+		return true
+	}
+	p.seriesLimited.Incr(1)
+
+	switch p.Action {
+	case "collapse":
+		for _, tag := range m.TagList() {
+			if !p.keepTags.Match(tag.Key) {
+				m.RemoveTag(tag.Key)
+			}
+		}
+		m.AddTag(p.OverflowTag, "true")
+		return true
+	case "strip_tags":
+		for _, tag := range m.TagList() {
+			if p.stripTags.Match(tag.Key) {
+				m.RemoveTag(tag.Key)
+			}
+		}
+		return p.track(m, now)
+	}
+	return false
+}
+
+// track records the series of the metric if it is known or the limit of the
+// measurement is not yet reached and returns false otherwise.
+func (p *CardinalityLimit) track(m telegraf.Metric, now time.Time) bool {
+	series, found := p.series[m.Name()]
+	if !found {
+		series = make(map[uint64]time.Time)
+		p.series[m.Name()] = series
+	}
+
+	id := m.HashID()
+	if _, found := series[id]; found {
+		series[id] = now
+		return true
+	}
+	if len(series) >= p.Limit {
+		return false
+	}
+
+	series[id] = now
+	p.seriesTracked.Incr(1)
+	return true
+}
+
+// cleanup removes the series not seen within the window. To reduce the
+// overhead, the series are only checked a few times per window.
+func (p *CardinalityLimit) cleanup(now time.Time) {
+	window := time.Duration(p.Window)
+	if now.Sub(p.lastCleanup) < window/10 {
+		return
+	}
+	p.lastCleanup = now
+
+	for name, series := range p.series {
+		var expired int64
+		for id, seen := range series {
+			if now.Sub(seen) >= window {
+				delete(series, id)
+				expired++
+			}
+		}
+		if len(series) == 0 {
+			delete(p.series, name)
+		}
+		p.seriesTracked.Incr(-expired)
+	}
+}
+
+func init() {
+	processors.Add("cardinality_limit", func() telegraf.Processor {
+		return &CardinalityLimit{}
+	})
+}
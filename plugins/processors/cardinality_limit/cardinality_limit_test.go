@@ -0,0 +1,216 @@
+package cardinality_limit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CardinalityLimit
+		expected string
+	}{
+		{
+			name:     "no limit",
+			plugin:   &CardinalityLimit{},
+			expected: "'limit' must be positive",
+		},
+		{
+			name:     "negative window",
+			plugin:   &CardinalityLimit{Limit: 1, Window: config.Duration(-time.Second)},
+			expected: "'window' cannot be negative",
+		},
+		{
+			name:     "invalid action",
+			plugin:   &CardinalityLimit{Limit: 1, Action: "foo"},
+			expected: `invalid action "foo"`,
+		},
+		{
+			name:     "strip tags without tags",
+			plugin:   &CardinalityLimit{Limit: 1, Action: "strip_tags"},
+			expected: `'strip_tags' required for action "strip_tags"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+		metric.New("http", map[string]string{"host": "a", "path": "/login"}, map[string]interface{}{"value": 2}, now),
+		metric.New("http", map[string]string{"host": "a", "path": "/logout"}, map[string]interface{}{"value": 3}, now),
+		metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 4}, now),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"value": 5}, now),
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *CardinalityLimit
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "drop",
+			plugin: &CardinalityLimit{Limit: 2},
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/login"}, map[string]interface{}{"value": 2}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 4}, now),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"value": 5}, now),
+			},
+		},
+		{
+			name:   "collapse",
+			plugin: &CardinalityLimit{Limit: 2, Action: "collapse", KeepTags: []string{"host"}},
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/login"}, map[string]interface{}{"value": 2}, now),
+				metric.New("http", map[string]string{"host": "a", "cardinality_overflow": "true"}, map[string]interface{}{"value": 3}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 4}, now),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"value": 5}, now),
+			},
+		},
+		{
+			name:   "strip tags within limit",
+			plugin: &CardinalityLimit{Limit: 3, Action: "strip_tags", StripTags: []string{"path"}},
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/login"}, map[string]interface{}{"value": 2}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/logout"}, map[string]interface{}{"value": 3}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 4}, now),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"value": 5}, now),
+			},
+		},
+		{
+			name:   "strip tags exceeding limit",
+			plugin: &CardinalityLimit{Limit: 2, Action: "strip_tags", StripTags: []string{"pa*"}},
+			expected: []telegraf.Metric{
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/login"}, map[string]interface{}{"value": 2}, now),
+				metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 4}, now),
+				metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"value": 5}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.plugin.Init())
+
+			in := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				in = append(in, m.Copy())
+			}
+			actual := tt.plugin.Apply(in...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestApplyStripTagsFreesSlot(t *testing.T) {
+	now := time.Now()
+	plugin := &CardinalityLimit{Limit: 2, Action: "strip_tags", StripTags: []string{"path"}}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+		metric.New("http", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, now),
+		metric.New("http", map[string]string{"host": "a", "path": "/logout"}, map[string]interface{}{"value": 3}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("http", map[string]string{"host": "a", "path": "/index"}, map[string]interface{}{"value": 1}, now),
+		metric.New("http", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, now),
+		metric.New("http", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Now()
+	plugin := &CardinalityLimit{Limit: 1, Window: config.Duration(time.Minute)}
+	require.NoError(t, plugin.Init())
+
+	first := metric.New("http", map[string]string{"path": "/index"}, map[string]interface{}{"value": 1}, now)
+	second := metric.New("http", map[string]string{"path": "/login"}, map[string]interface{}{"value": 2}, now)
+
+	require.Len(t, plugin.Apply(first.Copy()), 1)
+	require.Empty(t, plugin.Apply(second.Copy()))
+
+	// Pretend the first series was last seen before the window
+	plugin.lastCleanup = now.Add(-time.Hour)
+	for id := range plugin.series["http"] {
+		plugin.series["http"][id] = now.Add(-2 * time.Minute)
+	}
+
+	actual := plugin.Apply(second.Copy())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{second}, actual)
+	require.Empty(t, plugin.Apply(first.Copy()))
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"path": "/index"}, map[string]interface{}{"value": 1}, now),
+		metric.New("http", map[string]string{"path": "/login"}, map[string]interface{}{"value": 2}, now),
+	}
+	tracked := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		tm, _ := metric.WithTracking(m, notify)
+		tracked = append(tracked, tm)
+	}
+
+	plugin := &CardinalityLimit{Limit: 1}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(tracked...)
+	testutil.RequireMetricsEqual(t, input[:1], actual)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
+
+func TestStatisticsAlias(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"path": "/index"}, map[string]interface{}{"value": 1}, now),
+		metric.New("http", map[string]string{"path": "/login"}, map[string]interface{}{"value": 2}, now),
+	}
+
+	plugin := &CardinalityLimit{Limit: 1, Alias: "statistics_alias"}
+	require.NoError(t, plugin.Init())
+	plugin.Apply(input...)
+
+	values := selfstat.Values("cardinality_limit", map[string]string{"alias": "statistics_alias"})
+	require.Equal(t, int64(1), values["series_tracked"])
+	require.Equal(t, int64(1), values["series_limited"])
+	require.Equal(t, int64(1), values["metrics_dropped"])
+}
@@ -0,0 +1,248 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cel
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CEL struct {
+	Name       string            `toml:"name"`
+	Fields     map[string]string `toml:"fields"`
+	Tags       map[string]string `toml:"tags"`
+	DropFields []string          `toml:"drop_fields"`
+	DropTags   []string          `toml:"drop_tags"`
+	Log        telegraf.Logger   `toml:"-"`
+
+	name       cel.Program
+	fields     []program
+	tags       []program
+	dropFields filter.Filter
+	dropTags   filter.Filter
+}
+
+type program struct {
+	key string
+	cel.Program
+}
+
+func (*CEL) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *CEL) Init() error {
+	if p.Name == "" && len(p.Fields) == 0 && len(p.Tags) == 0 && len(p.DropFields) == 0 && len(p.DropTags) == 0 {
+		return errors.New("no transformation specified")
+	}
+
+	env, err := models.NewMetricEnvironment()
+	if err != nil {
+		return fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	if p.Name != "" {
+		p.name, err = compile(env, p.Name)
+		if err != nil {
+			return fmt.Errorf("compiling name expression failed: %w", err)
+		}
+	}
+	p.fields, err = compileAll(env, p.Fields)
+	if err != nil {
+		return fmt.Errorf("compiling field expression failed: %w", err)
+	}
+	p.tags, err = compileAll(env, p.Tags)
+	if err != nil {
+		return fmt.Errorf("compiling tag expression failed: %w", err)
+	}
+
+	p.dropFields, err = filter.Compile(p.DropFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	p.dropTags, err = filter.Compile(p.DropTags)
+	if err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *CEL) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, metric := range in {
+		if err := p.apply(metric); err != nil {
+			p.Log.Errorf("Leaving metric unchanged: %v", err)
+		}
+	}
+	return in
+}
+
+// apply evaluates all expressions and only changes the metric if all of them
+// succeeded.
+func (p *CEL) apply(metric telegraf.Metric) error {
+	// Evaluate all expressions on the original metric
+	activation := models.MetricActivation(metric)
+
+	var errs []error
+	var name string
+	if p.name != nil {
+		result, _, err := p.name.Eval(activation)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating name expression failed: %w", err))
+		} else if v, ok := result.Value().(string); ok {
+			name = v
+		} else {
+			errs = append(errs, fmt.Errorf("invalid type %q for name", result.Type().TypeName()))
+		}
+	}
+
+	fields := make(map[string]interface{}, len(p.fields))
+	for _, prog := range p.fields {
+		result, _, err := prog.Eval(activation)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating expression for field %q failed: %w", prog.key, err))
+			continue
+		}
+		value, err := fieldValue(result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid result for field %q: %w", prog.key, err))
+			continue
+		}
+		fields[prog.key] = value
+	}
+
+	tags := make(map[string]interface{}, len(p.tags))
+	for _, prog := range p.tags {
+		result, _, err := prog.Eval(activation)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating expression for tag %q failed: %w", prog.key, err))
+			continue
+		}
+		value, err := tagValue(result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid result for tag %q: %w", prog.key, err))
+			continue
+		}
+		tags[prog.key] = value
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Apply the changes, removing fields and tags before setting the new ones
+	if name != "" {
+		metric.SetName(name)
+	}
+	if p.dropFields != nil {
+		for key := range activation["fields"].(map[string]interface{}) {
+			if p.dropFields.Match(key) {
+				metric.RemoveField(key)
+			}
+		}
+	}
+	if p.dropTags != nil {
+		for key := range activation["tags"].(map[string]string) {
+			if p.dropTags.Match(key) {
+				metric.RemoveTag(key)
+			}
+		}
+	}
+	for _, prog := range p.fields {
+		if value := fields[prog.key]; value == nil {
+			metric.RemoveField(prog.key)
+		} else {
+			metric.AddField(prog.key, value)
+		}
+	}
+	for _, prog := range p.tags {
+		value := tags[prog.key]
+		switch {
+		case value == nil:
+			metric.RemoveTag(prog.key)
+		default:
+			metric.AddTag(prog.key, value.(string))
+		}
+	}
+
+	return nil
+}
+
+func compile(env *cel.Env, expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+}
+
+// compileAll compiles the given expressions sorted by key to apply them in
+// a stable order.
+func compileAll(env *cel.Env, expressions map[string]string) ([]program, error) {
+	programs := make([]program, 0, len(expressions))
+	for key, expression := range expressions {
+		prog, err := compile(env, expression)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", key, err)
+		}
+		programs = append(programs, program{key: key, Program: prog})
+	}
+	sort.Slice(programs, func(i, j int) bool { return programs[i].key < programs[j].key })
+	return programs, nil
+}
+
+// fieldValue converts the result of an expression to a field value. A nil
+// value denotes the removal of the field.
+func fieldValue(result ref.Val) (interface{}, error) {
+	if result.Type() == types.NullType {
+		return nil, nil
+	}
+	switch v := result.Value().(type) {
+	case int64, uint64, float64, bool, string:
+		return v, nil
+	case time.Time:
+		return v.UnixNano(), nil
+	case time.Duration:
+		return v.Nanoseconds(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", result.Type().TypeName())
+}
+
+// tagValue converts the result of an expression to a tag value. A nil value
+// denotes the removal of the tag.
+func tagValue(result ref.Val) (interface{}, error) {
+	if result.Type() == types.NullType {
+		return nil, nil
+	}
+	switch v := result.Value().(type) {
+	case string:
+		return v, nil
+	case int64, uint64, float64, bool:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", result.Type().TypeName())
+}
+
+func init() {
+	processors.Add("cel", func() telegraf.Processor {
+		return &CEL{}
+	})
+}
@@ -0,0 +1,205 @@
+package cel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CEL
+		expected string
+	}{
+		{
+			name:     "empty",
+			plugin:   &CEL{},
+			expected: "no transformation specified",
+		},
+		{
+			name:     "invalid name expression",
+			plugin:   &CEL{Name: "name +"},
+			expected: "compiling name expression failed",
+		},
+		{
+			name:     "invalid field expression",
+			plugin:   &CEL{Fields: map[string]string{"value": "unknown.value"}},
+			expected: `compiling field expression failed: "value"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		plugin   *CEL
+		expected []telegraf.Metric
+	}{
+		{
+			name: "rename",
+			plugin: &CEL{
+				Name: "name + '_' + tags.host",
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem_Server01",
+					map[string]string{"host": "Server01"},
+					map[string]interface{}{"used": int64(25), "total": int64(100), "state": "ok"},
+					now,
+				),
+			},
+		},
+		{
+			name: "compute fields",
+			plugin: &CEL{
+				Fields: map[string]string{
+					"used_percent": "double(fields.used) / double(fields.total) * 100.0",
+					"free":         "fields.total - fields.used",
+					"healthy":      "fields.state == 'ok'",
+					"state":        "fields.state.upperAscii()",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{"host": "Server01"},
+					map[string]interface{}{
+						"used":         int64(25),
+						"total":        int64(100),
+						"state":        "OK",
+						"used_percent": float64(25),
+						"free":         int64(75),
+						"healthy":      true,
+					},
+					now,
+				),
+			},
+		},
+		{
+			name: "compute tags",
+			plugin: &CEL{
+				Tags: map[string]string{
+					"host":  "tags.host.lowerAscii()",
+					"total": "fields.total",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{"host": "server01", "total": "100"},
+					map[string]interface{}{"used": int64(25), "total": int64(100), "state": "ok"},
+					now,
+				),
+			},
+		},
+		{
+			name: "drop",
+			plugin: &CEL{
+				Fields: map[string]string{
+					"used_percent": "double(fields.used) / double(fields.total) * 100.0",
+					"state":        "fields.state == 'ok' ? null : fields.state",
+				},
+				Tags: map[string]string{
+					"host": "null",
+				},
+				DropFields: []string{"used", "tot*"},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{},
+					map[string]interface{}{"used_percent": float64(25)},
+					now,
+				),
+			},
+		},
+		{
+			name: "failing expression",
+			plugin: &CEL{
+				Fields: map[string]string{
+					"free":  "fields.total - fields.unknown",
+					"valid": "true",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{"host": "Server01"},
+					map[string]interface{}{"used": int64(25), "total": int64(100), "state": "ok"},
+					now,
+				),
+			},
+		},
+		{
+			name: "failing expression with drop",
+			plugin: &CEL{
+				Name:       "name + '_computed'",
+				Fields:     map[string]string{"free": "fields.total - fields.unknown"},
+				Tags:       map[string]string{"computed": "'true'"},
+				DropFields: []string{"used", "total"},
+				DropTags:   []string{"host"},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{"host": "Server01"},
+					map[string]interface{}{"used": int64(25), "total": int64(100), "state": "ok"},
+					now,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := metric.New(
+				"mem",
+				map[string]string{"host": "Server01"},
+				map[string]interface{}{"used": int64(25), "total": int64(100), "state": "ok"},
+				now,
+			)
+
+			plugin := tt.plugin
+			plugin.Log = &testutil.Logger{}
+			require.NoError(t, plugin.Init())
+			actual := plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var delivered bool
+	notify := func(telegraf.DeliveryInfo) {
+		delivered = true
+	}
+	input, _ := metric.WithTracking(
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": int64(25)}, time.Unix(0, 0)),
+		notify,
+	)
+
+	plugin := &CEL{
+		Fields: map[string]string{"used": "fields.used * 2"},
+		Log:    &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"used": int64(50)}, actual[0].Fields())
+	actual[0].Accept()
+	require.Eventually(t, func() bool { return delivered }, time.Second, 10*time.Millisecond)
+}
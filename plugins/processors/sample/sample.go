@@ -0,0 +1,200 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sample
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Sample struct {
+	Mode     string          `toml:"mode"`
+	Rate     float64         `toml:"rate"`
+	Size     int             `toml:"size"`
+	Interval config.Duration `toml:"interval"`
+	Log      telegraf.Logger `toml:"-"`
+
+	// threshold is the upper limit of the series hashes kept in "series" mode
+	threshold uint64
+
+	// reservoir contains the sampled metrics of the current interval along
+	// with the number of metrics seen within the interval
+	reservoir []telegraf.Metric
+	seen      int
+	sync.Mutex
+
+	acc    telegraf.Accumulator
+	cancel chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (*Sample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sample) Init() error {
+	switch s.Mode {
+	case "":
+		s.Mode = "random"
+	case "random", "series", "reservoir":
+	default:
+		return fmt.Errorf("invalid mode %q", s.Mode)
+	}
+
+	if s.Rate == 0 {
+		s.Rate = 0.1
+	}
+	if s.Rate < 0 || s.Rate > 1 {
+		return errors.New("'rate' must be greater than zero and at most one")
+	}
+	if s.Rate < 1 {
+		s.threshold = uint64(s.Rate * math.MaxUint64)
+	} else {
+		s.threshold = math.MaxUint64
+	}
+
+	if s.Size < 0 {
+		return errors.New("'size' cannot be negative")
+	}
+	if s.Size == 0 {
+		s.Size = 1000
+	}
+	if s.Interval < 0 {
+		return errors.New("'interval' cannot be negative")
+	}
+	if s.Interval == 0 {
+		s.Interval = config.Duration(10 * time.Second)
+	}
+
+	return nil
+}
+
+func (s *Sample) Start(acc telegraf.Accumulator) error {
+	s.acc = acc
+	if s.Mode != "reservoir" {
+		return nil
+	}
+
+	s.reservoir = make([]telegraf.Metric, 0, s.Size)
+	s.cancel = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(s.Interval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.cancel:
+				return
+			case <-ticker.C:
+				s.flush()
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *Sample) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	switch s.Mode {
+	case "random":
+		if rand.Float64() >= s.Rate {
+			m.Drop()
+			return nil
+		}
+	case "series":
+		if mix(m.HashID()) > s.threshold {
+			m.Drop()
+			return nil
+		}
+	case "reservoir":
+		s.sample(m)
+		return nil
+	}
+
+	m.AddField("sample_rate", s.Rate)
+	acc.AddMetric(m)
+	return nil
+}
+
+func (s *Sample) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	close(s.cancel)
+	s.wg.Wait()
+	s.flush()
+}
+
+// sample replaces a random metric of the reservoir by the given one such that
+// every metric of the interval has the same probability to be kept.
+func (s *Sample) sample(m telegraf.Metric) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.seen++
+	if len(s.reservoir) < s.Size {
+		s.reservoir = append(s.reservoir, m)
+		return
+	}
+
+	if i := rand.Intn(s.seen); i < s.Size {
+		s.reservoir[i].Drop()
+		s.reservoir[i] = m
+		return
+	}
+	m.Drop()
+}
+
+// flush emits the metrics kept within the current interval in the order of
+// their timestamps and starts a new interval.
+func (s *Sample) flush() {
+	s.Lock()
+	metrics, seen := s.reservoir, s.seen
+	s.reservoir = make([]telegraf.Metric, 0, s.Size)
+	s.seen = 0
+	s.Unlock()
+
+	if len(metrics) == 0 {
+		return
+	}
+
+	rate := float64(len(metrics)) / float64(seen)
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Time().Before(metrics[j].Time())
+	})
+	for _, m := range metrics {
+		m.AddField("sample_rate", rate)
+		s.acc.AddMetric(m)
+	}
+}
+
+// mix spreads the series hash uniformly across the value range using the
+// finalizer of the 64-bit MurmurHash3 to select an unbiased fraction of the
+// series.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func init() {
+	processors.AddStreaming("sample", func() telegraf.StreamingProcessor {
+		return &Sample{}
+	})
+}
@@ -0,0 +1,238 @@
+package sample
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Sample
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Sample{Mode: "foo"},
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "negative rate",
+			plugin:   &Sample{Rate: -0.1},
+			expected: "'rate' must be greater than zero and at most one",
+		},
+		{
+			name:     "rate above one",
+			plugin:   &Sample{Rate: 1.5},
+			expected: "'rate' must be greater than zero and at most one",
+		},
+		{
+			name:     "negative size",
+			plugin:   &Sample{Mode: "reservoir", Size: -1},
+			expected: "'size' cannot be negative",
+		},
+		{
+			name:     "negative interval",
+			plugin:   &Sample{Mode: "reservoir", Interval: config.Duration(-time.Second)},
+			expected: "'interval' cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	plugin := &Sample{Rate: 0.5}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for i := 0; i < 10000; i++ {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Now())
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	// The number of kept metrics follows a binomial distribution with a
+	// standard deviation of 50, so the bounds are very unlikely to fail
+	actual := acc.GetTelegrafMetrics()
+	require.InDelta(t, 5000, len(actual), 500)
+	for _, m := range actual {
+		rate, found := m.GetField("sample_rate")
+		require.True(t, found)
+		require.InDelta(t, 0.5, rate, 0)
+	}
+}
+
+func TestRandomKeepAll(t *testing.T) {
+	plugin := &Sample{Rate: 1}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"a": "x"}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{"a": "y"}, map[string]interface{}{"value": 2}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"a": "x"}, map[string]interface{}{"value": 1, "sample_rate": 1.0}, now),
+		metric.New("test", map[string]string{"a": "y"}, map[string]interface{}{"value": 2, "sample_rate": 1.0}, now),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestSeries(t *testing.T) {
+	plugin := &Sample{Mode: "series", Rate: 0.25}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		for series := 0; series < 1000; series++ {
+			m := metric.New(
+				"test",
+				map[string]string{"series": strconv.Itoa(series)},
+				map[string]interface{}{"value": i},
+				now.Add(time.Duration(i)*time.Second),
+			)
+			require.NoError(t, plugin.Add(m, &acc))
+		}
+	}
+	plugin.Stop()
+
+	// Either all or no metrics of a series are kept
+	counts := make(map[string]int)
+	for _, m := range acc.GetTelegrafMetrics() {
+		tag, found := m.GetTag("series")
+		require.True(t, found)
+		counts[tag]++
+	}
+	for series, count := range counts {
+		require.Equalf(t, 10, count, "incomplete series %q", series)
+	}
+	require.InDelta(t, 250, len(counts), 60)
+
+	// The selection is deterministic across instances
+	other := &Sample{Mode: "series", Rate: 0.25}
+	require.NoError(t, other.Init())
+	var otherAcc testutil.Accumulator
+	require.NoError(t, other.Start(&otherAcc))
+	for series := 0; series < 1000; series++ {
+		m := metric.New("test", map[string]string{"series": strconv.Itoa(series)}, map[string]interface{}{"value": 0}, now)
+		require.NoError(t, other.Add(m, &otherAcc))
+	}
+	other.Stop()
+	require.Len(t, otherAcc.GetTelegrafMetrics(), len(counts))
+	for _, m := range otherAcc.GetTelegrafMetrics() {
+		tag, _ := m.GetTag("series")
+		require.Contains(t, counts, tag)
+	}
+}
+
+func TestReservoir(t *testing.T) {
+	plugin := &Sample{Mode: "reservoir", Size: 10, Interval: config.Duration(time.Hour)}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	// Metrics are held back until the end of the interval
+	require.Empty(t, acc.GetTelegrafMetrics())
+	plugin.Stop()
+
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 10)
+	for i, m := range actual {
+		rate, found := m.GetField("sample_rate")
+		require.True(t, found)
+		require.InDelta(t, 0.1, rate, 1e-9)
+		if i > 0 {
+			require.False(t, m.Time().Before(actual[i-1].Time()), "metrics not ordered by time")
+		}
+	}
+}
+
+func TestReservoirInterval(t *testing.T) {
+	plugin := &Sample{Mode: "reservoir", Size: 10, Interval: config.Duration(100 * time.Millisecond)}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, now.Add(time.Second)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1, "sample_rate": 1.0}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2, "sample_rate": 1.0}, now.Add(time.Second)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 50*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 100)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	plugin := &Sample{Mode: "reservoir", Size: 10, Interval: config.Duration(time.Hour)}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for i := 0; i < 100; i++ {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Now())
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+	plugin.Stop()
+
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 100
+	}, time.Second, 100*time.Millisecond, "%d delivered but 100 expected", len(delivered))
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/persister"
 	common "github.com/influxdata/telegraf/plugins/common/starlark"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -3629,6 +3630,71 @@ func testNow(_ *starlark.Thread, _ *starlark.Builtin, _ starlark.Tuple, _ []star
 	return starlarktime.Time(time.Date(2021, 4, 15, 12, 0, 0, 999, time.UTC)), nil
 }
 
+func TestStatePersistence(t *testing.T) {
+	source := `
+state = {"last": None, "count": 0}
+
+def apply(metric):
+  previous = state["last"]
+  state["last"] = deepcopy(metric)
+  state["count"] += 1
+  state["history"] = state.get("history", []) + [metric.fields["value"]]
+  state["info"] = {"total": 1.5 * state["count"], "seen": (True, None)}
+  if previous == None:
+    return None
+  metric.fields["previous"] = previous.fields["value"]
+  metric.fields["count"] = state["count"]
+  metric.fields["history"] = len(state["history"])
+  metric.fields["total"] = state["info"]["total"]
+  metric.fields["seen"] = state["info"]["seen"][0]
+  return metric
+`
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	plugin := newStarlarkFromSource(source)
+	require.NoError(t, plugin.Init())
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(metric.New("m", map[string]string{"tag": "a"}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 0)), &acc))
+	require.NoError(t, plugin.Add(metric.New("m", map[string]string{"tag": "a"}, map[string]interface{}{"value": int64(2)}, time.Unix(1, 0)), &acc))
+	plugin.Stop()
+
+	p := &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("starlark", plugin))
+	require.NoError(t, p.Store())
+
+	// Restore the state into a new instance and continue processing
+	plugin = newStarlarkFromSource(source)
+	require.NoError(t, plugin.Init())
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("starlark", plugin))
+	require.NoError(t, p.Load())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(metric.New("m", map[string]string{"tag": "a"}, map[string]interface{}{"value": int64(3)}, time.Unix(2, 0)), &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"m",
+			map[string]string{"tag": "a"},
+			map[string]interface{}{
+				"value":    int64(3),
+				"previous": int64(2),
+				"count":    int64(3),
+				"history":  int64(3),
+				"total":    4.5,
+				"seen":     true,
+			},
+			time.Unix(2, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 func newStarlarkFromSource(source string) *Starlark {
 	return &Starlark{
 		Common: common.Common{
@@ -54,6 +54,7 @@ type Histogram struct {
 	Buckets []Bucket
 	Count   uint64
 	Sum     float64
+	Native  *NativeHistogram
 }
 
 func (h *Histogram) merge(b Bucket) {
@@ -182,6 +183,13 @@ func (c *Collection) createLabels(metric telegraf.Metric) []LabelPair {
 
 func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	labels := c.createLabels(metric)
+
+	// Native histograms span multiple fields so handle them separately
+	if name, ok := NativeHistogramName(metric); ok {
+		c.addNativeHistogram(metric, name, labels, now)
+		return
+	}
+
 	for _, field := range metric.FieldList() {
 		metricName := MetricName(metric.Name(), field.Key, metric.Type())
 		metricName, ok := SanitizeMetricName(metricName)
@@ -339,6 +347,56 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	}
 }
 
+func (c *Collection) addNativeHistogram(metric telegraf.Metric, name string, labels []LabelPair, now time.Time) {
+	native, err := ParseNativeHistogram(metric, name+"_")
+	if err != nil || native == nil {
+		return
+	}
+
+	metricName, ok := SanitizeMetricName(MetricName(metric.Name(), name, telegraf.Histogram))
+	if !ok {
+		return
+	}
+	family := MetricFamily{
+		Name: metricName,
+		Type: c.config.TypeMappings.DetermineType(metricName, metric),
+	}
+	if family.Type != telegraf.Histogram {
+		return
+	}
+
+	entry, ok := c.Entries[family]
+	if !ok {
+		entry = Entry{
+			Family:  family,
+			Metrics: make(map[MetricKey]*Metric),
+		}
+		c.Entries[family] = entry
+	}
+
+	metricKey := MakeMetricKey(labels)
+	m, ok := entry.Metrics[metricKey]
+	if ok {
+		if metric.Time().Before(m.Time) {
+			return
+		}
+		m.Time = metric.Time()
+		m.AddTime = now
+	} else {
+		m = &Metric{
+			Labels:    labels,
+			Time:      metric.Time(),
+			AddTime:   now,
+			Histogram: &Histogram{},
+		}
+	}
+	m.Histogram.Count = uint64(native.Count)
+	m.Histogram.Sum = native.Sum
+	m.Histogram.Native = native
+
+	entry.Metrics[metricKey] = m
+}
+
 func (c *Collection) Expire(now time.Time, age time.Duration) {
 	expireTime := now.Add(-age)
 	for _, entry := range c.Entries {
@@ -458,6 +516,9 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 					SampleCount: proto.Uint64(metric.Histogram.Count),
 					SampleSum:   proto.Float64(metric.Histogram.Sum),
 				}
+				if metric.Histogram.Native != nil {
+					metric.Histogram.Native.Fill(m.Histogram)
+				}
 			case telegraf.Summary:
 				quantiles := make([]*dto.Quantile, 0, len(metric.Summary.Quantiles))
 				for _, quantile := range metric.Summary.Quantiles {
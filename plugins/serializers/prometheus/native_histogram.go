@@ -0,0 +1,273 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+)
+
+type BucketSpan struct {
+	Offset int32
+	Length uint32
+}
+
+// NativeHistogram is a native (sparse) histogram with exponential buckets as
+// written by the prometheus parser. The bucket counts are absolute values.
+type NativeHistogram struct {
+	Schema         int32
+	ZeroThreshold  float64
+	ZeroCount      float64
+	Count          float64
+	Sum            float64
+	PositiveSpans  []BucketSpan
+	PositiveCounts []float64
+	NegativeSpans  []BucketSpan
+	NegativeCounts []float64
+}
+
+// NativeHistogramName returns the name of the native histogram contained in
+// a metric in the metric version 2 layout, i.e. the prefix of the schema
+// field.
+func NativeHistogramName(metric telegraf.Metric) (string, bool) {
+	if metric.Type() != telegraf.Histogram {
+		return "", false
+	}
+	for _, field := range metric.FieldList() {
+		if name, found := strings.CutSuffix(field.Key, "_schema"); found && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ParseNativeHistogram extracts the native histogram from the fields of the
+// metric starting with the given prefix. It returns nil without error if the
+// metric does not contain a native histogram.
+func ParseNativeHistogram(metric telegraf.Metric, prefix string) (*NativeHistogram, error) {
+	if metric.Type() != telegraf.Histogram {
+		return nil, nil
+	}
+	if _, found := metric.GetField(prefix + "schema"); !found {
+		return nil, nil
+	}
+
+	h := &NativeHistogram{}
+	positive := make(map[int]float64)
+	negative := make(map[int]float64)
+	positiveSpans := make(map[int]*BucketSpan)
+	negativeSpans := make(map[int]*BucketSpan)
+	for _, field := range metric.FieldList() {
+		key, found := strings.CutPrefix(field.Key, prefix)
+		if !found {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "schema":
+			var v int64
+			v, err = sampleInt(field.Value)
+			h.Schema = int32(v)
+		case "zero_threshold":
+			h.ZeroThreshold, err = sampleFloat(field.Value)
+		case "zero_count":
+			h.ZeroCount, err = sampleFloat(field.Value)
+		case "count":
+			h.Count, err = sampleFloat(field.Value)
+		case "sum":
+			h.Sum, err = sampleFloat(field.Value)
+		default:
+			if rest, found := strings.CutPrefix(key, "positive_"); found {
+				err = parseBucketField(rest, field.Value, positiveSpans, positive)
+			} else if rest, found := strings.CutPrefix(key, "negative_"); found {
+				err = parseBucketField(rest, field.Value, negativeSpans, negative)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", field.Key, err)
+		}
+	}
+
+	var err error
+	if h.PositiveSpans, h.PositiveCounts, err = collectBuckets(positiveSpans, positive); err != nil {
+		return nil, fmt.Errorf("invalid positive buckets: %w", err)
+	}
+	if h.NegativeSpans, h.NegativeCounts, err = collectBuckets(negativeSpans, negative); err != nil {
+		return nil, fmt.Errorf("invalid negative buckets: %w", err)
+	}
+	return h, nil
+}
+
+// parseBucketField parses the "span_<n>_offset", "span_<n>_length" and
+// "bucket_<n>" fields of the positive or negative buckets
+func parseBucketField(key string, value interface{}, spans map[int]*BucketSpan, counts map[int]float64) error {
+	if rest, found := strings.CutPrefix(key, "bucket_"); found {
+		idx, err := strconv.Atoi(rest)
+		if err != nil || idx < 0 {
+			return errors.New("invalid bucket index")
+		}
+		count, err := sampleFloat(value)
+		if err != nil {
+			return err
+		}
+		counts[idx] = count
+		return nil
+	}
+
+	rest, found := strings.CutPrefix(key, "span_")
+	if !found {
+		return nil
+	}
+	idxStr, attr, found := strings.Cut(rest, "_")
+	if !found {
+		return nil
+	}
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil || idx < 0 {
+		return errors.New("invalid span index")
+	}
+	v, err := sampleInt(value)
+	if err != nil {
+		return err
+	}
+	span, found := spans[idx]
+	if !found {
+		span = &BucketSpan{}
+		spans[idx] = span
+	}
+	switch attr {
+	case "offset":
+		span.Offset = int32(v)
+	case "length":
+		if v < 0 {
+			return errors.New("negative span length")
+		}
+		span.Length = uint32(v)
+	}
+	return nil
+}
+
+// collectBuckets orders the spans and bucket counts by their index and
+// checks that the number of buckets matches the spans
+func collectBuckets(spans map[int]*BucketSpan, counts map[int]float64) ([]BucketSpan, []float64, error) {
+	resultSpans := make([]BucketSpan, 0, len(spans))
+	var n int
+	for i := 0; i < len(spans); i++ {
+		span, found := spans[i]
+		if !found {
+			return nil, nil, fmt.Errorf("missing span %d", i)
+		}
+		resultSpans = append(resultSpans, *span)
+		n += int(span.Length)
+	}
+	if n != len(counts) {
+		return nil, nil, fmt.Errorf("spans contain %d buckets but got %d counts", n, len(counts))
+	}
+
+	resultCounts := make([]float64, 0, len(counts))
+	for i := 0; i < len(counts); i++ {
+		count, found := counts[i]
+		if !found {
+			return nil, nil, fmt.Errorf("missing bucket %d", i)
+		}
+		resultCounts = append(resultCounts, count)
+	}
+	return resultSpans, resultCounts, nil
+}
+
+// IsInteger returns true if all counts of the histogram are whole numbers so
+// it can be encoded as integer histogram
+func (h *NativeHistogram) IsInteger() bool {
+	isWhole := func(v float64) bool {
+		return v >= 0 && v == math.Trunc(v)
+	}
+	if !isWhole(h.Count) || !isWhole(h.ZeroCount) {
+		return false
+	}
+	for _, v := range h.PositiveCounts {
+		if !isWhole(v) {
+			return false
+		}
+	}
+	for _, v := range h.NegativeCounts {
+		if !isWhole(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Deltas encodes the absolute bucket counts as differences to the previous
+// bucket as used by integer histograms
+func Deltas(counts []float64) []int64 {
+	deltas := make([]int64, 0, len(counts))
+	var prev int64
+	for _, v := range counts {
+		count := int64(v)
+		deltas = append(deltas, count-prev)
+		prev = count
+	}
+	return deltas
+}
+
+// Fill sets the native histogram fields of the given protobuf histogram
+func (h *NativeHistogram) Fill(out *dto.Histogram) {
+	out.Schema = proto.Int32(h.Schema)
+	out.ZeroThreshold = proto.Float64(h.ZeroThreshold)
+	out.SampleSum = proto.Float64(h.Sum)
+	out.PositiveSpan = dtoSpans(h.PositiveSpans)
+	out.NegativeSpan = dtoSpans(h.NegativeSpans)
+	if h.IsInteger() {
+		out.SampleCount = proto.Uint64(uint64(h.Count))
+		out.ZeroCount = proto.Uint64(uint64(h.ZeroCount))
+		out.PositiveDelta = Deltas(h.PositiveCounts)
+		out.NegativeDelta = Deltas(h.NegativeCounts)
+		return
+	}
+	out.SampleCount = nil
+	out.SampleCountFloat = proto.Float64(h.Count)
+	out.ZeroCountFloat = proto.Float64(h.ZeroCount)
+	out.PositiveCount = h.PositiveCounts
+	out.NegativeCount = h.NegativeCounts
+}
+
+func dtoSpans(spans []BucketSpan) []*dto.BucketSpan {
+	result := make([]*dto.BucketSpan, 0, len(spans))
+	for _, s := range spans {
+		result = append(result, &dto.BucketSpan{
+			Offset: proto.Int32(s.Offset),
+			Length: proto.Uint32(s.Length),
+		})
+	}
+	return result
+}
+
+func sampleFloat(value interface{}) (float64, error) {
+	v, ok := SampleValue(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid type %T", value)
+	}
+	return v, nil
+}
+
+func sampleInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("invalid type %T", value)
+}
@@ -58,9 +58,21 @@ type FormatConfig struct {
 
 type Serializer struct {
 	FormatConfig
+	// Exposition format of the output, native histograms can only be
+	// represented in the protobuf format
+	Format string `toml:"prometheus_format"`
+
+	protobuf bool
 }
 
 func (s *Serializer) Init() error {
+	switch s.Format {
+	case "", "text":
+	case "protobuf":
+		s.protobuf = true
+	default:
+		return fmt.Errorf("invalid format %q", s.Format)
+	}
 	return s.FormatConfig.TypeMappings.Init()
 }
 
@@ -74,9 +86,14 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		coll.Add(metric, time.Now())
 	}
 
+	format := expfmt.FmtText
+	if s.protobuf {
+		format = expfmt.FmtProtoDelim
+	}
+
 	var buf bytes.Buffer
 	for _, mf := range coll.GetProto() {
-		enc := expfmt.NewEncoder(&buf, expfmt.FmtText)
+		enc := expfmt.NewEncoder(&buf, format)
 		err := enc.Encode(mf)
 		if err != nil {
 			return nil, err
@@ -1,13 +1,22 @@
 package prometheus
 
 import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	parser "github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -226,7 +235,7 @@ cpu_time_idle{host="example.org"} 42
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Serializer{
-				FormatConfig{
+				FormatConfig: FormatConfig{
 					SortMetrics:     true,
 					ExportTimestamp: tt.config.ExportTimestamp,
 					StringAsLabel:   tt.config.StringAsLabel,
@@ -746,7 +755,7 @@ rpc_duration_seconds_count 2693
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Serializer{
-				FormatConfig{
+				FormatConfig: FormatConfig{
 					SortMetrics:     true,
 					ExportTimestamp: tt.config.ExportTimestamp,
 					StringAsLabel:   tt.config.StringAsLabel,
@@ -762,6 +771,133 @@ rpc_duration_seconds_count 2693
 	}
 }
 
+func TestInitInvalidFormat(t *testing.T) {
+	s := &Serializer{Format: "foo"}
+	require.ErrorContains(t, s.Init(), `invalid format "foo"`)
+}
+
+func TestSerializeNativeHistogramRoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name  string
+		input []telegraf.Metric
+	}{
+		{
+			name: "integer",
+			input: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{"method": "GET"},
+					map[string]interface{}{
+						"http_request_duration_seconds_count":                  float64(13),
+						"http_request_duration_seconds_sum":                    3.25,
+						"http_request_duration_seconds_schema":                 int64(3),
+						"http_request_duration_seconds_zero_threshold":         2.938735877055719e-39,
+						"http_request_duration_seconds_zero_count":             float64(1),
+						"http_request_duration_seconds_positive_span_0_offset": int64(-2),
+						"http_request_duration_seconds_positive_span_0_length": int64(2),
+						"http_request_duration_seconds_positive_span_1_offset": int64(3),
+						"http_request_duration_seconds_positive_span_1_length": int64(1),
+						"http_request_duration_seconds_positive_bucket_0":      float64(2),
+						"http_request_duration_seconds_positive_bucket_1":      float64(5),
+						"http_request_duration_seconds_positive_bucket_2":      float64(4),
+						"http_request_duration_seconds_negative_span_0_offset": int64(1),
+						"http_request_duration_seconds_negative_span_0_length": int64(1),
+						"http_request_duration_seconds_negative_bucket_0":      float64(1),
+					},
+					now,
+					telegraf.Histogram,
+				),
+			},
+		},
+		{
+			name: "float with classic buckets",
+			input: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{
+						"rpc_latency_seconds_count":                  float64(5),
+						"rpc_latency_seconds_sum":                    1.5,
+						"rpc_latency_seconds_schema":                 int64(0),
+						"rpc_latency_seconds_zero_threshold":         0.001,
+						"rpc_latency_seconds_zero_count":             0.5,
+						"rpc_latency_seconds_positive_span_0_offset": int64(0),
+						"rpc_latency_seconds_positive_span_0_length": int64(2),
+						"rpc_latency_seconds_positive_bucket_0":      1.5,
+						"rpc_latency_seconds_positive_bucket_1":      3.0,
+					},
+					now,
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "0.1"},
+					map[string]interface{}{"rpc_latency_seconds_bucket": float64(2)},
+					now,
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "+Inf"},
+					map[string]interface{}{"rpc_latency_seconds_bucket": float64(5)},
+					now,
+					telegraf.Histogram,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serializer{
+				FormatConfig: FormatConfig{ExportTimestamp: true},
+				Format:       "protobuf",
+			}
+			require.NoError(t, s.Init())
+			buf, err := s.SerializeBatch(tt.input)
+			require.NoError(t, err)
+
+			p := &parser.Parser{
+				MetricVersion: 2,
+				Header:        http.Header{"Content-Type": []string{string(expfmt.FmtProtoDelim)}},
+			}
+			actual, err := p.Parse(buf)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.input, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestSerializeNativeHistogramParsed(t *testing.T) {
+	// Use the data of the parser test so both sides agree on the layout
+	fn := filepath.Join("..", "..", "parsers", "prometheus", "testcases", "native_histogram", "input.bin")
+	input, err := os.ReadFile(fn)
+	require.NoError(t, err)
+
+	p := &parser.Parser{
+		MetricVersion: 2,
+		Header:        http.Header{"Content-Type": []string{string(expfmt.FmtProtoDelim)}},
+	}
+	metrics, err := p.Parse(input)
+	require.NoError(t, err)
+
+	s := &Serializer{
+		FormatConfig: FormatConfig{ExportTimestamp: true, CompactEncoding: true},
+		Format:       "protobuf",
+	}
+	require.NoError(t, s.Init())
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	// The HELP metadata is not kept by the parser
+	var expected, actual dto.MetricFamily
+	require.NoError(t, expfmt.NewDecoder(bytes.NewReader(input), expfmt.FmtProtoDelim).Decode(&expected))
+	require.NoError(t, expfmt.NewDecoder(bytes.NewReader(buf), expfmt.FmtProtoDelim).Decode(&actual))
+	expected.Help = nil
+	require.Truef(t, proto.Equal(&expected, &actual), "expected %v but got %v", &expected, &actual)
+}
+
 func BenchmarkSerialize(b *testing.B) {
 	s := &Serializer{}
 	require.NoError(b, s.Init())
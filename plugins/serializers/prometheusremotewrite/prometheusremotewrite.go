@@ -32,9 +32,38 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	var buf bytes.Buffer
 
 	var entries = make(map[MetricKey]prompb.TimeSeries)
+	var natives []nativeHistogram
 	var labels = make([]prompb.Label, 0)
 	for _, metric := range metrics {
 		labels = s.appendCommonLabels(labels[:0], metric)
+
+		// Native histograms span multiple fields so handle them separately
+		if name, ok := prometheus.NativeHistogramName(metric); ok {
+			native, err := prometheus.ParseNativeHistogram(metric, name+"_")
+			if err != nil || native == nil {
+				continue
+			}
+			metricName, ok := prometheus.SanitizeMetricName(prometheus.MetricName(metric.Name(), name, metric.Type()))
+			if !ok {
+				continue
+			}
+
+			metrickey, promts := getPromHistogramTS(metricName, labels, native, metric.Time())
+			if m, ok := entries[metrickey]; ok && len(m.Histograms) > 0 {
+				if metric.Time().Before(time.Unix(0, m.Histograms[0].Timestamp*1_000_000)) {
+					continue
+				}
+			}
+			entries[metrickey] = promts
+			natives = append(natives, nativeHistogram{
+				name:      metricName,
+				labels:    append(make([]prompb.Label, 0, len(labels)), labels...),
+				histogram: native,
+				timestamp: metric.Time(),
+			})
+			continue
+		}
+
 		var metrickey MetricKey
 		var promts prompb.TimeSeries
 		for _, field := range metric.FieldList() {
@@ -165,7 +194,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 			// Prometheus sample.  If this metric is older than the existing
 			// sample then we can skip over it.
 			m, ok := entries[metrickey]
-			if ok {
+			if ok && len(m.Samples) > 0 {
 				if metric.Time().Before(time.Unix(0, m.Samples[0].Timestamp*1_000_000)) {
 					continue
 				}
@@ -174,6 +203,19 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		}
 	}
 
+	// Histograms with both native and classic buckets need the count and
+	// sum of the native histogram for the classic series
+	for _, n := range natives {
+		infLabel := prompb.Label{Name: "le", Value: "+Inf"}
+		if key, _ := getPromTS(n.name+"_bucket", n.labels, 0, n.timestamp, infLabel); !hasEntry(entries, key) {
+			continue
+		}
+		key, promts := getPromTS(n.name+"_sum", n.labels, n.histogram.Sum, n.timestamp)
+		entries[key] = promts
+		key, promts = getPromTS(n.name+"_count", n.labels, n.histogram.Count, n.timestamp)
+		entries[key] = promts
+	}
+
 	var promTS = make([]prompb.TimeSeries, len(entries))
 	var i int
 	for _, promts := range entries {
@@ -310,6 +352,61 @@ func getPromTS(name string, labels []prompb.Label, value float64, ts time.Time,
 	return MakeMetricKey(labelscopy), prompb.TimeSeries{Labels: labelscopy, Samples: sample}
 }
 
+type nativeHistogram struct {
+	name      string
+	labels    []prompb.Label
+	histogram *prometheus.NativeHistogram
+	timestamp time.Time
+}
+
+func hasEntry(entries map[MetricKey]prompb.TimeSeries, key MetricKey) bool {
+	_, found := entries[key]
+	return found
+}
+
+func getPromHistogramTS(name string, labels []prompb.Label, native *prometheus.NativeHistogram, ts time.Time) (MetricKey, prompb.TimeSeries) {
+	labelscopy := make([]prompb.Label, len(labels), len(labels)+1)
+	copy(labelscopy, labels)
+	labelscopy = append(labelscopy, prompb.Label{
+		Name:  "__name__",
+		Value: name,
+	})
+
+	// we sort the labels since Prometheus TSDB does not like out of order labels
+	sort.Sort(sortableLabels(labelscopy))
+
+	h := prompb.Histogram{
+		Sum:           native.Sum,
+		Schema:        native.Schema,
+		ZeroThreshold: native.ZeroThreshold,
+		PositiveSpans: promSpans(native.PositiveSpans),
+		NegativeSpans: promSpans(native.NegativeSpans),
+		// Timestamp is int milliseconds for remote write.
+		Timestamp: ts.UnixNano() / int64(time.Millisecond),
+	}
+	if native.IsInteger() {
+		h.Count = &prompb.Histogram_CountInt{CountInt: uint64(native.Count)}
+		h.ZeroCount = &prompb.Histogram_ZeroCountInt{ZeroCountInt: uint64(native.ZeroCount)}
+		h.PositiveDeltas = prometheus.Deltas(native.PositiveCounts)
+		h.NegativeDeltas = prometheus.Deltas(native.NegativeCounts)
+	} else {
+		h.Count = &prompb.Histogram_CountFloat{CountFloat: native.Count}
+		h.ZeroCount = &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: native.ZeroCount}
+		h.PositiveCounts = native.PositiveCounts
+		h.NegativeCounts = native.NegativeCounts
+	}
+
+	return MakeMetricKey(labelscopy), prompb.TimeSeries{Labels: labelscopy, Histograms: []prompb.Histogram{h}}
+}
+
+func promSpans(spans []prometheus.BucketSpan) []prompb.BucketSpan {
+	result := make([]prompb.BucketSpan, 0, len(spans))
+	for _, s := range spans {
+		result = append(result, prompb.BucketSpan{Offset: s.Offset, Length: s.Length})
+	}
+	return result
+}
+
 type sortableLabels []prompb.Label
 
 func (sl sortableLabels) Len() int { return len(sl) }
@@ -751,3 +751,132 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestRemoteWriteSerializeNativeHistogram(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"method": "GET"},
+			map[string]interface{}{
+				"latency_schema":                 int64(3),
+				"latency_zero_threshold":         0.001,
+				"latency_zero_count":             1.0,
+				"latency_count":                  13.0,
+				"latency_sum":                    42.5,
+				"latency_positive_span_0_offset": int64(-2),
+				"latency_positive_span_0_length": int64(2),
+				"latency_positive_span_1_offset": int64(3),
+				"latency_positive_span_1_length": int64(1),
+				"latency_positive_bucket_0":      2.0,
+				"latency_positive_bucket_1":      5.0,
+				"latency_positive_bucket_2":      4.0,
+				"latency_negative_span_0_offset": int64(1),
+				"latency_negative_span_0_length": int64(1),
+				"latency_negative_bucket_0":      1.0,
+			},
+			time.Unix(1700000000, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	s := &Serializer{SortMetrics: true}
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	protobuff, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+	var req prompb.WriteRequest
+	require.NoError(t, req.Unmarshal(protobuff))
+	require.Len(t, req.Timeseries, 1)
+
+	ts := req.Timeseries[0]
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "latency"},
+		{Name: "method", Value: "GET"},
+	}, ts.Labels)
+	require.Empty(t, ts.Samples)
+	require.Len(t, ts.Histograms, 1)
+
+	h := ts.Histograms[0]
+	require.Equal(t, uint64(13), h.GetCountInt())
+	require.Equal(t, uint64(1), h.GetZeroCountInt())
+	require.InDelta(t, 42.5, h.Sum, 1e-9)
+	require.Equal(t, int32(3), h.Schema)
+	require.InDelta(t, 0.001, h.ZeroThreshold, 1e-9)
+	require.Equal(t, []prompb.BucketSpan{{Offset: -2, Length: 2}, {Offset: 3, Length: 1}}, h.PositiveSpans)
+	require.Equal(t, []int64{2, 3, -1}, h.PositiveDeltas)
+	require.Equal(t, []prompb.BucketSpan{{Offset: 1, Length: 1}}, h.NegativeSpans)
+	require.Equal(t, []int64{1}, h.NegativeDeltas)
+	require.Equal(t, int64(1700000000000), h.Timestamp)
+}
+
+func TestRemoteWriteSerializeFloatNativeHistogram(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{},
+			map[string]interface{}{
+				"size_schema":                 int64(0),
+				"size_zero_threshold":         0.0,
+				"size_zero_count":             0.0,
+				"size_count":                  2.5,
+				"size_sum":                    10.0,
+				"size_positive_span_0_offset": int64(0),
+				"size_positive_span_0_length": int64(1),
+				"size_positive_bucket_0":      2.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "1"},
+			map[string]interface{}{
+				"size_bucket": 2.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "+Inf"},
+			map[string]interface{}{
+				"size_bucket": 2.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	s := &Serializer{SortMetrics: true}
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	protobuff, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+	var req prompb.WriteRequest
+	require.NoError(t, req.Unmarshal(protobuff))
+
+	var native *prompb.Histogram
+	for i := range req.Timeseries {
+		if len(req.Timeseries[i].Histograms) > 0 {
+			native = &req.Timeseries[i].Histograms[0]
+		}
+	}
+	require.NotNil(t, native)
+	require.InDelta(t, 2.5, native.GetCountFloat(), 1e-9)
+	require.InDelta(t, 0.0, native.GetZeroCountFloat(), 1e-9)
+	require.Equal(t, []float64{2.5}, native.PositiveCounts)
+	require.Empty(t, native.PositiveDeltas)
+
+	// The classic series of a mixed histogram use the native count and sum
+	actual, err := prompbToText(data)
+	require.NoError(t, err)
+	expected := `
+size_count 2.5
+size_sum 10
+size_bucket{le="+Inf"} 2
+size_bucket{le="1"} 2
+`
+	require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(string(actual)))
+}
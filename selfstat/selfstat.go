@@ -77,10 +77,26 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
-	registry.mu.Lock()
+	return registry.metrics(Stat.Get)
+}
+
+// Snapshot returns all registered stats as telegraf metrics like Metrics()
+// but without resetting the average of timing stats, so reading the stats
+// does not interfere with the inputs.internal plugin.
+func Snapshot() []telegraf.Metric {
+	return registry.metrics(func(s Stat) int64 {
+		if t, ok := s.(*timingStat); ok {
+			return t.peek()
+		}
+		return s.Get()
+	})
+}
+
+func (r *Registry) metrics(get func(Stat) int64) []telegraf.Metric {
+	r.mu.Lock()
 	now := time.Now()
-	metrics := make([]telegraf.Metric, 0, len(registry.stats))
-	for _, stats := range registry.stats {
+	metrics := make([]telegraf.Metric, 0, len(r.stats))
+	for _, stats := range r.stats {
 		if len(stats) > 0 {
 			var tags map[string]string
 			var name string
@@ -91,17 +107,34 @@ func Metrics() []telegraf.Metric {
 					tags = stat.Tags()
 					name = stat.Name()
 				}
-				fields[fieldname] = stat.Get()
+				fields[fieldname] = get(stat)
 				j++
 			}
 			m := metric.New(name, tags, fields, now)
 			metrics = append(metrics, m)
 		}
 	}
-	registry.mu.Unlock()
+	r.mu.Unlock()
 	return metrics
 }
 
+// Values returns the current values of the stats registered for the given
+// measurement and tags. Timing stats are skipped.
+func Values(measurement string, tags map[string]string) map[string]int64 {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	stats := registry.stats[key("internal_"+measurement, tags)]
+	values := make(map[string]int64, len(stats))
+	for field, stat := range stats {
+		if _, ok := stat.(*timingStat); ok {
+			continue
+		}
+		values[field] = stat.Get()
+	}
+	return values
+}
+
 type Registry struct {
 	stats map[uint64]map[string]Stat
 	mu    sync.Mutex
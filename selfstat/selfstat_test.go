@@ -211,3 +211,40 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestValues(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	Register("test", "count", map[string]string{"input": "foo"}).Incr(3)
+	Register("test", "errors", map[string]string{"input": "foo"}).Incr(1)
+	Register("test", "count", map[string]string{"input": "bar"}).Incr(5)
+	timing := RegisterTiming("test", "time_ns", map[string]string{"input": "foo"})
+	timing.Incr(10)
+
+	expected := map[string]int64{"count": 3, "errors": 1}
+	require.Equal(t, expected, Values("test", map[string]string{"input": "foo"}))
+	require.Empty(t, Values("test", map[string]string{"input": "baz"}))
+
+	// Reading the values must not reset the timing average
+	require.Equal(t, int64(10), timing.Get())
+}
+
+func TestSnapshot(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	timing := RegisterTiming("test", "time_ns", map[string]string{"input": "foo"})
+	timing.Incr(10)
+	timing.Incr(20)
+
+	metrics := Snapshot()
+	require.Len(t, metrics, 1)
+	value, found := metrics[0].GetField("time_ns")
+	require.True(t, found)
+	require.Equal(t, int64(15), value)
+
+	// Taking a snapshot must not reset the timing average
+	timing.Incr(30)
+	require.Equal(t, int64(20), timing.Get())
+}
@@ -39,6 +39,16 @@ func (s *timingStat) Get() int64 {
 	return avg
 }
 
+// peek returns the current average like Get() without resetting it.
+func (s *timingStat) peek() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count > 0 {
+		return s.v / s.count
+	}
+	return s.prev
+}
+
 func (s *timingStat) Name() string {
 	return s.measurement
 }